@@ -8,6 +8,8 @@ import (
 	"time"
 	"io"
 	"net/http"
+	// в alpine нет базы часовых поясов, а цитата дня считается по ?tz=
+	_ "time/tzdata"
 
 	"github.com/jackc/pgx/v5/pgxpool"
     "go.uber.org/zap"
@@ -15,6 +17,8 @@ import (
 	"quotebook/config"
 	"quotebook/internal/logger"
 	"quotebook/internal/database"
+	"quotebook/internal/interfaces"
+	"quotebook/internal/jobs"
 	"quotebook/internal/repository"
 	"quotebook/internal/service"
 	"quotebook/internal/transport/http/api"
@@ -25,7 +29,7 @@ func main() {
 	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt)
 	defer cancel()
 
-	srv, dbPool, logBase, err := run(ctx, os.Stdout, os.Args);
+	srv, dbPool, logBase, views, err := run(ctx, os.Stdout, os.Args);
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", err)
 		os.Exit(1)
@@ -41,36 +45,63 @@ func main() {
     if err := srv.Shutdown(shutdownCtx); err != nil {
         logBase.Error(ctx, "Server shutdown failed", zap.Error(err))
     }
+    // просмотры, накопленные после последнего фонового сброса
+    if _, err := views.Flush(shutdownCtx); err != nil {
+        logBase.Error(ctx, "Final view flush failed", zap.Error(err))
+    }
     logBase.Info(ctx, "Server exited gracefully")
 }
 
-func run(ctx context.Context, w io.Writer, args []string) (*http.Server, *pgxpool.Pool, *logger.Logger, error) {
+func run(ctx context.Context, w io.Writer, args []string) (*http.Server, *pgxpool.Pool, *logger.Logger, interfaces.IViewService, error) {
     // Конфиг и логгер
     cfg, err := config.LoadConfig("config/config.yml")
     if err != nil {
-        return nil, nil, nil, err
+        return nil, nil, nil, nil, err
     }
     logBase, err := logger.New(cfg)
     if err != nil {
-        return nil, nil, nil, err
+        return nil, nil, nil, nil, err
     }
     ctx = logger.CtxWWithLogger(ctx, logBase)
+    if cfg.Admin.Token == "" {
+        logBase.Error(ctx, "admin.token is not set, all /admin routes are closed")
+    }
 
     // Подключение к БД и миграции
     dbPool, err := database.Connect(ctx, cfg)
     if err != nil {
-        return nil, nil, nil, err
+        return nil, nil, nil, nil, err
     }
     if err := database.RunMigrations(ctx, cfg, dbPool); err != nil {
-        return nil, nil, nil, err
+        return nil, nil, nil, nil, err
     }
 
     // Репозиторий и quote-сервис
     repo := repository.NewQuoteRepository(dbPool, cfg)
     qSrv := service.NewQuoteService(cfg, repo)
+    auditSrv := service.NewAuditService(cfg, repository.NewAuditRepository(dbPool, cfg))
+    idemSrv := service.NewIdempotencyService(cfg, repository.NewIdempotencyRepository(dbPool, cfg))
+    importSrv := service.NewImportService(cfg, repository.NewImportRepository(dbPool, cfg))
+    cardSrv := service.NewCardService(cfg, repo)
+    dailySrv := service.NewDailyService(cfg, repository.NewDailyRepository(dbPool, cfg))
+    shuffleSrv := service.NewShuffleService(cfg, repository.NewShuffleRepository(dbPool, cfg))
+    engagementSrv := service.NewEngagementService(cfg, repository.NewEngagementRepository(dbPool, cfg))
+    collectionSrv := service.NewCollectionService(cfg, repository.NewCollectionRepository(dbPool, cfg))
+    disputeSrv := service.NewDisputeService(cfg, repository.NewDisputeRepository(dbPool, cfg))
+    statsSrv := service.NewStatsService(cfg, repository.NewStatsRepository(dbPool, cfg))
+    translationSrv := service.NewTranslationService(cfg, repository.NewTranslationRepository(dbPool, cfg), repo)
+    viewSrv := service.NewViewService(cfg, repository.NewViewRepository(dbPool, cfg))
+    similarSrv := service.NewSimilarService(cfg, repo)
+
+    // Фоновые задачи
+    jobs.StartTrashPurge(ctx, logBase, cfg, qSrv)
+    jobs.StartIdempotencyPurge(ctx, logBase, cfg, idemSrv)
+    jobs.StartShufflePurge(ctx, logBase, cfg, shuffleSrv)
+    jobs.StartViewFlush(ctx, logBase, cfg, viewSrv)
+    jobs.StartViewPurge(ctx, logBase, cfg, viewSrv)
 
     // роутер
-    handler := api.NewHandler(logBase, cfg, qSrv)
+    handler := api.NewHandler(logBase, cfg, qSrv, auditSrv, idemSrv, importSrv, cardSrv, dailySrv, shuffleSrv, engagementSrv, collectionSrv, disputeSrv, translationSrv, statsSrv, viewSrv, similarSrv)
     router := api.NewRouter(handler)
 
     // HTTP-сервер
@@ -87,5 +118,5 @@ func run(ctx context.Context, w io.Writer, args []string) (*http.Server, *pgxpoo
         }
     }()
 
-    return srv, dbPool, logBase, nil
+    return srv, dbPool, logBase, viewSrv, nil
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
 	"time"
 
@@ -17,9 +18,12 @@ func (lc *LoggerConfig) Build() (*zap.Logger, error) {
 	return lc.Config.Build()
 }
 
+// ServerConfig TrustedProxies — адреса и подсети прокси, которым можно верить
+// в X-Forwarded-For; от остальных клиентов заголовок не учитывается
 type ServerConfig struct {
-	Host string `yaml:"host"`
-	Port int    `yaml:"port"`
+	Host           string     `yaml:"host"`
+	Port           int        `yaml:"port"`
+	TrustedProxies []IPPrefix `yaml:"trustedProxies"`
 }
 
 type PoolConfig struct {
@@ -50,10 +54,147 @@ func (db DBConfig) ConnString() string {
 	)
 }
 
+// TrashConfig задаёт срок хранения удалённых цитат
+type TrashConfig struct {
+	Retention     Duration `yaml:"retention"`
+	PurgeInterval Duration `yaml:"purgeInterval"`
+}
+
+type AuditConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// AdminConfig токен для /admin/*, без него админские ручки закрыты
+type AdminConfig struct {
+	Token string `yaml:"token"`
+}
+
+// ConcurrencyConfig если RequireIfMatch, изменения без If-Match отклоняются
+type ConcurrencyConfig struct {
+	RequireIfMatch bool `yaml:"requireIfMatch"`
+}
+
+// CacheConfig Cache-Control для GET-ответов по шаблону маршрута, например "/quotes"
+type CacheConfig struct {
+	Routes map[string]string `yaml:"routes"`
+}
+
+// IdempotencyConfig сколько хранить ответы на запросы с Idempotency-Key
+type IdempotencyConfig struct {
+	TTL             Duration `yaml:"ttl"`
+	MaxResponseSize int      `yaml:"maxResponseSize"`
+	PurgeInterval   Duration `yaml:"purgeInterval"`
+}
+
+// ImportConfig настройки POST /quotes/import
+type ImportConfig struct {
+	BatchSize      int    `yaml:"batchSize"`
+	MaxErrors      int    `yaml:"maxErrors"`
+	AsyncThreshold int64  `yaml:"asyncThreshold"`
+	SpoolDir       string `yaml:"spoolDir"`
+}
+
+// CardConfig карточки GET /quotes/{id}/card.{png,svg}
+type CardConfig struct {
+	CacheBytes int    `yaml:"cacheBytes"`
+	MaxSide    int    `yaml:"maxSide"`
+	Theme      string `yaml:"theme"`
+	Font       string `yaml:"font"`
+}
+
+// FeedConfig ленты /feeds/quotes.rss и .atom. BaseURL нужен для постоянных
+// ссылок и id записей; пустой — берётся из запроса.
+type FeedConfig struct {
+	Size    int    `yaml:"size"`
+	Title   string `yaml:"title"`
+	BaseURL string `yaml:"baseURL"`
+}
+
+// DailyConfig цитата дня: пояс по умолчанию (IANA, например Europe/Moscow),
+// сколько дней отдаёт история без ?limit= и способ выбора без ?mode=
+type DailyConfig struct {
+	Timezone    string `yaml:"timezone"`
+	HistorySize int    `yaml:"historySize"`
+	Mode        string `yaml:"mode"`
+}
+
+// ShuffleConfig обходы GET /quotes/random?shuffle=true: сколько хранить
+// заброшенные и как часто их чистить
+type ShuffleConfig struct {
+	TTL           Duration `yaml:"ttl"`
+	PurgeInterval Duration `yaml:"purgeInterval"`
+}
+
+// RandomConfig взвешенный GET /quotes/random?strategy=: как часто перечитывать
+// веса и за какое время вес стратегии recency падает вдвое
+type RandomConfig struct {
+	Refresh         Duration `yaml:"refresh"`
+	RecencyHalfLife Duration `yaml:"recencyHalfLife"`
+}
+
+// CollectionsConfig подборки: сколько цитат в одной и сколько подборок у пользователя
+type CollectionsConfig struct {
+	MaxSize    int `yaml:"maxSize"`
+	MaxPerUser int `yaml:"maxPerUser"`
+}
+
+// AuthorsConfig поиск по автору. Похожесть — доля общих триграмм, от 0 до 1:
+// с Similarity опечатка считается тем же автором, с SuggestSimilarity автор
+// попадает в подсказку "возможно, вы искали" пустого ответа
+type AuthorsConfig struct {
+	Similarity        float64 `yaml:"similarity"`
+	SuggestSimilarity float64 `yaml:"suggestSimilarity"`
+	SuggestLimit      int     `yaml:"suggestLimit"`
+}
+
+// StatsConfig GET /stats: сколько держать посчитанную сводку, сколько строк
+// в топах и сколько периодов в гистограмме
+type StatsConfig struct {
+	TTL     Duration `yaml:"ttl"`
+	Top     int      `yaml:"top"`
+	Buckets int      `yaml:"buckets"`
+}
+
+// ViewsConfig счётчики просмотров: как часто сбрасывать накопленное в памяти
+// в базу, сколько хранить почасовые просмотры для /quotes/trending и сколько
+// цитат отдавать без ?limit=
+type ViewsConfig struct {
+	FlushInterval Duration `yaml:"flushInterval"`
+	Retention     Duration `yaml:"retention"`
+	PurgeInterval Duration `yaml:"purgeInterval"`
+	TrendingLimit int      `yaml:"trendingLimit"`
+}
+
+// SimilarConfig GET /quotes/{id}/similar: как часто подтягивать в индекс
+// изменённые цитаты, сколько похожих отдавать без ?limit= и с какой близостью
+// (косинус, от 0 до 1) цитата ещё считается похожей
+type SimilarConfig struct {
+	Refresh  Duration `yaml:"refresh"`
+	Limit    int      `yaml:"limit"`
+	MinScore float64  `yaml:"minScore"`
+}
+
 type Config struct {
-	Server ServerConfig `yaml:"server"`
-	DB     DBConfig     `yaml:"db"`
-	Logger LoggerConfig `yaml:"logger"`
+	Server      ServerConfig      `yaml:"server"`
+	DB          DBConfig          `yaml:"db"`
+	Logger      LoggerConfig      `yaml:"logger"`
+	Trash       TrashConfig       `yaml:"trash"`
+	Audit       AuditConfig       `yaml:"audit"`
+	Admin       AdminConfig       `yaml:"admin"`
+	Concurrency ConcurrencyConfig `yaml:"concurrency"`
+	Cache       CacheConfig       `yaml:"cache"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Import      ImportConfig      `yaml:"import"`
+	Card        CardConfig        `yaml:"card"`
+	Feed        FeedConfig        `yaml:"feed"`
+	Daily       DailyConfig       `yaml:"daily"`
+	Shuffle     ShuffleConfig     `yaml:"shuffle"`
+	Random      RandomConfig      `yaml:"random"`
+	Collections CollectionsConfig `yaml:"collections"`
+	Authors     AuthorsConfig     `yaml:"authors"`
+	Stats       StatsConfig       `yaml:"stats"`
+	Views       ViewsConfig       `yaml:"views"`
+	Similar     SimilarConfig     `yaml:"similar"`
 }
 
 func LoadConfig(filename string) (*Config, error) {
@@ -72,6 +213,31 @@ func LoadConfig(filename string) (*Config, error) {
 	return &config, nil
 }
 
+// IPPrefix подсеть ("10.0.0.0/8") или отдельный адрес ("127.0.0.1")
+type IPPrefix netip.Prefix
+
+func (p *IPPrefix) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	prefix, err := netip.ParsePrefix(str)
+	if err != nil {
+		addr, addrErr := netip.ParseAddr(str)
+		if addrErr != nil {
+			return fmt.Errorf("invalid address or subnet %q: %v", str, err)
+		}
+		prefix = netip.PrefixFrom(addr, addr.BitLen())
+	}
+	*p = IPPrefix(prefix.Masked())
+	return nil
+}
+
+// Contains входит ли адрес в подсеть; IPv4-mapped IPv6 сравнивается как IPv4
+func (p IPPrefix) Contains(addr netip.Addr) bool {
+	return netip.Prefix(p).Contains(addr.Unmap())
+}
+
 // для bdConfig
 type Duration time.Duration
 
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"quotebook/internal/models"
+)
+
+const TrailKey = "AuditTrail"
+
+// Trail собирает сведения о запросе, которые известны только сервису,
+// например id только что созданной цитаты
+type Trail struct {
+	mu       sync.Mutex
+	targetID string
+}
+
+func CtxWithTrail(ctx context.Context) (context.Context, *Trail) {
+	t := &Trail{}
+	return context.WithValue(ctx, TrailKey, t), t
+}
+
+// SetTarget хук для сервисного слоя: запоминает, над чем выполнена операция
+func SetTarget(ctx context.Context, targetID string) {
+	t, ok := ctx.Value(TrailKey).(*Trail)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	t.targetID = targetID
+	t.mu.Unlock()
+}
+
+func (t *Trail) TargetID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.targetID
+}
+
+// ChainHash считает хеш записи журнала с учётом хеша предыдущей.
+// Изменение или удаление любой строки ломает цепочку у всех последующих.
+func ChainHash(prevHash string, e *models.AuditEntry) string {
+	fields := []string{
+		prevHash,
+		strconv.FormatInt(e.ID, 10),
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		e.Principal,
+		e.IP,
+		e.Method,
+		e.Route,
+		e.TargetID,
+		strconv.Itoa(e.Status),
+		e.Outcome,
+		e.RequestID,
+		e.PayloadHash,
+	}
+	// поле появилось позже: у старых записей оно false и в хеш не входит,
+	// поэтому их цепочка по-прежнему сходится
+	if e.Authenticated {
+		fields = append(fields, "authenticated")
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(sum[:])
+}
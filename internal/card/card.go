@@ -0,0 +1,289 @@
+// Package card рисует карточку с цитатой для соцсетей: PNG растеризуется
+// на чистом Go шрифтами Go из golang.org/x/image, SVG повторяет ту же раскладку.
+package card
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/gofont/gosmallcaps"
+	"golang.org/x/image/font/gofont/gosmallcapsitalic"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Options размер карточки в пикселях, тема и шрифт
+type Options struct {
+	Width  int
+	Height int
+	Theme  string
+	Font   string
+}
+
+type theme struct {
+	background, text, accent color.RGBA
+}
+
+var themes = map[string]theme{
+	"light": {rgb(0xFAFAF7), rgb(0x1F2328), rgb(0xC2410C)},
+	"dark":  {rgb(0x111827), rgb(0xF3F4F6), rgb(0xF59E0B)},
+	"sepia": {rgb(0xF4ECD8), rgb(0x433422), rgb(0x8B5E34)},
+	"ocean": {rgb(0x0B3C5D), rgb(0xF0F7FA), rgb(0x5FB3CE)},
+}
+
+func rgb(v uint32) color.RGBA {
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// family шрифт цитаты и курсив для автора; разбираются один раз при первом использовании
+type family struct {
+	regular, italic []byte
+	// как назвать шрифт в SVG, где растеризует уже просмотрщик
+	svg string
+
+	once      sync.Once
+	reg, ital *opentype.Font
+	err       error
+}
+
+func (f *family) load() error {
+	f.once.Do(func() {
+		if f.reg, f.err = opentype.Parse(f.regular); f.err != nil {
+			return
+		}
+		f.ital, f.err = opentype.Parse(f.italic)
+	})
+	return f.err
+}
+
+var families = map[string]*family{
+	"sans":      {regular: goregular.TTF, italic: goitalic.TTF, svg: `'Go', 'Helvetica Neue', Arial, sans-serif`},
+	"mono":      {regular: gomono.TTF, italic: gomonoitalic.TTF, svg: `'Go Mono', Menlo, Consolas, monospace`},
+	"smallcaps": {regular: gosmallcaps.TTF, italic: gosmallcapsitalic.TTF, svg: `'Go Smallcaps', Georgia, serif`},
+}
+
+func names[T any](m map[string]T) []string {
+	out := make([]string, 0, len(m))
+	for name := range m {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Themes и Fonts перечисляют допустимые значения Options
+func Themes() []string { return names(themes) }
+func Fonts() []string  { return names(families) }
+
+func HasTheme(name string) bool { _, ok := themes[name]; return ok }
+func HasFont(name string) bool  { _, ok := families[name]; return ok }
+
+// layout раскладка, общая для PNG и SVG; координаты — базовые линии
+type layout struct {
+	lines      []string
+	size       float64
+	lineHeight float64
+	x, y       float64
+
+	author     string
+	authorSize float64
+	authorY    float64
+
+	// полоса акцентного цвета слева от текста
+	bar image.Rectangle
+}
+
+const lineSpacing = 1.3
+
+func face(f *opentype.Font, size float64) (font.Face, error) {
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+}
+
+func width(fc font.Face, s string) float64 {
+	return float64(font.MeasureString(fc, s)) / 64
+}
+
+// wrap переносит по словам, слишком длинное слово режется по символам
+func wrap(fc font.Face, text string, limit float64) []string {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if width(fc, candidate) <= limit {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			line = ""
+			for _, r := range word {
+				if line != "" && width(fc, line+string(r)) > limit {
+					lines = append(lines, line)
+					line = ""
+				}
+				line += string(r)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// computeLayout подбирает самый крупный кегль, при котором цитата
+// помещается в карточку; если не влезает и при минимальном, текст обрезается
+func computeLayout(quote, author string, o Options, fam *family) (*layout, error) {
+	short := float64(min(o.Width, o.Height))
+	pad := short * 0.08
+	textWidth := float64(o.Width) - 2*pad
+
+	l := &layout{author: "— " + author, authorSize: max(12, short/24)}
+	available := float64(o.Height) - 2*pad - l.authorSize*2.5
+	text := "“" + strings.TrimSpace(quote) + "”"
+
+	minSize := max(10, short/40)
+	for size := short / 8; ; size *= 0.92 {
+		if size < minSize {
+			size = minSize
+		}
+		fc, err := face(fam.reg, size)
+		if err != nil {
+			return nil, err
+		}
+		l.size, l.lineHeight = size, size*lineSpacing
+		l.lines = wrap(fc, text, textWidth)
+		fits := float64(len(l.lines))*l.lineHeight <= available
+		if !fits && size == minSize {
+			keep := max(1, int(available/l.lineHeight))
+			l.lines = l.lines[:keep]
+			l.lines[keep-1] = strings.TrimRight(l.lines[keep-1], " ") + "…"
+			fits = true
+		}
+		fc.Close()
+		if fits {
+			break
+		}
+	}
+
+	authorGap := l.authorSize * 1.6
+	block := float64(len(l.lines)-1)*l.lineHeight + l.size + authorGap + l.authorSize
+	top := (float64(o.Height) - block) / 2
+
+	l.x = pad
+	l.y = top + l.size
+	l.authorY = l.y + float64(len(l.lines)-1)*l.lineHeight + authorGap + l.authorSize
+
+	barWidth := max(4, o.Width/200)
+	barX := int(pad/2) - barWidth/2
+	l.bar = image.Rect(barX, int(top), barX+barWidth, int(l.authorY+l.authorSize*0.3))
+	return l, nil
+}
+
+func resolve(o Options) (theme, *family, error) {
+	th, ok := themes[o.Theme]
+	if !ok {
+		return theme{}, nil, fmt.Errorf("unknown theme %q", o.Theme)
+	}
+	fam, ok := families[o.Font]
+	if !ok {
+		return theme{}, nil, fmt.Errorf("unknown font %q", o.Font)
+	}
+	if err := fam.load(); err != nil {
+		return theme{}, nil, fmt.Errorf("load font %s: %w", o.Font, err)
+	}
+	return th, fam, nil
+}
+
+// PNG рисует карточку и пишет её в w
+func PNG(w io.Writer, quote, author string, o Options) error {
+	th, fam, err := resolve(o)
+	if err != nil {
+		return err
+	}
+	l, err := computeLayout(quote, author, o, fam)
+	if err != nil {
+		return err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, o.Width, o.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(th.background), image.Point{}, draw.Src)
+	draw.Draw(img, l.bar, image.NewUniform(th.accent), image.Point{}, draw.Src)
+
+	quoteFace, err := face(fam.reg, l.size)
+	if err != nil {
+		return err
+	}
+	defer quoteFace.Close()
+	d := font.Drawer{Dst: img, Src: image.NewUniform(th.text), Face: quoteFace}
+	for i, line := range l.lines {
+		d.Dot = fixed.P(int(l.x), int(l.y+float64(i)*l.lineHeight))
+		d.DrawString(line)
+	}
+
+	authorFace, err := face(fam.ital, l.authorSize)
+	if err != nil {
+		return err
+	}
+	defer authorFace.Close()
+	d = font.Drawer{Dst: img, Src: image.NewUniform(th.accent), Face: authorFace}
+	d.Dot = fixed.P(int(l.x), int(l.authorY))
+	d.DrawString(l.author)
+
+	return png.Encode(w, img)
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// SVG пишет карточку векторно; переносы строк посчитаны по метрикам шрифтов Go
+func SVG(w io.Writer, quote, author string, o Options) error {
+	th, fam, err := resolve(o)
+	if err != nil {
+		return err
+	}
+	l, err := computeLayout(quote, author, o, fam)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d">`+"\n", o.Width, o.Height)
+	fmt.Fprintf(&b, `  <rect width="100%%" height="100%%" fill="%s"/>`+"\n", hex(th.background))
+	fmt.Fprintf(&b, `  <rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+		l.bar.Min.X, l.bar.Min.Y, l.bar.Dx(), l.bar.Dy(), hex(th.accent))
+	fmt.Fprintf(&b, `  <text font-family="%s" font-size="%.1f" fill="%s">`+"\n", escape(fam.svg), l.size, hex(th.text))
+	for i, line := range l.lines {
+		fmt.Fprintf(&b, `    <tspan x="%.1f" y="%.1f">%s</tspan>`+"\n", l.x, l.y+float64(i)*l.lineHeight, escape(line))
+	}
+	b.WriteString("  </text>\n")
+	fmt.Fprintf(&b, `  <text x="%.1f" y="%.1f" font-family="%s" font-size="%.1f" font-style="italic" fill="%s">%s</text>`+"\n",
+		l.x, l.authorY, escape(fam.svg), l.authorSize, hex(th.accent), escape(l.author))
+	b.WriteString("</svg>\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,246 @@
+// Package citation оформляет ссылку на источник цитаты в стилях APA (7-е изд.),
+// MLA (9-е изд.) и Chicago (библиография). Оформление упрощённое: в источнике
+// нет места издания, даты выступления и прочих полей, которых стили требуют
+// для полной записи.
+package citation
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"quotebook/internal/models"
+)
+
+// Styles поддерживаемые стили
+var Styles = []string{models.CitationAPA, models.CitationMLA, models.CitationChicago}
+
+// Format ссылка на src автора author в стиле style: обычный текст и HTML,
+// где курсив оформлен через <i>
+func Format(style, author string, src *models.Source) (text, htmlText string, err error) {
+	var f func(b *builder, a name, src *models.Source)
+	switch style {
+	case models.CitationAPA:
+		f = apa
+	case models.CitationMLA:
+		f = mla
+	case models.CitationChicago:
+		f = chicago
+	default:
+		return "", "", fmt.Errorf("unknown citation style %q", style)
+	}
+
+	plain := &builder{}
+	marked := &builder{html: true}
+	a := parseName(author)
+	f(plain, a, src)
+	f(marked, a, src)
+	return plain.String(), marked.String(), nil
+}
+
+// builder собирает ссылку, экранируя HTML и расставляя курсив
+type builder struct {
+	strings.Builder
+	html bool
+}
+
+func (b *builder) text(s string) {
+	if b.html {
+		s = html.EscapeString(s)
+	}
+	b.WriteString(s)
+}
+
+func (b *builder) italic(s string) {
+	if b.html {
+		b.WriteString("<i>" + html.EscapeString(s) + "</i>")
+		return
+	}
+	b.WriteString(s)
+}
+
+// sentence дописывает s и точку, если s ею не заканчивается
+func (b *builder) sentence(s string) {
+	b.text(s)
+	if !endsWithPunct(s) {
+		b.WriteString(".")
+	}
+}
+
+func endsWithPunct(s string) bool {
+	s = strings.TrimRight(s, `"”»`)
+	return strings.HasSuffix(s, ".") || strings.HasSuffix(s, "?") || strings.HasSuffix(s, "!")
+}
+
+// name автор, разобранный на фамилию и имена. Одно слово ("Confucius")
+// остаётся как есть во всех стилях.
+type name struct {
+	full  string
+	last  string
+	given []string
+}
+
+func parseName(author string) name {
+	parts := strings.Fields(author)
+	n := name{full: strings.Join(parts, " ")}
+	if len(parts) < 2 {
+		n.last = n.full
+		return n
+	}
+	n.last = parts[len(parts)-1]
+	n.given = parts[:len(parts)-1]
+	return n
+}
+
+// inverted "Фамилия, Имена" для MLA и Chicago
+func (n name) inverted() string {
+	if len(n.given) == 0 {
+		return n.last
+	}
+	return n.last + ", " + strings.Join(n.given, " ")
+}
+
+// initials "Фамилия, И. О." для APA
+func (n name) initials() string {
+	if len(n.given) == 0 {
+		return n.last
+	}
+	inits := make([]string, 0, len(n.given))
+	for _, g := range n.given {
+		r := []rune(g)
+		if strings.HasSuffix(g, ".") && len(r) <= 3 {
+			inits = append(inits, g)
+			continue
+		}
+		inits = append(inits, string(unicode.ToUpper(r[0]))+".")
+	}
+	return n.last + ", " + strings.Join(inits, " ")
+}
+
+// medium пометка типа источника для выступлений и интервью
+func medium(src *models.Source) string {
+	switch src.Type {
+	case models.SourceSpeech:
+		return "Speech"
+	case models.SourceInterview:
+		return "Interview"
+	}
+	return ""
+}
+
+// italicTitle книги и прочие самостоятельные издания пишутся курсивом,
+// выступления, интервью и статьи — в кавычках
+func italicTitle(src *models.Source) bool {
+	return src.Type == models.SourceBook || src.Type == models.SourceOther
+}
+
+func pages(page string) string {
+	if strings.ContainsAny(page, "-–") {
+		return "pp. " + strings.ReplaceAll(page, "-", "–")
+	}
+	return "p. " + page
+}
+
+// apa Фамилия, И. (Год). Название [Тип]. Издатель. URL
+func apa(b *builder, a name, src *models.Source) {
+	b.sentence(a.initials())
+	b.text(" (")
+	if src.Year != 0 {
+		b.text(strconv.Itoa(src.Year))
+	} else {
+		b.text("n.d.")
+	}
+	b.text("). ")
+
+	b.italic(src.Title)
+	if m := medium(src); m != "" {
+		b.text(" [" + m + "]")
+	}
+	if !endsWithPunct(src.Title) || medium(src) != "" {
+		b.text(".")
+	}
+	if src.Publisher != "" {
+		b.text(" ")
+		b.sentence(src.Publisher)
+	}
+	if src.URL != "" {
+		b.text(" " + src.URL)
+	}
+}
+
+// mla Фамилия, Имя. Название. Издатель, Год, p. N. Тип. URL.
+func mla(b *builder, a name, src *models.Source) {
+	b.sentence(a.inverted())
+	b.text(" ")
+	if italicTitle(src) {
+		b.italic(src.Title)
+		if !endsWithPunct(src.Title) {
+			b.text(".")
+		}
+	} else {
+		b.text("“")
+		b.sentence(src.Title)
+		b.text("”")
+	}
+
+	var parts []string
+	if src.Publisher != "" {
+		parts = append(parts, src.Publisher)
+	}
+	if src.Year != 0 {
+		parts = append(parts, strconv.Itoa(src.Year))
+	}
+	if src.Page != "" {
+		parts = append(parts, pages(src.Page))
+	}
+	if len(parts) > 0 {
+		b.text(" ")
+		b.sentence(strings.Join(parts, ", "))
+	}
+	if m := medium(src); m != "" {
+		b.text(" " + m + ".")
+	}
+	if src.URL != "" {
+		// MLA пишет адрес без протокола
+		u := strings.TrimPrefix(strings.TrimPrefix(src.URL, "https://"), "http://")
+		b.text(" ")
+		b.sentence(u)
+	}
+}
+
+// chicago Фамилия, Имя. Название. Тип. Издатель, Год. URL.
+func chicago(b *builder, a name, src *models.Source) {
+	b.sentence(a.inverted())
+	b.text(" ")
+	if italicTitle(src) {
+		b.italic(src.Title)
+		if !endsWithPunct(src.Title) {
+			b.text(".")
+		}
+	} else {
+		b.text("“")
+		b.sentence(src.Title)
+		b.text("”")
+	}
+	if m := medium(src); m != "" {
+		b.text(" " + m + ".")
+	}
+
+	var parts []string
+	if src.Publisher != "" {
+		parts = append(parts, src.Publisher)
+	}
+	if src.Year != 0 {
+		parts = append(parts, strconv.Itoa(src.Year))
+	}
+	if len(parts) > 0 {
+		b.text(" ")
+		b.sentence(strings.Join(parts, ", "))
+	}
+	if src.URL != "" {
+		b.text(" ")
+		b.sentence(src.URL)
+	}
+}
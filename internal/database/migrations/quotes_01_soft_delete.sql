@@ -0,0 +1,11 @@
+-- Мягкое удаление: строка помечается deleted_at и попадает в корзину
+ALTER TABLE %[1]s.quotesbook
+  ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Большинство запросов читает только живые цитаты
+CREATE INDEX IF NOT EXISTS idx_quotesbook_alive
+  ON %[1]s.quotesbook (id) WHERE deleted_at IS NULL;
+
+-- Для корзины и фоновой очистки
+CREATE INDEX IF NOT EXISTS idx_quotesbook_deleted_at
+  ON %[1]s.quotesbook (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,21 @@
+ALTER TABLE %[1]s.quotesbook
+  ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- История изменений: полный снимок цитаты после каждой операции
+CREATE TABLE IF NOT EXISTS %[1]s.quote_revisions (
+    quote_id   INT NOT NULL,
+    rev        INT NOT NULL,
+    action     VARCHAR(16) NOT NULL,
+    author     VARCHAR(255) NOT NULL,
+    quote      TEXT NOT NULL,
+    actor      VARCHAR(255) NOT NULL,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (quote_id, rev)
+);
+
+-- Ревизии неизменяемы
+CREATE OR REPLACE RULE quote_revisions_no_update AS
+  ON UPDATE TO %[1]s.quote_revisions DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE quote_revisions_no_delete AS
+  ON DELETE TO %[1]s.quote_revisions DO INSTEAD NOTHING;
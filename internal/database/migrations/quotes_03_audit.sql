@@ -0,0 +1,29 @@
+-- Журнал аудита всех изменяющих запросов, отдельно от логов приложения
+CREATE TABLE IF NOT EXISTS %[1]s.audit_log (
+    id           BIGINT PRIMARY KEY,
+    created_at   TIMESTAMPTZ NOT NULL,
+    principal    VARCHAR(255) NOT NULL,
+    ip           VARCHAR(64) NOT NULL,
+    method       VARCHAR(16) NOT NULL,
+    route        VARCHAR(255) NOT NULL,
+    target_id    VARCHAR(64) NOT NULL DEFAULT '',
+    status       INT NOT NULL,
+    outcome      VARCHAR(16) NOT NULL,
+    request_id   VARCHAR(64) NOT NULL,
+    payload_hash VARCHAR(64) NOT NULL DEFAULT '',
+    prev_hash    VARCHAR(64) NOT NULL,
+    hash         VARCHAR(64) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at
+  ON %[1]s.audit_log (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_principal
+  ON %[1]s.audit_log (principal);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target
+  ON %[1]s.audit_log (target_id);
+
+-- Только дописывание
+CREATE OR REPLACE RULE audit_log_no_update AS
+  ON UPDATE TO %[1]s.audit_log DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE audit_log_no_delete AS
+  ON DELETE TO %[1]s.audit_log DO INSTEAD NOTHING;
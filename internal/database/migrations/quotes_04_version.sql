@@ -0,0 +1,3 @@
+-- Версия строки для оптимистичной блокировки (ETag / If-Match)
+ALTER TABLE %[1]s.quotesbook
+  ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+-- max(updated_at) для ETag/Last-Modified списка без полного скана
+CREATE INDEX IF NOT EXISTS idx_quotesbook_updated_at
+  ON %[1]s.quotesbook (updated_at);
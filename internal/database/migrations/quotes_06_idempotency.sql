@@ -0,0 +1,17 @@
+-- Ответы на запросы с Idempotency-Key, чтобы повтор не выполнялся второй раз
+CREATE TABLE IF NOT EXISTS %[1]s.idempotency_keys (
+    principal    VARCHAR(255) NOT NULL,
+    key          VARCHAR(255) NOT NULL,
+    fingerprint  VARCHAR(64) NOT NULL DEFAULT '',
+    completed    BOOLEAN NOT NULL DEFAULT false,
+    status       INT NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    etag         VARCHAR(255) NOT NULL DEFAULT '',
+    body         BYTEA,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (principal, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at
+  ON %[1]s.idempotency_keys (expires_at);
@@ -0,0 +1,14 @@
+-- Поиск дублей при импорте: одинаковый текст без учёта регистра и пробелов по краям
+CREATE INDEX IF NOT EXISTS idx_quotesbook_quote_key
+  ON %[1]s.quotesbook (md5(lower(btrim(quote)))) WHERE deleted_at IS NULL;
+
+-- Фоновые импорты больших файлов
+CREATE TABLE IF NOT EXISTS %[1]s.import_jobs (
+    id          UUID PRIMARY KEY,
+    status      VARCHAR(16) NOT NULL,
+    result      JSONB,
+    error       TEXT NOT NULL DEFAULT '',
+    created_by  VARCHAR(255) NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
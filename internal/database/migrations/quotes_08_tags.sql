@@ -0,0 +1,15 @@
+-- Теги цитаты: строчные, без повторов, нормализует сервис
+ALTER TABLE %[1]s.quotesbook
+  ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Поиск по тегу: tags @> ARRAY[$1]
+CREATE INDEX IF NOT EXISTS idx_quotesbook_tags
+  ON %[1]s.quotesbook USING GIN (tags);
+
+-- Ревизия хранит теги вместе с текстом, чтобы откат возвращал и их
+ALTER TABLE %[1]s.quote_revisions
+  ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Ленты отдают свежие цитаты
+CREATE INDEX IF NOT EXISTS idx_quotesbook_created_at
+  ON %[1]s.quotesbook (created_at DESC) WHERE deleted_at IS NULL;
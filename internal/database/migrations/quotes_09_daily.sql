@@ -0,0 +1,14 @@
+-- Цитата дня: одна строка на календарный день. Кто первым запросил день,
+-- тот и выбирает цитату, остальные реплики читают готовую строку.
+CREATE TABLE IF NOT EXISTS %[1]s.daily_quotes (
+    day       DATE PRIMARY KEY,
+    quote_id  INT NOT NULL REFERENCES %[1]s.quotesbook (id) ON DELETE CASCADE,
+    -- номер круга: цитата не повторяется, пока в круге есть непоказанные
+    cycle     INT NOT NULL,
+    pinned    BOOLEAN NOT NULL DEFAULT false,
+    pinned_by VARCHAR(255) NOT NULL DEFAULT '',
+    chosen_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_quotesbook_daily_cycle
+  ON %[1]s.daily_quotes (cycle, quote_id);
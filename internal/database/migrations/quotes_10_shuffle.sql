@@ -0,0 +1,15 @@
+-- Перемешанный обход цитат для клиента. Порядок задаёт seed (см. пакет shuffle),
+-- поэтому хранится только позиция в перестановке id 1..size.
+CREATE TABLE IF NOT EXISTS %[1]s.shuffle_sessions (
+    token      UUID PRIMARY KEY,
+    seed       BIGINT NOT NULL,
+    -- max(id) живых цитат на начало круга; новые цитаты попадут в следующий
+    size       INT NOT NULL,
+    position   INT NOT NULL DEFAULT 0,
+    round      INT NOT NULL DEFAULT 1,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Для фоновой очистки заброшенных обходов
+CREATE INDEX IF NOT EXISTS idx_quotesbook_shuffle_updated_at
+  ON %[1]s.shuffle_sessions (updated_at);
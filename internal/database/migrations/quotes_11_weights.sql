@@ -0,0 +1,9 @@
+-- Вес цитаты для GET /quotes/random?strategy=weight, 0 — никогда не выпадает
+ALTER TABLE %[1]s.quotesbook
+  ADD COLUMN IF NOT EXISTS weight DOUBLE PRECISION NOT NULL DEFAULT 1 CHECK (weight >= 0);
+
+-- Денормализованные счётчики для стратегий rating и views
+ALTER TABLE %[1]s.quotesbook
+  ADD COLUMN IF NOT EXISTS rating_sum INT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS rating_count INT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS views BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,26 @@
+-- Избранное пользователей (X-User); счётчик денормализован в quotesbook.favorites
+CREATE TABLE IF NOT EXISTS %[1]s.quote_favorites (
+    principal  VARCHAR(255) NOT NULL,
+    quote_id   INT NOT NULL REFERENCES %[1]s.quotesbook (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (principal, quote_id)
+);
+
+-- Оценки 1–5; сумма и число оценок денормализованы в quotesbook.rating_sum и rating_count
+CREATE TABLE IF NOT EXISTS %[1]s.quote_ratings (
+    quote_id   INT NOT NULL REFERENCES %[1]s.quotesbook (id) ON DELETE CASCADE,
+    principal  VARCHAR(255) NOT NULL,
+    stars      SMALLINT NOT NULL CHECK (stars BETWEEN 1 AND 5),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (quote_id, principal)
+);
+
+-- engaged_at — время последнего изменения счётчиков: version и updated_at
+-- относятся к тексту цитаты, а отпечаток для условных GET должен видеть и счётчики
+ALTER TABLE %[1]s.quotesbook
+  ADD COLUMN IF NOT EXISTS favorites INT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS engaged_at TIMESTAMPTZ;
+
+-- GET /quotes?sort=popular
+CREATE INDEX IF NOT EXISTS idx_quotesbook_popular
+  ON %[1]s.quotesbook (favorites DESC, id) WHERE deleted_at IS NULL;
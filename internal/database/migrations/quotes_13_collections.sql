@@ -0,0 +1,26 @@
+-- Подборки цитат; приватные видит только владелец (X-User)
+CREATE TABLE IF NOT EXISTS %[1]s.collections (
+    id          SERIAL PRIMARY KEY,
+    owner       VARCHAR(255) NOT NULL,
+    name        VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    public      BOOLEAN NOT NULL DEFAULT false,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_quotesbook_collections_owner
+  ON %[1]s.collections (owner, id);
+
+-- Состав подборки; position — порядок с 1 без пропусков. Цитата в корзине
+-- остаётся в подборке и возвращается вместе с восстановлением.
+CREATE TABLE IF NOT EXISTS %[1]s.collection_items (
+    collection_id INT NOT NULL REFERENCES %[1]s.collections (id) ON DELETE CASCADE,
+    quote_id      INT NOT NULL REFERENCES %[1]s.quotesbook (id) ON DELETE CASCADE,
+    position      INT NOT NULL,
+    added_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (collection_id, quote_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quotesbook_collection_items_position
+  ON %[1]s.collection_items (collection_id, position);
@@ -0,0 +1,4 @@
+-- Источник цитаты: {"type","title","year","page","url","isbn","publisher"},
+-- проверяется сервисом при записи
+ALTER TABLE %[1]s.quotesbook
+  ADD COLUMN IF NOT EXISTS source JSONB;
@@ -0,0 +1,30 @@
+-- Статус авторства меняет только проверяющий (/admin), обычная правка его не трогает
+ALTER TABLE %[1]s.quotesbook
+  ADD COLUMN IF NOT EXISTS attribution VARCHAR(16) NOT NULL DEFAULT 'unknown'
+    CHECK (attribution IN ('verified', 'disputed', 'misattributed', 'unknown')),
+  ADD COLUMN IF NOT EXISTS attribution_note TEXT NOT NULL DEFAULT '';
+
+-- Оспаривания авторства: пользователь предлагает статус, проверяющий принимает или отклоняет
+CREATE TABLE IF NOT EXISTS %[1]s.quote_disputes (
+    id          SERIAL PRIMARY KEY,
+    quote_id    INT NOT NULL REFERENCES %[1]s.quotesbook (id) ON DELETE CASCADE,
+    filed_by    VARCHAR(255) NOT NULL,
+    proposed    VARCHAR(16) NOT NULL
+      CHECK (proposed IN ('verified', 'disputed', 'misattributed', 'unknown')),
+    reason      TEXT NOT NULL,
+    evidence    TEXT NOT NULL DEFAULT '',
+    status      VARCHAR(16) NOT NULL DEFAULT 'open'
+      CHECK (status IN ('open', 'accepted', 'rejected')),
+    reviewer    VARCHAR(255),
+    review_note TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    reviewed_at TIMESTAMPTZ
+);
+
+-- у пользователя одно открытое оспаривание на цитату
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quotesbook_disputes_open
+  ON %[1]s.quote_disputes (quote_id, filed_by) WHERE status = 'open';
+
+-- очередь проверяющего
+CREATE INDEX IF NOT EXISTS idx_quotesbook_disputes_status
+  ON %[1]s.quote_disputes (status, created_at);
@@ -0,0 +1,21 @@
+-- Язык цитаты (ISO 639-1, und — не определён); у старых цитат он неизвестен
+ALTER TABLE %[1]s.quotesbook
+  ADD COLUMN IF NOT EXISTS lang VARCHAR(8) NOT NULL DEFAULT 'und';
+
+CREATE INDEX IF NOT EXISTS idx_quotesbook_lang
+  ON %[1]s.quotesbook (lang) WHERE deleted_at IS NULL;
+
+-- Переводы цитаты: по одному на язык, автор и источник общие с оригиналом
+CREATE TABLE IF NOT EXISTS %[1]s.quote_translations (
+    quote_id   INT NOT NULL REFERENCES %[1]s.quotesbook (id) ON DELETE CASCADE,
+    lang       VARCHAR(8) NOT NULL,
+    quote      TEXT NOT NULL,
+    translator VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (quote_id, lang)
+);
+
+-- ?lang= ищет и по переводам
+CREATE INDEX IF NOT EXISTS idx_quotesbook_translations_lang
+  ON %[1]s.quote_translations (lang, quote_id);
@@ -0,0 +1,10 @@
+-- Триграммы для подсказок и нечёткого поиска автора. Расширение ставится в схему
+-- сервиса: только она есть в search_path
+CREATE EXTENSION IF NOT EXISTS pg_trgm WITH SCHEMA %[1]s;
+
+CREATE INDEX IF NOT EXISTS idx_quotesbook_author_trgm
+  ON %[1]s.quotesbook USING gin (lower(author) gin_trgm_ops) WHERE deleted_at IS NULL;
+
+-- Сравнение без учёта регистра и поиск по началу имени
+CREATE INDEX IF NOT EXISTS idx_quotesbook_author_lower
+  ON %[1]s.quotesbook (lower(author) text_pattern_ops) WHERE deleted_at IS NULL;
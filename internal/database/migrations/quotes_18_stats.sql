@@ -0,0 +1,6 @@
+-- Сколько раз цитата выпала в GET /quotes/random, для /stats
+ALTER TABLE %[1]s.quotesbook
+  ADD COLUMN IF NOT EXISTS served BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_quotesbook_served
+  ON %[1]s.quotesbook (served DESC) WHERE deleted_at IS NULL AND served > 0;
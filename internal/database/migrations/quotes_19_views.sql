@@ -0,0 +1,11 @@
+-- Просмотры цитат по часам для GET /quotes/trending. Итоги за всё время
+-- денормализованы в quotesbook.views и served; старые часы удаляет фоновая задача.
+CREATE TABLE IF NOT EXISTS %[1]s.quote_views (
+    quote_id INT NOT NULL REFERENCES %[1]s.quotesbook (id) ON DELETE CASCADE,
+    hour     TIMESTAMPTZ NOT NULL,
+    hits     BIGINT NOT NULL,
+    PRIMARY KEY (quote_id, hour)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quote_views_hour
+  ON %[1]s.quote_views (hour);
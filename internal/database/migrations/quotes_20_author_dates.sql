@@ -0,0 +1,11 @@
+-- Даты жизни авторов для GET /quotes/on-this-day. Автор цитаты — строка,
+-- поэтому запись ищется по lower(author), как ?author=. Авторов немного,
+-- годовщины дня ищутся полным просмотром.
+CREATE TABLE IF NOT EXISTS %[1]s.authors (
+    key        TEXT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    born       DATE,
+    died       DATE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (born IS NULL OR died IS NULL OR died >= born)
+);
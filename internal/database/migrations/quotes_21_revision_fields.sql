@@ -0,0 +1,7 @@
+-- Ревизия хранит и источник, вес и язык, чтобы откат возвращал их. У ревизий,
+-- записанных раньше, они неизвестны (NULL), и откат к такой ревизии их не
+-- трогает; цитата без источника записывается как JSON null.
+ALTER TABLE %[1]s.quote_revisions
+  ADD COLUMN IF NOT EXISTS source JSONB,
+  ADD COLUMN IF NOT EXISTS weight DOUBLE PRECISION,
+  ADD COLUMN IF NOT EXISTS lang VARCHAR(8);
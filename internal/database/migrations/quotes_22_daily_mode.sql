@@ -0,0 +1,12 @@
+-- Цитата дня хранится отдельно для каждого режима: в режиме on-this-day
+-- сохраняется цитата юбиляра и чья это годовщина, так что история совпадает
+-- с показанным. Такие строки идут с cycle = 0 и круг rotation не расходуют.
+ALTER TABLE %[1]s.daily_quotes
+  ADD COLUMN IF NOT EXISTS mode VARCHAR(16) NOT NULL DEFAULT 'rotation',
+  ADD COLUMN IF NOT EXISTS anniversary_event VARCHAR(8),
+  ADD COLUMN IF NOT EXISTS anniversary_date DATE;
+
+ALTER TABLE %[1]s.daily_quotes DROP CONSTRAINT IF EXISTS daily_quotes_pkey;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_daily_quotes_day_mode
+  ON %[1]s.daily_quotes (day, mode);
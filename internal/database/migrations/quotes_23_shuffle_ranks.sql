@@ -0,0 +1,29 @@
+-- Обход переставляет не id, а ранги в снимке круга: shuffle_members
+-- запоминает, какие цитаты были живы в начале круга и в каком порядке.
+-- Дыры в id не дают пустых позиций; восстановленные и новые цитаты попадают
+-- только в следующий круг, удалённые и стёртые пропускаются, а ранги
+-- остальных не сдвигаются. Круги с одинаковым набором цитат делят снимок.
+-- Обходы без снимка (начатые по id) со следующего запроса начинают новый круг.
+CREATE TABLE IF NOT EXISTS %[1]s.shuffle_snapshots (
+    id          BIGSERIAL PRIMARY KEY,
+    -- md5 id живых цитат по возрастанию
+    fingerprint VARCHAR(32) NOT NULL,
+    size        INT NOT NULL,
+    used_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shuffle_snapshots_fingerprint
+  ON %[1]s.shuffle_snapshots (fingerprint);
+
+CREATE TABLE IF NOT EXISTS %[1]s.shuffle_members (
+    snapshot_id BIGINT NOT NULL REFERENCES %[1]s.shuffle_snapshots (id) ON DELETE CASCADE,
+    rank        INT NOT NULL,
+    quote_id    INT NOT NULL,
+    PRIMARY KEY (snapshot_id, rank)
+);
+
+ALTER TABLE %[1]s.shuffle_sessions
+  ADD COLUMN IF NOT EXISTS snapshot_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_shuffle_sessions_snapshot
+  ON %[1]s.shuffle_sessions (snapshot_id);
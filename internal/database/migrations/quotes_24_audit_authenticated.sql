@@ -0,0 +1,4 @@
+-- principal из X-User клиент указывает сам; authenticated отмечает записи,
+-- где запрос подтверждён токеном admin.token
+ALTER TABLE %[1]s.audit_log
+  ADD COLUMN IF NOT EXISTS authenticated BOOLEAN NOT NULL DEFAULT false;
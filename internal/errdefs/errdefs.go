@@ -16,6 +16,15 @@ var (
 	ErrMigrationFailed = errors.New("Migration failed")
 	ErrNoBackends	   = errors.New("Not free backend")
 	ErrRateLimitExceeded = errors.New("ErrRateLimitExceeded")
+	// версия изменилась с момента чтения
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrPreconditionRequired = errors.New("precondition required")
+	// Idempotency-Key повторно использован с другим запросом
+	ErrIdempotencyMismatch = errors.New("idempotency key reused with different request")
+	// действие требует представиться (X-User)
+	ErrUnauthorized = errors.New("unauthorized")
+	// пользователь известен, но действие ему не разрешено
+	ErrForbidden = errors.New("forbidden")
 )
 
 // fmt.Errorf с %w
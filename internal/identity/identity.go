@@ -0,0 +1,35 @@
+package identity
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const (
+	ActorKey = "Actor"
+	// авторизации пока нет, клиент представляется сам
+	ActorHeader = "X-User"
+	Anonymous   = "anonymous"
+)
+
+func CtxWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, ActorKey, actor)
+}
+
+// ActorFromCtx возвращает того, кто выполняет запрос, или Anonymous
+func ActorFromCtx(ctx context.Context) string {
+	if actor, ok := ctx.Value(ActorKey).(string); ok && actor != "" {
+		return actor
+	}
+	return Anonymous
+}
+
+// ActorFromRequest достаёт имя клиента из заголовка X-User
+func ActorFromRequest(r *http.Request) string {
+	actor := strings.TrimSpace(r.Header.Get(ActorHeader))
+	if actor == "" {
+		return Anonymous
+	}
+	return actor
+}
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"quotebook/internal/models"
 )
@@ -9,7 +10,114 @@ import (
 type IQuoteRepository interface {
     CreateQuote(ctx context.Context, q *models.Quote) (int, error)
     QuotesAll(ctx context.Context) (*[]models.Quote, error)
+    SortedQuotes(ctx context.Context, sort string) (*[]models.Quote, error)
     QuoteByAuthor(ctx context.Context, author string) (*[]models.Quote, error)
+    FuzzyQuotesByAuthor(ctx context.Context, author string, threshold float64) (*[]models.Quote, error)
+    SimilarAuthors(ctx context.Context, name string, threshold float64, limit int) (*[]models.AuthorSuggestion, error)
+    SuggestAuthors(ctx context.Context, prefix string, limit int) (*[]models.AuthorSuggestion, error)
+    StreamQuotes(ctx context.Context, f *models.QuoteFilter, fn func(q *models.Quote) error) error
+    RecentQuotes(ctx context.Context, f *models.QuoteFilter, limit int) (*[]models.Quote, error)
+    QuotesStamp(ctx context.Context) (*models.QuotesStamp, error)
     RandQuote(ctx context.Context) (*models.Quote, error)
-    DeleteQuote(ctx context.Context, id int) error
+    RandQuoteByLang(ctx context.Context, lang string) (*models.Quote, error)
+    QuoteWeights(ctx context.Context) (*[]models.QuoteWeight, error)
+    GetQuote(ctx context.Context, id int) (*models.Quote, error)
+    QuotesByIDs(ctx context.Context, ids []int) (*[]models.Quote, error)
+    QuotesChangedSince(ctx context.Context, since time.Time) (*[]models.Quote, error)
+    GetAuthor(ctx context.Context, name string) (*models.Author, error)
+    PutAuthor(ctx context.Context, a *models.Author) (*models.Author, error)
+    DeleteQuote(ctx context.Context, id, version int) error
+    TrashQuotes(ctx context.Context) (*[]models.Quote, error)
+    RestoreQuote(ctx context.Context, id int) error
+    PurgeQuotes(ctx context.Context, before time.Time) (int64, error)
+    UpdateQuote(ctx context.Context, q *models.Quote) error
+    PatchQuote(ctx context.Context, id, version int, p *models.QuotePatch) (*models.Quote, error)
+    QuoteRevisions(ctx context.Context, id int) (*[]models.QuoteRevision, error)
+    QuoteRevision(ctx context.Context, id, rev int) (*models.QuoteRevision, error)
+    RevertQuote(ctx context.Context, id, rev int) error
+}
+
+type IAuditRepository interface {
+    AppendAudit(ctx context.Context, e *models.AuditEntry) error
+    AuditEntries(ctx context.Context, f *models.AuditFilter) (*[]models.AuditEntry, error)
+    StreamAudit(ctx context.Context, f *models.AuditFilter, fn func(e *models.AuditEntry) error) error
+}
+
+type IIdempotencyRepository interface {
+    ReserveKey(ctx context.Context, principal, key string, expiresAt time.Time) (*models.IdempotencyRecord, bool, error)
+    CompleteKey(ctx context.Context, rec *models.IdempotencyRecord) error
+    ReleaseKey(ctx context.Context, principal, key string) error
+    PurgeExpiredKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+type IDailyRepository interface {
+    DailyQuote(ctx context.Context, day time.Time) (*models.DailyQuote, error)
+    DailyHistory(ctx context.Context, until time.Time, mode string, limit int) (*[]models.DailyQuote, error)
+    PinDailyQuote(ctx context.Context, day time.Time, quoteID int) (*models.DailyQuote, error)
+    OnThisDayQuote(ctx context.Context, day time.Time) (*models.DailyQuote, error)
+    SaveOnThisDayQuote(ctx context.Context, day time.Time, aq *models.AnniversaryQuote) (*models.DailyQuote, error)
+    OnThisDay(ctx context.Context, days []string) (*[]models.AnniversaryQuote, error)
+}
+
+type IShuffleRepository interface {
+    CreateShuffle(ctx context.Context, s *models.ShuffleSession) error
+    GetShuffle(ctx context.Context, token string) (*models.ShuffleSession, error)
+    UpdateShuffle(ctx context.Context, s *models.ShuffleSession, fromPosition, fromRound int) error
+    ShuffleRound(ctx context.Context) (*models.ShuffleSession, error)
+    ShuffleQuotes(ctx context.Context, s *models.ShuffleSession, ranks []int) (map[int]models.Quote, error)
+    PurgeShuffles(ctx context.Context, before time.Time) (int64, error)
+}
+
+type IEngagementRepository interface {
+    AddFavorite(ctx context.Context, id int, principal string) (*models.Engagement, error)
+    RemoveFavorite(ctx context.Context, id int, principal string) (*models.Engagement, error)
+    RateQuote(ctx context.Context, id int, principal string, stars int) (*models.Engagement, error)
+    RemoveRating(ctx context.Context, id int, principal string) (*models.Engagement, error)
+    FavoriteQuotes(ctx context.Context, principal string) (*[]models.Quote, error)
+}
+
+type ICollectionRepository interface {
+    CreateCollection(ctx context.Context, c *models.Collection) error
+    GetCollection(ctx context.Context, id int) (*models.Collection, error)
+    ListCollections(ctx context.Context, viewer, owner string) (*[]models.Collection, error)
+    UpdateCollection(ctx context.Context, id int, p *models.CollectionPatch) (*models.Collection, error)
+    DeleteCollection(ctx context.Context, id int) error
+    CollectionQuotes(ctx context.Context, id int) (*[]models.Quote, error)
+    RandCollectionQuote(ctx context.Context, id int) (*models.Quote, error)
+    AddToCollection(ctx context.Context, id, quoteID, position int) error
+    RemoveFromCollection(ctx context.Context, id, quoteID int) error
+    ReorderCollection(ctx context.Context, id int, quoteIDs []int) error
+}
+
+type IDisputeRepository interface {
+    CreateDispute(ctx context.Context, d *models.Dispute) error
+    QuoteDisputes(ctx context.Context, quoteID int) (*[]models.Dispute, error)
+    Disputes(ctx context.Context, status string, limit int) (*[]models.Dispute, error)
+    ResolveDispute(ctx context.Context, id int, reviewer string, r *models.DisputeReview) (*models.Dispute, error)
+    SetAttribution(ctx context.Context, quoteID int, u *models.AttributionUpdate) (*models.Quote, error)
+}
+
+type ITranslationRepository interface {
+    PutTranslation(ctx context.Context, t *models.Translation) (bool, error)
+    GetTranslation(ctx context.Context, quoteID int, lang string) (*models.Translation, error)
+    Translations(ctx context.Context, quoteID int) (*[]models.Translation, error)
+    TranslationsOf(ctx context.Context, ids []int, langs []string) (*[]models.Translation, error)
+    DeleteTranslation(ctx context.Context, quoteID int, lang string) error
+}
+
+type IStatsRepository interface {
+    Stats(ctx context.Context, top int, interval string, buckets int) (*models.Stats, error)
+}
+
+type IViewRepository interface {
+    AddViews(ctx context.Context, counts []models.ViewCount) error
+    Trending(ctx context.Context, since time.Time, halfLife time.Duration, limit int) (*[]models.TrendingQuote, error)
+    PurgeViews(ctx context.Context, before time.Time) (int64, error)
+}
+
+type IImportRepository interface {
+    ImportQuotes(ctx context.Context, opts *models.ImportOptions, next func() ([]models.ImportRow, error)) (*models.ImportCounts, error)
+    CreateImportJob(ctx context.Context, job *models.ImportJob) error
+    UpdateImportJob(ctx context.Context, job *models.ImportJob) error
+    GetImportJob(ctx context.Context, id string) (*models.ImportJob, error)
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package interfaces
 
 import (
     "context"
+    "io"
 
     "quotebook/internal/models"
 )
@@ -9,7 +10,122 @@ import (
 type IQuoteService interface {
     CreateQuote(ctx context.Context, b *models.Quote) (int, error)
     QuotesAll(ctx context.Context) (*[]models.Quote, error)
+    SortedQuotes(ctx context.Context, sort string) (*[]models.Quote, error)
+    Citation(ctx context.Context, id int, style string) (*models.Citation, error)
     QuoteByAuthor(ctx context.Context, author string) (*[]models.Quote, error)
+    DidYouMean(ctx context.Context, author string) ([]string, error)
+    SuggestAuthors(ctx context.Context, prefix string, limit int) (*[]models.AuthorSuggestion, error)
+    Author(ctx context.Context, name string) (*models.Author, error)
+    SetAuthorDates(ctx context.Context, name string, dates *models.Author) (*models.Author, error)
+    ExportQuotes(ctx context.Context, f *models.QuoteFilter, fn func(q *models.Quote) error) error
+    RecentQuotes(ctx context.Context, f *models.QuoteFilter) (*[]models.Quote, error)
+    FilterQuotes(ctx context.Context, f *models.QuoteFilter) (*[]models.Quote, error)
+    FilterQuotesByAuthor(ctx context.Context, f *models.QuoteFilter) (*[]models.Quote, error)
+    QuotesStamp(ctx context.Context) (*models.QuotesStamp, error)
     RandQuote(ctx context.Context) (*models.Quote, error)
-    DeleteQuote(ctx context.Context, id int) error
-}
\ No newline at end of file
+    RandQuoteByLang(ctx context.Context, lang string) (*models.Quote, error)
+    WeightedQuote(ctx context.Context, strategy string) (*models.Quote, error)
+    GetQuote(ctx context.Context, id int) (*models.Quote, error)
+    QuotesByIDs(ctx context.Context, ids []int) (*models.QuoteBatch, error)
+    DeleteQuote(ctx context.Context, id, version int) error
+    TrashQuotes(ctx context.Context) (*[]models.Quote, error)
+    RestoreQuote(ctx context.Context, id int) error
+    PurgeTrash(ctx context.Context) (int64, error)
+    UpdateQuote(ctx context.Context, q *models.Quote) error
+    PatchQuote(ctx context.Context, id, version int, p *models.QuotePatch) (*models.Quote, error)
+    QuoteRevisions(ctx context.Context, id int) (*[]models.QuoteRevision, error)
+    QuoteRevision(ctx context.Context, id, rev int) (*models.QuoteRevision, error)
+    DiffQuoteRevisions(ctx context.Context, id, from, to int) (string, error)
+    RevertQuote(ctx context.Context, id, rev int) error
+}
+
+type IAuditService interface {
+    Record(ctx context.Context, e *models.AuditEntry) error
+    Entries(ctx context.Context, f *models.AuditFilter) (*[]models.AuditEntry, error)
+    Export(ctx context.Context, f *models.AuditFilter, fn func(e *models.AuditEntry) error) error
+    Verify(ctx context.Context) (*models.AuditVerification, error)
+}
+
+type IIdempotencyService interface {
+    Enabled() bool
+    Begin(ctx context.Context, principal, key string) (*models.IdempotencyRecord, error)
+    CheckReplay(rec *models.IdempotencyRecord, fingerprint string) error
+    Complete(ctx context.Context, rec *models.IdempotencyRecord) error
+    Release(ctx context.Context, principal, key string) error
+    PurgeExpired(ctx context.Context) (int64, error)
+}
+
+type IImportService interface {
+    Import(ctx context.Context, opts *models.ImportOptions, body io.Reader) (*models.ImportResult, error)
+    StartImport(ctx context.Context, opts *models.ImportOptions, path string) (*models.ImportJob, error)
+    ImportJob(ctx context.Context, id string) (*models.ImportJob, error)
+}
+
+type ICardService interface {
+    QuoteCard(ctx context.Context, id int, opts *models.CardOptions) (*models.Card, error)
+}
+
+type IDailyService interface {
+    DailyQuote(ctx context.Context, tz, mode string) (*models.DailyQuote, error)
+    OnThisDay(ctx context.Context, tz string) (*models.OnThisDay, error)
+    DailyHistory(ctx context.Context, tz, mode string, limit int) (*[]models.DailyQuote, error)
+    PinDailyQuote(ctx context.Context, date string, quoteID int) (*models.DailyQuote, error)
+}
+
+type IShuffleService interface {
+    NextQuote(ctx context.Context, token string) (*models.Quote, string, error)
+    PurgeExpired(ctx context.Context) (int64, error)
+}
+
+type IEngagementService interface {
+    Favorite(ctx context.Context, id int) (*models.Engagement, error)
+    Unfavorite(ctx context.Context, id int) (*models.Engagement, error)
+    Rate(ctx context.Context, id, stars int) (*models.Engagement, error)
+    Unrate(ctx context.Context, id int) (*models.Engagement, error)
+    Favorites(ctx context.Context) (*[]models.Quote, error)
+}
+
+type ICollectionService interface {
+    CreateCollection(ctx context.Context, c *models.Collection) (*models.Collection, error)
+    Collections(ctx context.Context, owner string) (*[]models.Collection, error)
+    GetCollection(ctx context.Context, id int) (*models.Collection, error)
+    UpdateCollection(ctx context.Context, id int, p *models.CollectionPatch) (*models.Collection, error)
+    DeleteCollection(ctx context.Context, id int) error
+    AddQuote(ctx context.Context, id, quoteID, position int) (*models.Collection, error)
+    RemoveQuote(ctx context.Context, id, quoteID int) (*models.Collection, error)
+    ReorderCollection(ctx context.Context, id int, quoteIDs []int) (*models.Collection, error)
+    RandomQuote(ctx context.Context, id int) (*models.Quote, error)
+    ExportCollection(ctx context.Context, id int, fn func(q *models.Quote) error) error
+}
+
+type IDisputeService interface {
+    FileDispute(ctx context.Context, quoteID int, d *models.Dispute) (*models.Dispute, error)
+    QuoteDisputes(ctx context.Context, quoteID int) (*[]models.Dispute, error)
+    Disputes(ctx context.Context, status string, limit int) (*[]models.Dispute, error)
+    ResolveDispute(ctx context.Context, id int, r *models.DisputeReview) (*models.Dispute, error)
+    SetAttribution(ctx context.Context, quoteID int, u *models.AttributionUpdate) (*models.Quote, error)
+}
+
+type ITranslationService interface {
+    PutTranslation(ctx context.Context, quoteID int, lang string, t *models.Translation) (*models.Translation, bool, error)
+    Translation(ctx context.Context, quoteID int, lang string) (*models.Translation, error)
+    Translations(ctx context.Context, quoteID int) (*[]models.Translation, error)
+    DeleteTranslation(ctx context.Context, quoteID int, lang string) error
+    Localize(ctx context.Context, quotes []models.Quote, prefs []string) error
+}
+
+type IStatsService interface {
+    Stats(ctx context.Context, top int, interval string) (*models.Stats, error)
+}
+
+type ISimilarService interface {
+    Similar(ctx context.Context, id, limit int) (*[]models.SimilarQuote, error)
+}
+
+type IViewService interface {
+    View(id int)
+    Serve(id int)
+    Flush(ctx context.Context) (int, error)
+    Trending(ctx context.Context, window string, limit int) (*[]models.TrendingQuote, error)
+    PurgeExpired(ctx context.Context) (int64, error)
+}
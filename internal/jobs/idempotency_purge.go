@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"quotebook/config"
+	"quotebook/internal/interfaces"
+	"quotebook/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// StartIdempotencyPurge удаляет истёкшие Idempotency-Key
+func StartIdempotencyPurge(ctx context.Context, lg *logger.Logger, cfg *config.Config, idem interfaces.IIdempotencyService) {
+	interval := time.Duration(cfg.Idempotency.PurgeInterval)
+	if interval <= 0 || !idem.Enabled() {
+		lg.Info(ctx, "idempotency purge disabled")
+		return
+	}
+
+	runEvery(ctx, interval, func(ctx context.Context) {
+		purged, err := idem.PurgeExpired(ctx)
+		if err != nil {
+			lg.Error(ctx, "idempotency purge failed", zap.Error(err))
+			return
+		}
+		if purged > 0 {
+			lg.Info(ctx, "idempotency keys purged", zap.Int64("purged", purged))
+		}
+	})
+}
@@ -0,0 +1,22 @@
+package jobs
+
+import (
+	"context"
+	"time"
+)
+
+// runEvery вызывает fn сразу и затем каждые interval, пока жив ctx
+func runEvery(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			fn(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
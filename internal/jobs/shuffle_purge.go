@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"quotebook/config"
+	"quotebook/internal/interfaces"
+	"quotebook/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// StartShufflePurge удаляет обходы /quotes/random?shuffle=true, заброшенные клиентами
+func StartShufflePurge(ctx context.Context, lg *logger.Logger, cfg *config.Config, shuffles interfaces.IShuffleService) {
+	interval := time.Duration(cfg.Shuffle.PurgeInterval)
+	if interval <= 0 || cfg.Shuffle.TTL <= 0 {
+		lg.Info(ctx, "shuffle purge disabled")
+		return
+	}
+
+	runEvery(ctx, interval, func(ctx context.Context) {
+		purged, err := shuffles.PurgeExpired(ctx)
+		if err != nil {
+			lg.Error(ctx, "shuffle purge failed", zap.Error(err))
+			return
+		}
+		if purged > 0 {
+			lg.Info(ctx, "shuffle sessions purged", zap.Int64("purged", purged))
+		}
+	})
+}
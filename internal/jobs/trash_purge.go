@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"quotebook/config"
+	"quotebook/internal/interfaces"
+	"quotebook/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// StartTrashPurge периодически окончательно удаляет старые цитаты из корзины
+func StartTrashPurge(ctx context.Context, lg *logger.Logger, cfg *config.Config, qbs interfaces.IQuoteService) {
+	interval := time.Duration(cfg.Trash.PurgeInterval)
+	if interval <= 0 {
+		lg.Info(ctx, "trash purge disabled")
+		return
+	}
+
+	runEvery(ctx, interval, func(ctx context.Context) {
+		purged, err := qbs.PurgeTrash(ctx)
+		if err != nil {
+			lg.Error(ctx, "trash purge failed", zap.Error(err))
+			return
+		}
+		if purged > 0 {
+			lg.Info(ctx, "trash purged", zap.Int64("purged", purged))
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"quotebook/config"
+	"quotebook/internal/interfaces"
+	"quotebook/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+const defaultViewFlushInterval = 10 * time.Second
+
+// StartViewFlush периодически пишет в базу просмотры, накопленные в памяти.
+// Выключить нельзя: без сброса счётчики только растут. Последний сброс при
+// остановке делает main.
+func StartViewFlush(ctx context.Context, lg *logger.Logger, cfg *config.Config, views interfaces.IViewService) {
+	interval := time.Duration(cfg.Views.FlushInterval)
+	if interval <= 0 {
+		interval = defaultViewFlushInterval
+	}
+
+	runEvery(ctx, interval, func(ctx context.Context) {
+		flushed, err := views.Flush(ctx)
+		if err != nil {
+			lg.Error(ctx, "view flush failed", zap.Error(err))
+			return
+		}
+		if flushed > 0 {
+			lg.Debug(ctx, "views flushed", zap.Int("rows", flushed))
+		}
+	})
+}
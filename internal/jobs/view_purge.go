@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"quotebook/config"
+	"quotebook/internal/interfaces"
+	"quotebook/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// StartViewPurge удаляет почасовые просмотры, вышедшие из окон /quotes/trending
+func StartViewPurge(ctx context.Context, lg *logger.Logger, cfg *config.Config, views interfaces.IViewService) {
+	interval := time.Duration(cfg.Views.PurgeInterval)
+	if interval <= 0 {
+		lg.Info(ctx, "view purge disabled")
+		return
+	}
+
+	runEvery(ctx, interval, func(ctx context.Context) {
+		purged, err := views.PurgeExpired(ctx)
+		if err != nil {
+			lg.Error(ctx, "view purge failed", zap.Error(err))
+			return
+		}
+		if purged > 0 {
+			lg.Info(ctx, "hourly views purged", zap.Int64("purged", purged))
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package langdetect
+
+// образцы текста, из которых при старте строятся профили триграмм.
+// Тексты нейтральные и нарочно похожи на цитаты: короткие фразы о жизни,
+// времени и людях, с частыми служебными словами языка.
+var corpus = map[string]string{
+	"en": `The only thing we have to fear is that we will stop trying. Life is what happens
+while you are busy making other plans, and the time you enjoy wasting is not wasted time.
+A friend is someone who knows all about you and still loves you. Be yourself; everyone
+else is already taken. In the end, it is not the years in your life that count, it is the
+life in your years. The best way to predict the future is to create it. Whatever you are,
+be a good one. It does not matter how slowly you go as long as you do not stop. We are
+what we repeatedly do; excellence, then, is not an act but a habit. Nothing in the world
+is more dangerous than sincere ignorance and conscientious stupidity. Those who cannot
+remember the past are condemned to repeat it. The journey of a thousand miles begins with
+one step. If you want to go fast, go alone; if you want to go far, go together. There is
+no way to happiness, happiness is the way. Knowledge speaks, but wisdom listens. What we
+think, we become. The mind is everything, and the truth will set you free. When one door
+of happiness closes, another opens, but often we look so long at the closed door that we
+do not see the one which has been opened for us. This is the beginning of something that
+they would have called wisdom, and which they thought could only be found through work.`,
+
+	"ru": `Жизнь — это то, что с тобой происходит, пока ты строишь другие планы. Счастье
+не в том, чтобы делать всегда, что хочешь, а в том, чтобы всегда хотеть того, что
+делаешь. Все счастливые семьи похожи друг на друга, каждая несчастливая семья несчастлива
+по-своему. Красота спасёт мир. Человек есть тайна, её надо разгадывать, и если будешь её
+разгадывать всю жизнь, то не говори, что потерял время. Берегите в себе человека. Ученье
+— свет, а неученье — тьма. Не бойся, что не знаешь: бойся, что не учишься. Кто хочет,
+тот ищет возможности, кто не хочет — ищет причины. Мы в ответе за тех, кого приручили.
+Лучше быть, чем казаться. Время — лучший учитель, но, к сожалению, оно убивает своих
+учеников. Никогда ничего не просите, особенно у тех, кто сильнее вас: сами предложат и
+сами всё дадут. Умный человек не тот, кто много знает, а тот, чьи знания полезны. Слово
+— тоже дело. Простота есть необходимое условие прекрасного. Если хочешь быть счастливым,
+будь им. Чем больше человек узнаёт, тем яснее видит, как мало он знает. Жить — значит
+работать, а труд есть жизнь человека. Где нет любви, там нет и правды.`,
+
+	"uk": `Життя — це те, що з тобою відбувається, поки ти будуєш інші плани. Борітеся —
+поборете, вам Бог помагає. Учітеся, брати мої, думайте, читайте, і чужому научайтесь, й
+свого не цурайтесь. Хто не знає свого минулого, той не вартий майбутнього. Щастя не в
+тому, щоб робити завжди що хочеш, а в тому, щоб завжди хотіти того, що робиш. Без надії
+сподіваюсь. Людина є таємниця, і її треба розгадувати все життя. Якщо хочеш бути
+щасливим, будь ним. Знання — це сила, а праця є життям людини. Де немає любові, там немає
+і правди. Чим більше людина дізнається, тим ясніше бачить, як мало вона знає. Слово —
+теж діло. Час — найкращий учитель, але, на жаль, він убиває своїх учнів. Ніколи нічого не
+просіть, особливо у тих, хто сильніший за вас. Розумна людина не та, що багато знає, а та,
+чиї знання корисні. Краще бути, ніж здаватися. Ми відповідаємо за тих, кого приручили.
+Кожен день є новою можливістю змінити своє життя. Їжте, пийте, веселіться, але пам'ятайте
+про свою ґрунтовну справу і про своїх дітей, бо вони наше майбутнє і наша єдина надія.`,
+
+	"de": `Das Leben ist das, was passiert, während du eifrig dabei bist, andere Pläne zu
+machen. Was mich nicht umbringt, macht mich stärker. Wer kämpft, kann verlieren, wer nicht
+kämpft, hat schon verloren. Man sieht nur mit dem Herzen gut, das Wesentliche ist für die
+Augen unsichtbar. Die Grenzen meiner Sprache bedeuten die Grenzen meiner Welt. Es ist
+nicht genug zu wissen, man muss auch anwenden; es ist nicht genug zu wollen, man muss auch
+tun. Phantasie ist wichtiger als Wissen, denn Wissen ist begrenzt. Der Weg ist das Ziel.
+Wer immer tut, was er schon kann, bleibt immer das, was er schon ist. Die Zeit heilt nicht
+alle Wunden, sie lehrt uns nur, mit dem Unbegreiflichen zu leben. Glück ist das Einzige,
+das sich verdoppelt, wenn man es teilt. Auch aus Steinen, die einem in den Weg gelegt
+werden, kann man Schönes bauen. Wer nicht an Wunder glaubt, ist kein Realist. Nichts ist
+so beständig wie der Wandel. Ein Freund ist ein Mensch, vor dem man laut denken kann. Das
+Schönste, was wir erleben können, ist das Geheimnisvolle. Die Welt gehört dem, der sie genießt.`,
+
+	"fr": `La vie, c'est ce qui arrive pendant que vous êtes occupé à faire d'autres projets.
+On ne voit bien qu'avec le cœur, l'essentiel est invisible pour les yeux. Je pense, donc je
+suis. Le cœur a ses raisons que la raison ne connaît point. Il n'y a qu'un bonheur dans la
+vie, c'est d'aimer et d'être aimé. La patience est amère, mais son fruit est doux. Le
+bonheur est parfois caché dans l'inconnu. Ce qui ne me tue pas me rend plus fort. Vivre
+sans aimer n'est pas proprement vivre. Il faut cultiver notre jardin. L'homme est
+condamné à être libre. Rien ne sert de courir, il faut partir à point. La liberté des
+uns s'arrête là où commence celle des autres. Le temps est un grand maître, dit-on, le
+malheur est qu'il tue ses élèves. Chacun est responsable de sa propre vie et de ce qu'il
+en fait. Les grandes personnes ne comprennent jamais rien toutes seules, et c'est fatigant,
+pour les enfants, de toujours leur donner des explications. Un sourire coûte moins cher
+que l'électricité, mais donne autant de lumière. Dans la vie, rien n'est à craindre, tout est à comprendre.`,
+
+	"es": `La vida es lo que te pasa mientras estás ocupado haciendo otros planes. Caminante,
+no hay camino, se hace camino al andar. El que lee mucho y anda mucho, ve mucho y sabe
+mucho. Solo sé que no sé nada. La felicidad no es hacer lo que uno quiere, sino querer lo
+que uno hace. No hay mal que por bien no venga. Donde una puerta se cierra, otra se abre.
+El tiempo es el mejor autor: siempre encuentra un final perfecto. Quien no ha tenido
+tribulaciones que pasar no tiene fuerzas que probar. Lo importante no es lo que nos hace
+el destino, sino lo que nosotros hacemos con él. La verdad adelgaza y no quiebra, y siempre
+anda sobre la mentira como el aceite sobre el agua. Dime con quién andas y te diré quién
+eres. Vivir es lo más raro del mundo; la mayoría de la gente existe, eso es todo. Los
+sueños, sueños son, y toda la vida es sueño. Es mejor ser rey de tu silencio que esclavo
+de tus palabras. Nunca es tarde para aprender algo nuevo. Cada día sabemos más y
+entendemos menos, pero seguimos buscando las respuestas en el corazón de las personas.`,
+
+	"it": `La vita è quello che ti succede mentre sei impegnato a fare altri progetti. Nel
+mezzo del cammin di nostra vita mi ritrovai per una selva oscura. Chi va piano va sano e
+va lontano. Fatti non foste a viver come bruti, ma per seguir virtute e canoscenza. La
+semplicità è la sofisticazione suprema. Il tempo è un grande maestro, ma purtroppo uccide
+tutti i suoi allievi. Chi non ama la solitudine, non ama la libertà. Non c'è amore più
+sincero di quello per il cibo. L'amor che move il sole e l'altre stelle. Tra il dire e il
+fare c'è di mezzo il mare. La felicità non è fare tutto ciò che si vuole, ma volere tutto
+ciò che si fa. Una giornata ben spesa dà lieto dormire, così una vita bene usata dà lieto
+morire. Chi trova un amico trova un tesoro. Se vogliamo che tutto rimanga come è, bisogna
+che tutto cambi. Ogni giorno è una nuova occasione per cambiare la propria vita. Non è mai
+troppo tardi per essere ciò che avresti potuto essere. La conoscenza è l'unica ricchezza
+che non si può rubare, e la bellezza delle cose esiste nella mente di chi le osserva.`,
+}
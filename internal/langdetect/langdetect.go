@@ -0,0 +1,174 @@
+// Package langdetect определяет язык короткого текста. Сначала по письменности
+// отсекаются кандидаты (кириллица, латиница, иероглифы и т. д.), затем среди
+// языков с одной письменностью выбирается наиболее вероятный наивным Байесом
+// по n-граммам символов длиной 1–3. Профили строятся при старте из corpus.go.
+package langdetect
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Undetermined код ISO 639-2 для текста, язык которого определить не удалось
+const Undetermined = "und"
+
+const (
+	maxN = 3
+	// меньше букв — не угадываем
+	minLetters = 3
+	// по n-граммам язык различим только на тексте хотя бы такой длины
+	minStatLetters = 10
+	// сглаживание Лапласа для n-грамм, которых не было в образце
+	alpha = 0.5
+)
+
+type profile struct {
+	counts [maxN + 1]map[string]float64
+	totals [maxN + 1]float64
+}
+
+var (
+	profiles = map[string]*profile{}
+	// языки, различаемые по n-граммам, по письменности
+	byScript = map[*unicode.RangeTable][]string{
+		unicode.Cyrillic: {"ru", "uk"},
+		unicode.Latin:    {"en", "de", "fr", "es", "it"},
+	}
+	// письменности, которых достаточно для ответа
+	singleScript = []struct {
+		table *unicode.RangeTable
+		lang  string
+	}{
+		{unicode.Hangul, "ko"},
+		{unicode.Hiragana, "ja"},
+		{unicode.Katakana, "ja"},
+		{unicode.Han, "zh"},
+		{unicode.Arabic, "ar"},
+		{unicode.Hebrew, "he"},
+		{unicode.Greek, "el"},
+	}
+)
+
+func init() {
+	for lang, text := range corpus {
+		p := &profile{}
+		for n := 1; n <= maxN; n++ {
+			p.counts[n] = map[string]float64{}
+		}
+		eachGram(normalize(text), func(n int, g string) {
+			p.counts[n][g]++
+			p.totals[n]++
+		})
+		profiles[lang] = p
+	}
+}
+
+// Detect код языка text по ISO 639-1 или Undetermined
+func Detect(text string) string {
+	letters := 0
+	scripts := map[*unicode.RangeTable]int{}
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range singleScript {
+			if unicode.Is(s.table, r) {
+				scripts[s.table]++
+			}
+		}
+		for table := range byScript {
+			if unicode.Is(table, r) {
+				scripts[table]++
+			}
+		}
+	}
+	if letters < minLetters {
+		return Undetermined
+	}
+
+	// японский текст пишется иероглифами вперемешку с каной, поэтому кана
+	// проверяется раньше иероглифов
+	if scripts[unicode.Hiragana]+scripts[unicode.Katakana] > 0 {
+		return "ja"
+	}
+	var dominant *unicode.RangeTable
+	for table, n := range scripts {
+		if dominant == nil || n > scripts[dominant] {
+			dominant = table
+		}
+	}
+	if dominant == nil || scripts[dominant]*2 < letters {
+		return Undetermined
+	}
+	for _, s := range singleScript {
+		if s.table == dominant {
+			return s.lang
+		}
+	}
+	if letters < minStatLetters {
+		return Undetermined
+	}
+	return best(normalize(text), byScript[dominant])
+}
+
+// best наиболее вероятный из candidates язык текста
+func best(text string, candidates []string) string {
+	scores := make(map[string]float64, len(candidates))
+	eachGram(text, func(n int, g string) {
+		for _, lang := range candidates {
+			p := profiles[lang]
+			vocab := float64(len(p.counts[n])) + 1
+			// длинные n-граммы различают языки лучше, им больший вес
+			scores[lang] += float64(n) * math.Log((p.counts[n][g]+alpha)/(p.totals[n]+alpha*vocab))
+		}
+	})
+
+	bestLang, bestScore := Undetermined, math.Inf(-1)
+	for _, lang := range candidates {
+		if s := scores[lang]; s > bestScore {
+			bestLang, bestScore = lang, s
+		}
+	}
+	return bestLang
+}
+
+// normalize нижний регистр, всё кроме букв и апострофа — пробел, пробелы схлопнуты
+func normalize(text string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || r == '\'' || r == '’' {
+			if r == '’' {
+				r = '\''
+			}
+			b.WriteRune(r)
+			space = false
+			continue
+		}
+		if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return " " + strings.TrimSpace(b.String()) + " "
+}
+
+// eachGram вызывает fn для всех n-грамм text длиной от 1 до maxN символов;
+// пробелы по краям слов входят в n-граммы и отмечают начало и конец слова
+func eachGram(text string, fn func(n int, g string)) {
+	runes := []rune(text)
+	for i := range runes {
+		for n := 1; n <= maxN && i+n <= len(runes); n++ {
+			if n == 1 && runes[i] == ' ' {
+				continue
+			}
+			if n == 3 && runes[i+1] == ' ' {
+				// n-грамма через границу слов
+				continue
+			}
+			fn(n, string(runes[i:i+n]))
+		}
+	}
+}
@@ -42,6 +42,12 @@ func GetLoggerFromCtx(ctx context.Context) *Logger {
 	return ctx.Value(LoggerKey).(*Logger)
 }
 
+// RequestIDFromCtx возвращает RequestID запроса или пустую строку
+func RequestIDFromCtx(ctx context.Context) string {
+	id, _ := ctx.Value(RequestID).(string)
+	return id
+}
+
 func (l *Logger) Info(ctx context.Context, msg string, fields ...zap.Field) {
 	if ctx.Value(RequestID) != nil {
 		fields = append(fields, zap.String(RequestID, ctx.Value(RequestID).(string)))
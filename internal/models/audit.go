@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// AuditEntry строка журнала аудита, дописывается и никогда не меняется.
+// Principal — имя из X-User, его клиент указывает сам; Authenticated
+// отмечает запросы, подтверждённые токеном admin.token.
+type AuditEntry struct {
+	ID            int64     `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	Principal     string    `json:"principal"`
+	Authenticated bool      `json:"authenticated"`
+	IP            string    `json:"ip"`
+	Method        string    `json:"method"`
+	Route         string    `json:"route"`
+	TargetID      string    `json:"target_id,omitempty"`
+	Status        int       `json:"status"`
+	Outcome       string    `json:"outcome"`
+	RequestID     string    `json:"request_id"`
+	PayloadHash   string    `json:"payload_hash,omitempty"`
+	PrevHash      string    `json:"prev_hash"`
+	Hash          string    `json:"hash"`
+}
+
+// AuditFilter пустые поля не участвуют в фильтрации
+type AuditFilter struct {
+	Principal string
+	Route     string
+	TargetID  string
+	Outcome   string
+	From      *time.Time
+	To        *time.Time
+	AfterID   int64
+	Limit     int
+}
+
+// AuditVerification результат проверки цепочки хешей
+type AuditVerification struct {
+	Checked  int   `json:"checked"`
+	Valid    bool  `json:"valid"`
+	BrokenAt int64 `json:"broken_at,omitempty"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "strconv"
+
+// AuthorSuggestion автор из подсказки: сколько у него живых цитат и, для
+// нечёткого поиска, похожесть на запрос от 0 до 1
+type AuthorSuggestion struct {
+	Author     string  `json:"author"`
+	Quotes     int     `json:"quotes"`
+	Similarity float64 `json:"similarity,omitempty"`
+}
+
+// Author автор и даты его жизни (YYYY-MM-DD, пустая — неизвестна).
+// Даты задаёт администратор, Quotes — число живых цитат.
+type Author struct {
+	Name   string `json:"name"`
+	Born   string `json:"born,omitempty"`
+	Died   string `json:"died,omitempty"`
+	Quotes int    `json:"quotes"`
+}
+
+// события GET /quotes/on-this-day
+const (
+	AnniversaryBorn = "born"
+	AnniversaryDied = "died"
+)
+
+// Anniversary автор цитаты родился или умер в этот день Years лет назад
+type Anniversary struct {
+	Event string `json:"event"`
+	Date  string `json:"date"`
+	Years int    `json:"years"`
+}
+
+// AnniversaryQuote цитата автора, у которого сегодня годовщина
+type AnniversaryQuote struct {
+	Anniversary
+	Quote Quote `json:"quote"`
+}
+
+// OnThisDay цитаты авторов, родившихся или умерших в день Date (YYYY-MM-DD)
+// пояса Timezone в другие годы
+type OnThisDay struct {
+	Date     string             `json:"date"`
+	Timezone string             `json:"timezone"`
+	Quotes   []AnniversaryQuote `json:"quotes"`
+}
+
+// note "born 1901-10-19, 125 years"
+func (a Anniversary) note() Note {
+	return Note{Name: "anniversary", Value: a.Event + " " + a.Date + ", " + strconv.Itoa(a.Years) + " years"}
+}
+
+func (a AnniversaryQuote) Entry() QuoteEntry {
+	return QuoteEntry{Quote: a.Quote, Notes: []Note{a.Anniversary.note()}}
+}
+
+func (o OnThisDay) Entries() ([]QuoteEntry, []Note) {
+	entries := make([]QuoteEntry, len(o.Quotes))
+	for i, q := range o.Quotes {
+		entries[i] = q.Entry()
+	}
+	return entries, []Note{{Name: "date", Value: o.Date}, {Name: "timezone", Value: o.Timezone}}
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// форматы карточки цитаты
+const (
+	CardPNG = "png"
+	CardSVG = "svg"
+)
+
+// CardOptions параметры GET /quotes/{id}/card.{png,svg}; пустые поля берутся из конфига.
+// Size — "1200x630" или пресет: og, square, story.
+type CardOptions struct {
+	Format string
+	Size   string
+	Theme  string
+	Font   string
+}
+
+// Card готовая картинка; ETag меняется вместе с версией цитаты и параметрами
+type Card struct {
+	ContentType  string
+	Body         []byte
+	ETag         string
+	LastModified time.Time
+}
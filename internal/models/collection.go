@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Collection подборка цитат пользователя
+type Collection struct {
+	ID          int    `json:"id"`
+	Owner       string `json:"owner"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Public      bool   `json:"public"`
+	// число цитат не из корзины
+	Size      int       `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// цитаты по порядку, только в GET /collections/{id}
+	Quotes []Quote `json:"quotes,omitempty"`
+}
+
+// CollectionPatch изменение подборки, nil-поля не трогаются
+type CollectionPatch struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Public      *bool   `json:"public"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "strconv"
+
+// как выбирается цитата дня GET /quotes/daily?mode=
+const (
+	// по кругу: цитата не повторяется, пока есть непоказанные
+	DailyRotation = "rotation"
+	// цитата автора, у которого сегодня годовщина; если таких нет — по кругу
+	DailyOnThisDay = "on-this-day"
+)
+
+// DailyQuote цитата дня. Date — календарный день (YYYY-MM-DD) в поясе Timezone;
+// Pinned — цитату на этот день назначил администратор. Mode — каким способом
+// выбрана цитата, для on-this-day Anniversary — чья годовщина.
+type DailyQuote struct {
+	Date        string       `json:"date"`
+	Timezone    string       `json:"timezone,omitempty"`
+	Pinned      bool         `json:"pinned"`
+	Mode        string       `json:"mode,omitempty"`
+	Anniversary *Anniversary `json:"anniversary,omitempty"`
+	Quote       Quote        `json:"quote"`
+}
+
+func (d DailyQuote) Entry() QuoteEntry {
+	notes := []Note{{Name: "date", Value: d.Date}}
+	if d.Timezone != "" {
+		notes = append(notes, Note{Name: "timezone", Value: d.Timezone})
+	}
+	notes = append(notes, Note{Name: "pinned", Value: strconv.FormatBool(d.Pinned)})
+	if d.Mode != "" {
+		notes = append(notes, Note{Name: "mode", Value: d.Mode})
+	}
+	if d.Anniversary != nil {
+		notes = append(notes, d.Anniversary.note())
+	}
+	return QuoteEntry{Quote: d.Quote, Notes: notes}
+}
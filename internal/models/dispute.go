@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// статусы авторства цитаты
+const (
+	AttributionVerified      = "verified"
+	AttributionDisputed      = "disputed"
+	AttributionMisattributed = "misattributed"
+	AttributionUnknown       = "unknown"
+)
+
+// состояния оспаривания
+const (
+	DisputeOpen     = "open"
+	DisputeAccepted = "accepted"
+	DisputeRejected = "rejected"
+)
+
+// Dispute оспаривание авторства цитаты
+type Dispute struct {
+	ID      int    `json:"id"`
+	QuoteID int    `json:"quote_id"`
+	FiledBy string `json:"filed_by"`
+	// статус авторства, который предлагает пользователь
+	Proposed   string     `json:"proposed"`
+	Reason     string     `json:"reason"`
+	Evidence   string     `json:"evidence,omitempty"`
+	Status     string     `json:"status"`
+	Reviewer   string     `json:"reviewer,omitempty"`
+	ReviewNote string     `json:"review_note,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// DisputeReview решение проверяющего: accept или reject. При accept цитата
+// получает Attribution, а если он пуст — предложенный статус.
+type DisputeReview struct {
+	Decision    string `json:"decision"`
+	Attribution string `json:"attribution"`
+	Note        string `json:"note"`
+}
+
+// AttributionUpdate статус авторства, выставленный проверяющим напрямую
+type AttributionUpdate struct {
+	Attribution string `json:"attribution"`
+	Note        string `json:"note"`
+}
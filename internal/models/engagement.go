@@ -0,0 +1,12 @@
+package models
+
+// Engagement счётчики цитаты и отношение к ней текущего пользователя
+type Engagement struct {
+	QuoteID     int     `json:"quote_id"`
+	Favorites   int     `json:"favorites"`
+	RatingAvg   float64 `json:"rating_avg"`
+	RatingCount int     `json:"rating_count"`
+	Favorited   bool    `json:"favorited"`
+	// оценка текущего пользователя, 0 — не оценивал
+	MyRating int `json:"my_rating"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Note подпись к цитате или ко всему ответу: то, что JSON отдаёт полями
+// обёртки, а текстовые форматы — строкой "name: value"
+type Note struct {
+	Name  string
+	Value string
+}
+
+// QuoteEntry цитата ответа и подписи к ней
+type QuoteEntry struct {
+	Quote Quote
+	Notes []Note
+}
+
+// QuoteEntrier реализуют цитата и обёртки над ней (DailyQuote,
+// TrendingQuote...): так форматы кроме JSON показывают их, не зная типа
+type QuoteEntrier interface {
+	Entry() QuoteEntry
+}
+
+// QuoteLister реализуют ответы-списки с подписями ко всему ответу
+// (QuoteBatch, OnThisDay)
+type QuoteLister interface {
+	Entries() ([]QuoteEntry, []Note)
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', 3, 64)
+}
+
+func formatIDs(ids []int) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// IdempotencyRecord сохранённый результат запроса с Idempotency-Key.
+// Пока Completed == false, первый запрос ещё выполняется.
+type IdempotencyRecord struct {
+	Principal   string
+	Key         string
+	Fingerprint string
+	Completed   bool
+	Status      int
+	ContentType string
+	ETag        string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// форматы импорта
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// что делать, если цитата с таким же текстом уже есть
+const (
+	DuplicateSkip   = "skip"
+	DuplicateFail   = "fail"
+	DuplicateUpdate = "update" // заменить автора у существующей цитаты
+)
+
+// статусы фонового импорта
+const (
+	ImportPending = "pending"
+	ImportRunning = "running"
+	ImportDone    = "done"
+	ImportFailed  = "failed"
+)
+
+type ImportOptions struct {
+	Format      string `json:"format"`
+	OnDuplicate string `json:"on_duplicate"`
+	DryRun      bool   `json:"dry_run"`
+}
+
+// ImportRow строка файла; Row — номер строки CSV/NDJSON или элемента JSON-массива
+type ImportRow struct {
+	Row    int
+	Author string
+	Quote  string
+	// Lang определяется сервисом по тексту
+	Lang string
+}
+
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportCounts то, что посчитала база
+type ImportCounts struct {
+	Inserted      int   `json:"inserted"`
+	Updated       int   `json:"updated"`
+	Duplicates    int   `json:"duplicates"`
+	DuplicateRows []int `json:"-"`
+}
+
+type ImportResult struct {
+	ImportCounts
+	Total   int              `json:"total"`
+	Invalid int              `json:"invalid"`
+	Skipped int              `json:"skipped"`
+	DryRun  bool             `json:"dry_run"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+type ImportJob struct {
+	ID         string        `json:"id"`
+	Status     string        `json:"status"`
+	Result     *ImportResult `json:"result,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	CreatedBy  string        `json:"created_by"`
+	CreatedAt  time.Time     `json:"created_at"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "encoding/json"
+
+// Optional поле PATCH, у которого null — значение, а не «не менять»:
+// Set — поле есть в теле, Value nil при Set — явный null
+type Optional[T any] struct {
+	Set   bool
+	Value *T
+}
+
+// Some заданное значение
+func Some[T any](v *T) Optional[T] {
+	return Optional[T]{Set: true, Value: v}
+}
+
+// UnmarshalJSON вызывается только для поля, которое есть в теле, в том числе для null
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	o.Set = true
+	o.Value = nil
+	if string(b) == "null" {
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	o.Value = &v
+	return nil
+}
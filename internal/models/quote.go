@@ -6,5 +6,101 @@ type Quote struct {
     ID        int    `json:"id,omitempty"`
     Author    string    `json:"author"`
     Quote      string    `json:"quote"`
+    Tags      []string  `json:"tags"`
+    // Lang язык текста (ISO 639-1, und — не определён); пустой при записи — определить по тексту
+    Lang      string    `json:"lang,omitempty"`
+    // OriginalLang язык оригинала, если Quote — перевод, выбранный по Accept-Language
+    OriginalLang string `json:"original_lang,omitempty"`
+    // Weight вес для случайного выбора по стратегии weight; nil при записи — не менять
+    Weight    *float64  `json:"weight,omitempty"`
+    // Source откуда цитата; nil в PUT снимает источник
+    Source    *Source   `json:"source,omitempty"`
+    // статус авторства и пояснение проверяющего, меняются только через /admin
+    Attribution     string `json:"attribution,omitempty"`
+    AttributionNote string `json:"attribution_note,omitempty"`
+    // счётчики избранного и оценок, меняются только через /like и /rating
+    Favorites   int     `json:"favorites"`
+    RatingAvg   float64 `json:"rating_avg"`
+    RatingCount int     `json:"rating_count"`
     CreatedAt time.Time `json:"created_at,omitempty"`
-}
\ No newline at end of file
+    UpdatedAt time.Time `json:"updated_at,omitempty"`
+    DeletedAt *time.Time `json:"deleted_at,omitempty"`
+    Version   int       `json:"version,omitempty"`
+}
+
+// QuotePatch частичное изменение, nil-поля не трогаются. Source
+// меняется, только если он есть в теле: "source": null снимает источник.
+type QuotePatch struct {
+    Author *string `json:"author"`
+    Quote  *string `json:"quote"`
+    Tags   *[]string `json:"tags"`
+    Weight *float64 `json:"weight"`
+    Source Optional[Source] `json:"source"`
+    Lang   *string `json:"lang"`
+}
+
+
+// QuoteFilter фильтры списка цитат, пустые поля не применяются
+type QuoteFilter struct {
+    Author string
+    Tag    string
+    // скрыть цитаты со статусом misattributed
+    HideMisattributed bool
+    // язык оригинала или перевода
+    Lang string
+}
+
+
+// стратегии случайного выбора GET /quotes/random?strategy=
+const (
+    RandomUniform = "uniform"
+    RandomRating  = "rating"
+    RandomRecency = "recency"
+    RandomViews   = "views"
+    RandomWeight  = "weight"
+)
+
+// порядок GET /quotes?sort=
+const (
+    SortPopular = "popular"
+    SortRating  = "rating"
+    SortRecent  = "recent"
+)
+
+// QuoteWeight данные, из которых считается вес цитаты при случайном выборе
+type QuoteWeight struct {
+    ID          int
+    Weight      float64
+    RatingSum   int
+    RatingCount int
+    Views       int64
+    CreatedAt   time.Time
+}
+
+
+// QuotesStamp дешёвый отпечаток таблицы для условных GET
+type QuotesStamp struct {
+    Count        int
+    LastModified time.Time
+}
+
+
+// QuoteBatch ответ на выборку по списку id: порядок как в запросе,
+// ненайденные id перечислены в Missing
+type QuoteBatch struct {
+    Quotes  []Quote `json:"quotes"`
+    Missing []int   `json:"missing"`
+}
+
+func (q Quote) Entry() QuoteEntry {
+    return QuoteEntry{Quote: q}
+}
+
+// Entries ненайденные id — подпись missing
+func (b QuoteBatch) Entries() ([]QuoteEntry, []Note) {
+    entries := make([]QuoteEntry, len(b.Quotes))
+    for i, q := range b.Quotes {
+        entries[i] = q.Entry()
+    }
+    return entries, []Note{{Name: "missing", Value: formatIDs(b.Missing)}}
+}
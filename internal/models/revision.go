@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// действия, после которых сохраняется ревизия
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionRevert  = "revert"
+)
+
+// QuoteRevision неизменяемый снимок цитаты после очередного изменения.
+// В ревизиях, записанных до того, как история начала хранить источник, вес
+// и язык, Weight nil, а Lang пустой.
+type QuoteRevision struct {
+	QuoteID   int       `json:"quote_id"`
+	Rev       int       `json:"rev"`
+	Action    string    `json:"action"`
+	Author    string    `json:"author"`
+	Quote     string    `json:"quote"`
+	Tags      []string  `json:"tags"`
+	Source    *Source   `json:"source,omitempty"`
+	Weight    *float64  `json:"weight,omitempty"`
+	Lang      string    `json:"lang,omitempty"`
+	Actor     string    `json:"actor"`
+	RequestID string    `json:"request_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// ShuffleSession обход цитат одного клиента без повторов: Position — позиция
+// в перестановке рангов 0..Size-1, заданной Seed. Ранги — номера цитат
+// в снимке SnapshotID, снятом в начале круга. Когда перестановка пройдена,
+// начинается следующий круг с новым Seed и свежим снимком.
+type ShuffleSession struct {
+	Token      string
+	Seed       int64
+	Size       int
+	SnapshotID int64
+	Position   int
+	Round      int
+}
@@ -0,0 +1,12 @@
+package models
+
+// SimilarQuote цитата в GET /quotes/{id}/similar и её близость к исходной,
+// от 0 до 1
+type SimilarQuote struct {
+	Score float64 `json:"score"`
+	Quote Quote   `json:"quote"`
+}
+
+func (s SimilarQuote) Entry() QuoteEntry {
+	return QuoteEntry{Quote: s.Quote, Notes: []Note{{Name: "score", Value: formatScore(s.Score)}}}
+}
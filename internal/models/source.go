@@ -0,0 +1,40 @@
+package models
+
+// типы источников
+const (
+	SourceBook      = "book"
+	SourceSpeech    = "speech"
+	SourceInterview = "interview"
+	SourceArticle   = "article"
+	SourceOther     = "other"
+)
+
+// стили GET /quotes/{id}/citation?style=
+const (
+	CitationAPA     = "apa"
+	CitationMLA     = "mla"
+	CitationChicago = "chicago"
+)
+
+// Source откуда взята цитата
+type Source struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	// год издания или выступления, 0 — неизвестен
+	Year      int    `json:"year,omitempty"`
+	Publisher string `json:"publisher,omitempty"`
+	// страница или диапазон, например "12" или "12-14"
+	Page string `json:"page,omitempty"`
+	URL  string `json:"url,omitempty"`
+	// ISBN-10 или ISBN-13 без дефисов
+	ISBN string `json:"isbn,omitempty"`
+}
+
+// Citation ссылка на источник цитаты в заданном стиле
+type Citation struct {
+	QuoteID int    `json:"quote_id"`
+	Style   string `json:"style"`
+	// без разметки и с названием в <i> там, где стиль требует курсив
+	Text string `json:"text"`
+	HTML string `json:"html"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// шаг гистограммы создания цитат GET /stats?interval=
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// Stats сводка по живым цитатам
+type Stats struct {
+	Quotes  int `json:"quotes"`
+	Authors int `json:"authors"`
+	// средняя длина текста в символах
+	AvgLength  float64       `json:"avg_length"`
+	TopAuthors []StatCount   `json:"top_authors"`
+	Tags       []StatCount   `json:"tags"`
+	Languages  []StatCount   `json:"languages"`
+	Interval   string        `json:"interval"`
+	Created    []StatBucket  `json:"created"`
+	MostServed []ServedQuote `json:"most_served"`
+	// когда сводка посчитана; до stats.ttl отдаётся из памяти
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// StatCount число цитат у автора, тега или языка
+type StatCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// StatBucket сколько цитат создано за период, начинающийся в Start
+type StatBucket struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// ServedQuote цитата и сколько раз её отдал /quotes/random
+type ServedQuote struct {
+	ID     int    `json:"id"`
+	Author string `json:"author"`
+	Quote  string `json:"quote"`
+	Served int64  `json:"served"`
+}
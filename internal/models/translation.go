@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Translation перевод цитаты QuoteID на язык Lang
+type Translation struct {
+	QuoteID    int       `json:"quote_id"`
+	Lang       string    `json:"lang"`
+	Quote      string    `json:"quote"`
+	Translator string    `json:"translator,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
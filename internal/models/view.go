@@ -0,0 +1,36 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// окна GET /quotes/trending?window=
+const (
+	TrendingDay  = "24h"
+	TrendingWeek = "7d"
+)
+
+// ViewCount просмотры цитаты за час Hour, накопленные в памяти до записи в базу.
+// Served — сколько из них выдал /quotes/random.
+type ViewCount struct {
+	QuoteID int
+	Hour    time.Time
+	Views   int64
+	Served  int64
+}
+
+// TrendingQuote цитата в GET /quotes/trending: просмотры за окно и оценка,
+// в которой свежие просмотры весят больше старых
+type TrendingQuote struct {
+	Views int64   `json:"views"`
+	Score float64 `json:"score"`
+	Quote Quote   `json:"quote"`
+}
+
+func (t TrendingQuote) Entry() QuoteEntry {
+	return QuoteEntry{Quote: t.Quote, Notes: []Note{
+		{Name: "views", Value: strconv.FormatInt(t.Views, 10)},
+		{Name: "score", Value: formatScore(t.Score)},
+	}}
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"quotebook/config"
+	"quotebook/internal/audit"
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const auditColumns = `id, created_at, principal, authenticated, ip, method, route, target_id,
+	status, outcome, request_id, payload_hash, prev_hash, hash`
+
+type AuditRepository struct {
+	db  *pgxpool.Pool
+	cfg *config.Config
+}
+
+func NewAuditRepository(db *pgxpool.Pool, cfg *config.Config) AuditRepository {
+	return AuditRepository{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+func scanAudit(row pgx.Row, e *models.AuditEntry) error {
+	return row.Scan(&e.ID, &e.CreatedAt, &e.Principal, &e.Authenticated, &e.IP, &e.Method, &e.Route, &e.TargetID,
+		&e.Status, &e.Outcome, &e.RequestID, &e.PayloadHash, &e.PrevHash, &e.Hash)
+}
+
+// AppendAudit дописывает запись в конец цепочки. Писатели выстраиваются
+// в очередь на блокировке таблицы, читатели при этом не блокируются.
+func (ar AuditRepository) AppendAudit(ctx context.Context, e *models.AuditEntry) error {
+	tx, err := ar.db.Begin(ctx)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `LOCK TABLE audit_log IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to lock audit log: %v", err)
+	}
+
+	var lastID int64
+	var prevHash string
+	err = tx.QueryRow(ctx, `SELECT id, hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&lastID, &prevHash)
+	if err != nil && !errdefs.Is(err, pgx.ErrNoRows) {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to read audit chain head: %v", err)
+	}
+
+	e.ID = lastID + 1
+	// postgres хранит микросекунды, хеш должен совпасть после чтения
+	e.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	e.PrevHash = prevHash
+	e.Hash = audit.ChainHash(prevHash, e)
+
+	query := `
+		INSERT INTO audit_log (` + auditColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+	_, err = tx.Exec(ctx, query,
+		e.ID, e.CreatedAt, e.Principal, e.Authenticated, e.IP, e.Method, e.Route, e.TargetID,
+		e.Status, e.Outcome, e.RequestID, e.PayloadHash, e.PrevHash, e.Hash,
+	)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to append audit entry: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+// auditWhere собирает WHERE по заполненным полям фильтра
+func auditWhere(f *models.AuditFilter) (string, []any) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Principal != "" {
+		add("principal = $%d", f.Principal)
+	}
+	if f.Route != "" {
+		add("route = $%d", f.Route)
+	}
+	if f.TargetID != "" {
+		add("target_id = $%d", f.TargetID)
+	}
+	if f.Outcome != "" {
+		add("outcome = $%d", f.Outcome)
+	}
+	if f.From != nil {
+		add("created_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("created_at < $%d", *f.To)
+	}
+	if f.AfterID > 0 {
+		add("id > $%d", f.AfterID)
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (ar AuditRepository) AuditEntries(ctx context.Context, f *models.AuditFilter) (*[]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+	err := ar.StreamAudit(ctx, f, func(e *models.AuditEntry) error {
+		entries = append(entries, *e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entries, nil
+}
+
+// StreamAudit отдаёт записи по одной в порядке цепочки, не держа всю выборку в памяти
+func (ar AuditRepository) StreamAudit(ctx context.Context, f *models.AuditFilter, fn func(e *models.AuditEntry) error) error {
+	where, args := auditWhere(f)
+	query := `SELECT ` + auditColumns + ` FROM audit_log ` + where + ` ORDER BY id`
+	if f.Limit > 0 {
+		args = append(args, f.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := ar.db.Query(ctx, query, args...)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to query audit log: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := scanAudit(rows, &e); err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to scan audit entry: %v", err)
+		}
+		if err := fn(&e); err != nil {
+			return err
+		}
+	}
+
+	if rows.Err() != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "rows iteration error: %v", rows.Err())
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"quotebook/internal/audit"
+	"quotebook/internal/models"
+)
+
+func clearAudit(t *testing.T) {
+	ctx := context.Background()
+	_, err := db.Exec(ctx, fmt.Sprintf("TRUNCATE TABLE %s.audit_log", cfg.DB.Schema))
+	require.NoError(t, err, "Failed to clear audit_log table")
+}
+
+func TestAuditRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewAuditRepository(db, cfg)
+
+	t.Run("AppendChainAndFilter", func(t *testing.T) {
+		clearAudit(t)
+
+		for _, principal := range []string{"alice", "bob", "alice"} {
+			err := repo.AppendAudit(ctx, &models.AuditEntry{
+				Principal:     principal,
+				Authenticated: principal == "bob",
+				IP:            "127.0.0.1",
+				Method:        "POST",
+				Route:         "/quotes",
+				Status:        201,
+				Outcome:       models.OutcomeSuccess,
+				RequestID:     "req",
+			})
+			require.NoError(t, err)
+		}
+
+		all, err := repo.AuditEntries(ctx, &models.AuditFilter{})
+		require.NoError(t, err)
+		require.Len(t, *all, 3)
+		require.True(t, (*all)[1].Authenticated)
+
+		// хеши, пересчитанные после чтения, совпадают с сохранёнными
+		prev := ""
+		for _, e := range *all {
+			require.Equal(t, prev, e.PrevHash)
+			require.Equal(t, audit.ChainHash(prev, &e), e.Hash)
+			prev = e.Hash
+		}
+
+		byAlice, err := repo.AuditEntries(ctx, &models.AuditFilter{Principal: "alice"})
+		require.NoError(t, err)
+		require.Len(t, *byAlice, 2)
+
+		page, err := repo.AuditEntries(ctx, &models.AuditFilter{AfterID: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, *page, 1)
+		require.Equal(t, int64(2), (*page)[0].ID)
+	})
+
+	t.Run("AppendOnly", func(t *testing.T) {
+		clearAudit(t)
+
+		require.NoError(t, repo.AppendAudit(ctx, &models.AuditEntry{Principal: "alice", Outcome: models.OutcomeSuccess}))
+
+		_, err := db.Exec(ctx, "UPDATE audit_log SET principal = 'mallory'")
+		require.NoError(t, err)
+		_, err = db.Exec(ctx, "DELETE FROM audit_log")
+		require.NoError(t, err)
+
+		all, err := repo.AuditEntries(ctx, &models.AuditFilter{})
+		require.NoError(t, err)
+		require.Len(t, *all, 1)
+		require.Equal(t, "alice", (*all)[0].Principal)
+	})
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// likeEscaper экранирует спецсимволы LIKE, чтобы "_" в запросе не был шаблоном
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SuggestAuthors авторы, у которых имя или одно из слов имени начинается с prefix
+// (без учёта регистра). Совпадения с начала имени идут первыми, дальше — по
+// числу цитат.
+func (qr QuoteRepository) SuggestAuthors(ctx context.Context, prefix string, limit int) (*[]models.AuthorSuggestion, error) {
+	query := `
+		SELECT author, COUNT(*)
+		FROM quotesbook
+		WHERE deleted_at IS NULL AND (lower(author) LIKE $1 OR lower(author) LIKE $2)
+		GROUP BY author
+		ORDER BY lower(author) LIKE $1 DESC, COUNT(*) DESC, author
+		LIMIT $3
+	`
+
+	p := likeEscaper.Replace(strings.ToLower(prefix))
+	rows, err := qr.db.Query(ctx, query, p+"%", "% "+p+"%", limit)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to suggest authors: %v", err)
+	}
+	defer rows.Close()
+
+	authors := []models.AuthorSuggestion{}
+	for rows.Next() {
+		var a models.AuthorSuggestion
+		if err := rows.Scan(&a.Author, &a.Quotes); err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan author: %v", err)
+		}
+		authors = append(authors, a)
+	}
+	if rows.Err() != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "rows iteration error: %v", rows.Err())
+	}
+	return &authors, nil
+}
+
+// withSimilarity выполняет fn в транзакции, где оператор % pg_trgm отсекает
+// всё, что менее похоже, чем threshold; так поиск идёт по триграммному индексу
+func (qr QuoteRepository) withSimilarity(ctx context.Context, threshold float64, fn func(tx pgx.Tx) error) error {
+	return qr.withTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `SELECT set_config('pg_trgm.similarity_threshold', $1, true)`,
+			strconv.FormatFloat(threshold, 'f', -1, 64))
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to set similarity threshold: %v", err)
+		}
+		return fn(tx)
+	})
+}
+
+// SimilarAuthors авторы, похожие на name не меньше чем на threshold, самые похожие первыми
+func (qr QuoteRepository) SimilarAuthors(ctx context.Context, name string, threshold float64, limit int) (*[]models.AuthorSuggestion, error) {
+	query := `
+		SELECT author, COUNT(*), similarity(lower(author), lower($1))::float8 AS sim
+		FROM quotesbook
+		WHERE deleted_at IS NULL AND lower(author) % lower($1)
+		GROUP BY author
+		ORDER BY sim DESC, COUNT(*) DESC, author
+		LIMIT $2
+	`
+
+	authors := []models.AuthorSuggestion{}
+	err := qr.withSimilarity(ctx, threshold, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, name, limit)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to query similar authors: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var a models.AuthorSuggestion
+			if err := rows.Scan(&a.Author, &a.Quotes, &a.Similarity); err != nil {
+				return errdefs.Wrapf(errdefs.ErrDB, "failed to scan author: %v", err)
+			}
+			authors = append(authors, a)
+		}
+		if rows.Err() != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "rows iteration error: %v", rows.Err())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &authors, nil
+}
+
+// FuzzyQuotesByAuthor цитаты авторов, похожих на author не меньше чем на threshold:
+// сначала самого похожего, внутри автора — по id
+func (qr QuoteRepository) FuzzyQuotesByAuthor(ctx context.Context, author string, threshold float64) (*[]models.Quote, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotesbook
+		WHERE deleted_at IS NULL AND lower(author) % lower($1)
+		ORDER BY similarity(lower(author), lower($1)) DESC, author, id
+	`
+
+	var quotes *[]models.Quote
+	err := qr.withSimilarity(ctx, threshold, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, author)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to query quotes by similar author: %v", err)
+		}
+		quotes, err = collectQuotes(rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return quotes, nil
+}
+
+// formatDate дата YYYY-MM-DD, пустая строка для NULL
+func formatDate(d *time.Time) string {
+	if d == nil {
+		return ""
+	}
+	return d.Format(time.DateOnly)
+}
+
+// GetAuthor автор name (без учёта регистра) с датами жизни и числом живых
+// цитат. ErrNotFound, если нет ни цитат, ни записи с датами.
+func (qr QuoteRepository) GetAuthor(ctx context.Context, name string) (*models.Author, error) {
+	query := `
+		SELECT COALESCE(a.name, q.name), a.born, a.died, q.quotes
+		FROM (
+			SELECT mode() WITHIN GROUP (ORDER BY author) AS name, COUNT(*) AS quotes
+			FROM quotesbook
+			WHERE deleted_at IS NULL AND lower(author) = lower($1)
+		) q
+		LEFT JOIN authors a ON a.key = lower($1)
+	`
+
+	var (
+		author     models.Author
+		display    *string
+		born, died *time.Time
+	)
+	err := qr.db.QueryRow(ctx, query, name).Scan(&display, &born, &died, &author.Quotes)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to get author: %v", err)
+	}
+	if display == nil {
+		return nil, errdefs.ErrNotFound
+	}
+	author.Name, author.Born, author.Died = *display, formatDate(born), formatDate(died)
+	return &author, nil
+}
+
+// PutAuthor задаёт даты жизни автора a.Name (пустая дата — неизвестна).
+// Имя в записи — самое частое написание среди его цитат; автора без живых
+// цитат нет, ErrNotFound.
+func (qr QuoteRepository) PutAuthor(ctx context.Context, a *models.Author) (*models.Author, error) {
+	query := `
+		INSERT INTO authors (key, name, born, died)
+		SELECT lower($1), mode() WITHIN GROUP (ORDER BY author), NULLIF($2, '')::date, NULLIF($3, '')::date
+		FROM quotesbook
+		WHERE deleted_at IS NULL AND lower(author) = lower($1)
+		HAVING COUNT(*) > 0
+		ON CONFLICT (key) DO UPDATE
+		SET name = EXCLUDED.name, born = EXCLUDED.born, died = EXCLUDED.died, updated_at = now()
+	`
+
+	tag, err := qr.db.Exec(ctx, query, a.Name, a.Born, a.Died)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to save author: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, errdefs.ErrNotFound
+	}
+	return qr.GetAuthor(ctx, a.Name)
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+
+	"quotebook/config"
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// колонки подборки с числом живых цитат, читаются через scanCollection
+const collectionColumns = `c.id, c.owner, c.name, c.description, c.public, c.created_at, c.updated_at,
+	(SELECT COUNT(*) FROM collection_items ci JOIN quotesbook q ON q.id = ci.quote_id
+	 WHERE ci.collection_id = c.id AND q.deleted_at IS NULL)`
+
+// collectionItems живые цитаты подборки $1 по порядку
+const collectionItems = `
+	FROM quotesbook JOIN collection_items ci ON ci.quote_id = quotesbook.id
+	WHERE ci.collection_id = $1 AND deleted_at IS NULL`
+
+type CollectionRepository struct {
+	db  *pgxpool.Pool
+	cfg *config.Config
+}
+
+func NewCollectionRepository(db *pgxpool.Pool, cfg *config.Config) CollectionRepository {
+	return CollectionRepository{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+func scanCollection(row pgx.Row, c *models.Collection) error {
+	return row.Scan(&c.ID, &c.Owner, &c.Name, &c.Description, &c.Public, &c.CreatedAt, &c.UpdatedAt, &c.Size)
+}
+
+// withCollection выполняет fn в транзакции, заблокировав подборку id:
+// изменения состава одной подборки идут по очереди, порядок не рвётся
+func (cr CollectionRepository) withCollection(ctx context.Context, id int, fn func(tx pgx.Tx) error) error {
+	tx, err := cr.db.Begin(ctx)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE collections SET updated_at = now() WHERE id = $1`, id)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to lock collection %d: %v", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errdefs.ErrNotFound
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+// CreateCollection создаёт подборку, если у владельца их меньше collections.maxPerUser;
+// ID и время пишутся в c
+func (cr CollectionRepository) CreateCollection(ctx context.Context, c *models.Collection) error {
+	tx, err := cr.db.Begin(ctx)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// считать и вставлять под одной блокировкой владельца, иначе лимит обходится параллельными запросами
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('quotebook.collections.' || $1::text))`, c.Owner); err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to lock owner: %v", err)
+	}
+	if limit := cr.cfg.Collections.MaxPerUser; limit > 0 {
+		var count int
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM collections WHERE owner = $1`, c.Owner).Scan(&count); err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to count collections: %v", err)
+		}
+		if count >= limit {
+			return errdefs.Wrapf(errdefs.ErrConflict, "at most %d collections per user", limit)
+		}
+	}
+
+	query := `
+		INSERT INTO collections (owner, name, description, public)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at`
+	err = tx.QueryRow(ctx, query, c.Owner, c.Name, c.Description, c.Public).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to create collection: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+func (cr CollectionRepository) GetCollection(ctx context.Context, id int) (*models.Collection, error) {
+	query := `SELECT ` + collectionColumns + ` FROM collections c WHERE c.id = $1`
+
+	var c models.Collection
+	err := scanCollection(cr.db.QueryRow(ctx, query, id), &c)
+	if errdefs.Is(err, pgx.ErrNoRows) {
+		return nil, errdefs.ErrNotFound
+	}
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to get collection %d: %v", id, err)
+	}
+	return &c, nil
+}
+
+// ListCollections подборки, которые видит viewer: публичные и свои.
+// owner непустой — только подборки этого пользователя.
+func (cr CollectionRepository) ListCollections(ctx context.Context, viewer, owner string) (*[]models.Collection, error) {
+	query := `
+		SELECT ` + collectionColumns + `
+		FROM collections c
+		WHERE (c.public OR c.owner = $1) AND ($2::text = '' OR c.owner = $2::text)
+		ORDER BY c.updated_at DESC, c.id DESC`
+
+	rows, err := cr.db.Query(ctx, query, viewer, owner)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to list collections: %v", err)
+	}
+	defer rows.Close()
+
+	collections := []models.Collection{}
+	for rows.Next() {
+		var c models.Collection
+		if err := scanCollection(rows, &c); err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan collection: %v", err)
+		}
+		collections = append(collections, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to iterate collections: %v", err)
+	}
+	return &collections, nil
+}
+
+// UpdateCollection меняет заданные поля подборки
+func (cr CollectionRepository) UpdateCollection(ctx context.Context, id int, p *models.CollectionPatch) (*models.Collection, error) {
+	query := `
+		UPDATE collections
+		SET name = COALESCE($2, name), description = COALESCE($3, description),
+			public = COALESCE($4, public), updated_at = now()
+		WHERE id = $1`
+
+	tag, err := cr.db.Exec(ctx, query, id, p.Name, p.Description, p.Public)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to update collection %d: %v", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, errdefs.ErrNotFound
+	}
+	return cr.GetCollection(ctx, id)
+}
+
+// DeleteCollection удаляет подборку вместе с составом; сами цитаты не трогаются
+func (cr CollectionRepository) DeleteCollection(ctx context.Context, id int) error {
+	tag, err := cr.db.Exec(ctx, `DELETE FROM collections WHERE id = $1`, id)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to delete collection %d: %v", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errdefs.ErrNotFound
+	}
+	return nil
+}
+
+// CollectionQuotes живые цитаты подборки по порядку
+func (cr CollectionRepository) CollectionQuotes(ctx context.Context, id int) (*[]models.Quote, error) {
+	query := `SELECT ` + quoteColumns + collectionItems + ` ORDER BY ci.position`
+
+	rows, err := cr.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to list quotes of collection %d: %v", id, err)
+	}
+	return collectQuotes(rows)
+}
+
+// RandCollectionQuote случайная живая цитата подборки
+func (cr CollectionRepository) RandCollectionQuote(ctx context.Context, id int) (*models.Quote, error) {
+	query := `SELECT ` + quoteColumns + collectionItems + ` ORDER BY random() LIMIT 1`
+
+	var q models.Quote
+	err := scanQuote(cr.db.QueryRow(ctx, query, id), &q)
+	if errdefs.Is(err, pgx.ErrNoRows) {
+		return nil, errdefs.ErrNotFound
+	}
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to get random quote of collection %d: %v", id, err)
+	}
+	return &q, nil
+}
+
+// AddToCollection вставляет цитату на позицию position (с 1), 0 или позиция
+// за концом — в конец. Цитата уже в подборке — ErrConflict.
+func (cr CollectionRepository) AddToCollection(ctx context.Context, id, quoteID, position int) error {
+	return cr.withCollection(ctx, id, func(tx pgx.Tx) error {
+		var alive, member bool
+		var size int
+		err := tx.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM quotesbook WHERE id = $2 AND deleted_at IS NULL),
+				EXISTS (SELECT 1 FROM collection_items WHERE collection_id = $1 AND quote_id = $2),
+				(SELECT COUNT(*) FROM collection_items WHERE collection_id = $1)`, id, quoteID).
+			Scan(&alive, &member, &size)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to check collection %d: %v", id, err)
+		}
+		if !alive {
+			return errdefs.Wrapf(errdefs.ErrNotFound, "quote %d", quoteID)
+		}
+		if member {
+			return errdefs.Wrapf(errdefs.ErrConflict, "quote %d is already in collection %d", quoteID, id)
+		}
+		if limit := cr.cfg.Collections.MaxSize; limit > 0 && size >= limit {
+			return errdefs.Wrapf(errdefs.ErrConflict, "collection %d is full (%d quotes)", id, limit)
+		}
+
+		if position <= 0 || position > size {
+			position = size + 1
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE collection_items SET position = position + 1
+			WHERE collection_id = $1 AND position >= $2`, id, position)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to shift collection %d: %v", id, err)
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO collection_items (collection_id, quote_id, position)
+			VALUES ($1, $2, $3)`, id, quoteID, position)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to add quote %d to collection %d: %v", quoteID, id, err)
+		}
+		return nil
+	})
+}
+
+// RemoveFromCollection убирает цитату из подборки, следующие сдвигаются на её место
+func (cr CollectionRepository) RemoveFromCollection(ctx context.Context, id, quoteID int) error {
+	return cr.withCollection(ctx, id, func(tx pgx.Tx) error {
+		var position int
+		err := tx.QueryRow(ctx, `
+			DELETE FROM collection_items WHERE collection_id = $1 AND quote_id = $2
+			RETURNING position`, id, quoteID).Scan(&position)
+		if errdefs.Is(err, pgx.ErrNoRows) {
+			return errdefs.Wrapf(errdefs.ErrNotFound, "quote %d is not in collection %d", quoteID, id)
+		}
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to remove quote %d from collection %d: %v", quoteID, id, err)
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE collection_items SET position = position - 1
+			WHERE collection_id = $1 AND position > $2`, id, position)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to shift collection %d: %v", id, err)
+		}
+		return nil
+	})
+}
+
+// ReorderCollection задаёт новый порядок: quoteIDs — все живые цитаты подборки
+// ровно по разу. Цитаты из корзины встают в конец в прежнем порядке.
+// Состав, изменившийся с момента чтения, — ErrConflict.
+func (cr CollectionRepository) ReorderCollection(ctx context.Context, id int, quoteIDs []int) error {
+	return cr.withCollection(ctx, id, func(tx pgx.Tx) error {
+		var mismatch bool
+		err := tx.QueryRow(ctx, `
+			SELECT EXISTS (
+				(SELECT ci.quote_id FROM collection_items ci JOIN quotesbook q ON q.id = ci.quote_id
+				 WHERE ci.collection_id = $1 AND q.deleted_at IS NULL)
+				EXCEPT SELECT unnest($2::int[])
+			) OR EXISTS (
+				SELECT unnest($2::int[])
+				EXCEPT (SELECT ci.quote_id FROM collection_items ci JOIN quotesbook q ON q.id = ci.quote_id
+				 WHERE ci.collection_id = $1 AND q.deleted_at IS NULL)
+			)`, id, quoteIDs).Scan(&mismatch)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to check collection %d: %v", id, err)
+		}
+		if mismatch {
+			return errdefs.Wrapf(errdefs.ErrConflict, "order must list every quote of collection %d exactly once", id)
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE collection_items ci SET position = o.pos
+			FROM (
+				SELECT i.quote_id, row_number() OVER (ORDER BY t.ord NULLS LAST, i.position) AS pos
+				FROM collection_items i
+				LEFT JOIN unnest($2::int[]) WITH ORDINALITY t(quote_id, ord) ON t.quote_id = i.quote_id
+				WHERE i.collection_id = $1
+			) o
+			WHERE ci.collection_id = $1 AND ci.quote_id = o.quote_id`, id, quoteIDs)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to reorder collection %d: %v", id, err)
+		}
+		return nil
+	})
+}
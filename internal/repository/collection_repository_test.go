@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+)
+
+func TestCollectionRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCollectionRepository(db, cfg)
+	quotes := NewQuoteRepository(db, cfg)
+
+	ids := func(qs *[]models.Quote) []int {
+		out := []int{}
+		for _, q := range *qs {
+			out = append(out, q.ID)
+		}
+		return out
+	}
+
+	t.Run("MembershipAndOrder", func(t *testing.T) {
+		clearTable(t)
+		var qids []int
+		for i := 0; i < 3; i++ {
+			id, err := quotes.CreateQuote(ctx, &models.Quote{Author: "A", Quote: fmt.Sprintf("q%d", i)})
+			require.NoError(t, err)
+			qids = append(qids, id)
+		}
+
+		c := &models.Collection{Owner: "alice", Name: "Monday motivation"}
+		require.NoError(t, repo.CreateCollection(ctx, c))
+		require.NotZero(t, c.ID)
+
+		require.NoError(t, repo.AddToCollection(ctx, c.ID, qids[0], 0))
+		require.NoError(t, repo.AddToCollection(ctx, c.ID, qids[1], 0))
+		// на первое место
+		require.NoError(t, repo.AddToCollection(ctx, c.ID, qids[2], 1))
+		require.ErrorIs(t, repo.AddToCollection(ctx, c.ID, qids[2], 0), errdefs.ErrConflict)
+
+		got, err := repo.CollectionQuotes(ctx, c.ID)
+		require.NoError(t, err)
+		require.Equal(t, []int{qids[2], qids[0], qids[1]}, ids(got))
+
+		require.NoError(t, repo.ReorderCollection(ctx, c.ID, []int{qids[1], qids[0], qids[2]}))
+		got, err = repo.CollectionQuotes(ctx, c.ID)
+		require.NoError(t, err)
+		require.Equal(t, []int{qids[1], qids[0], qids[2]}, ids(got))
+
+		// неполный порядок не принимается
+		require.ErrorIs(t, repo.ReorderCollection(ctx, c.ID, []int{qids[0]}), errdefs.ErrConflict)
+
+		require.NoError(t, repo.RemoveFromCollection(ctx, c.ID, qids[0]))
+		require.ErrorIs(t, repo.RemoveFromCollection(ctx, c.ID, qids[0]), errdefs.ErrNotFound)
+		require.NoError(t, repo.AddToCollection(ctx, c.ID, qids[0], 2))
+		got, err = repo.CollectionQuotes(ctx, c.ID)
+		require.NoError(t, err)
+		require.Equal(t, []int{qids[1], qids[0], qids[2]}, ids(got))
+
+		// цитата в корзине пропадает из подборки, но не из состава
+		require.NoError(t, quotes.DeleteQuote(ctx, qids[1], 0))
+		meta, err := repo.GetCollection(ctx, c.ID)
+		require.NoError(t, err)
+		require.Equal(t, 2, meta.Size)
+		require.NoError(t, repo.ReorderCollection(ctx, c.ID, []int{qids[2], qids[0]}))
+		require.NoError(t, quotes.RestoreQuote(ctx, qids[1]))
+		got, err = repo.CollectionQuotes(ctx, c.ID)
+		require.NoError(t, err)
+		require.Equal(t, []int{qids[2], qids[0], qids[1]}, ids(got))
+
+		q, err := repo.RandCollectionQuote(ctx, c.ID)
+		require.NoError(t, err)
+		require.Contains(t, qids, q.ID)
+
+		require.NoError(t, repo.DeleteCollection(ctx, c.ID))
+		_, err = repo.GetCollection(ctx, c.ID)
+		require.ErrorIs(t, err, errdefs.ErrNotFound)
+	})
+
+	t.Run("Visibility", func(t *testing.T) {
+		_, err := db.Exec(ctx, fmt.Sprintf(`DELETE FROM %s.collections WHERE owner = 'carol'`, cfg.DB.Schema))
+		require.NoError(t, err)
+
+		private := &models.Collection{Owner: "carol", Name: "mine"}
+		require.NoError(t, repo.CreateCollection(ctx, private))
+		public := &models.Collection{Owner: "carol", Name: "shared", Public: true}
+		require.NoError(t, repo.CreateCollection(ctx, public))
+
+		list, err := repo.ListCollections(ctx, "dave", "carol")
+		require.NoError(t, err)
+		require.Len(t, *list, 1)
+		require.Equal(t, public.ID, (*list)[0].ID)
+
+		list, err = repo.ListCollections(ctx, "carol", "carol")
+		require.NoError(t, err)
+		require.Len(t, *list, 2)
+
+		name := "renamed"
+		updated, err := repo.UpdateCollection(ctx, private.ID, &models.CollectionPatch{Name: &name})
+		require.NoError(t, err)
+		require.Equal(t, "renamed", updated.Name)
+		require.False(t, updated.Public)
+	})
+}
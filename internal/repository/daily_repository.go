@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"quotebook/config"
+	"quotebook/internal/errdefs"
+	"quotebook/internal/identity"
+	"quotebook/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// выбор цитаты дня сериализуется между репликами, иначе два соседних дня,
+// выбранные одновременно, могли бы получить одну и ту же цитату
+const dailyLock = `SELECT pg_advisory_xact_lock(hashtext('quotebook.daily_quotes'))`
+
+// колонки из daily_quotes d JOIN quotesbook, читаются через scanDaily
+const (
+	dailyColumns = `d.day, d.pinned, d.mode, d.anniversary_event, d.anniversary_date, ` + quoteColumns
+	dailyFrom    = ` FROM daily_quotes d JOIN quotesbook ON quotesbook.id = d.quote_id`
+	dailySelect  = `SELECT ` + dailyColumns + dailyFrom
+)
+
+type DailyRepository struct {
+	db  *pgxpool.Pool
+	cfg *config.Config
+}
+
+func NewDailyRepository(db *pgxpool.Pool, cfg *config.Config) DailyRepository {
+	return DailyRepository{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+func scanDaily(row pgx.Row, dq *models.DailyQuote) error {
+	var (
+		day       time.Time
+		event     *string
+		eventDate *time.Time
+	)
+	fields := append([]any{&day, &dq.Pinned, &dq.Mode, &event, &eventDate}, quoteFields(&dq.Quote)...)
+	if err := row.Scan(fields...); err != nil {
+		return err
+	}
+	dq.Date = day.Format(time.DateOnly)
+	if event != nil && eventDate != nil {
+		dq.Anniversary = &models.Anniversary{
+			Event: *event,
+			Date:  eventDate.Format(time.DateOnly),
+			Years: day.Year() - eventDate.Year(),
+		}
+	}
+	return nil
+}
+
+// DailyQuote цитата режима rotation на календарный день day (берётся дата
+// в поясе day). Если день ещё не выбран или его цитату удалили, цитата выбирается сейчас:
+// среди не показанных в текущем круге, в порядке md5(день, id), так что
+// результат не зависит от того, какая реплика выбирала.
+func (dr DailyRepository) DailyQuote(ctx context.Context, day time.Time) (*models.DailyQuote, error) {
+	date := day.Format(time.DateOnly)
+	query := dailySelect + ` WHERE d.day = $1::date AND d.mode = $2 AND quotesbook.deleted_at IS NULL`
+
+	var dq models.DailyQuote
+	err := scanDaily(dr.db.QueryRow(ctx, query, date, models.DailyRotation), &dq)
+	if err == nil {
+		return &dq, nil
+	}
+	if !errdefs.Is(err, pgx.ErrNoRows) {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to get daily quote for %s: %v", date, err)
+	}
+
+	tx, err := dr.db.Begin(ctx)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, dailyLock); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to lock daily quotes: %v", err)
+	}
+
+	// пока ждали блокировку, день могла выбрать другая реплика
+	err = scanDaily(tx.QueryRow(ctx, query, date, models.DailyRotation), &dq)
+	if err == nil {
+		return &dq, nil
+	}
+	if !errdefs.Is(err, pgx.ErrNoRows) {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to get daily quote for %s: %v", date, err)
+	}
+
+	id, cycle, err := pickDailyQuote(ctx, tx, date)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO daily_quotes (day, quote_id, cycle, mode)
+		VALUES ($1::date, $2, $3, $4)
+		ON CONFLICT (day, mode) DO UPDATE
+		SET quote_id = EXCLUDED.quote_id, cycle = EXCLUDED.cycle,
+			pinned = false, pinned_by = '', chosen_at = now()
+	`, date, id, cycle, models.DailyRotation)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to save daily quote for %s: %v", date, err)
+	}
+	if err := scanDaily(tx.QueryRow(ctx, query, date, models.DailyRotation), &dq); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to get daily quote for %s: %v", date, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to commit tx: %v", err)
+	}
+	return &dq, nil
+}
+
+// pickDailyQuote выбирает цитату, ещё не показанную в текущем круге;
+// когда круг исчерпан, начинается следующий
+func pickDailyQuote(ctx context.Context, tx pgx.Tx, date string) (int, int, error) {
+	var cycle int
+	err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(cycle), 1) FROM daily_quotes WHERE mode = $1`,
+		models.DailyRotation).Scan(&cycle)
+	if err != nil {
+		return 0, 0, errdefs.Wrapf(errdefs.ErrDB, "failed to get daily cycle: %v", err)
+	}
+
+	query := `
+		SELECT id FROM quotesbook q
+		WHERE deleted_at IS NULL AND NOT EXISTS (
+			SELECT 1 FROM daily_quotes d
+			WHERE d.cycle = $2 AND d.quote_id = q.id AND d.day <> $1::date
+		)
+		ORDER BY md5($1::date::text || '-' || id)
+		LIMIT 1`
+
+	for _, c := range []int{cycle, cycle + 1} {
+		var id int
+		err := tx.QueryRow(ctx, query, date, c).Scan(&id)
+		if err == nil {
+			return id, c, nil
+		}
+		if !errdefs.Is(err, pgx.ErrNoRows) {
+			return 0, 0, errdefs.Wrapf(errdefs.ErrDB, "failed to pick daily quote: %v", err)
+		}
+	}
+	// в новом круге исключать нечего, значит цитат нет вообще
+	return 0, 0, errdefs.Wrap(errdefs.ErrNotFound, "no quotes to choose from")
+}
+
+// DailyHistory прошедшие цитаты дня режима mode до until включительно, новые
+// первыми, по одной на день. Для on-this-day день показывается так же, как
+// его отдаёт DailyQuote: назначенная цитата, иначе цитата юбиляра, иначе
+// цитата по кругу. Назначенные на будущие дни не показываются.
+func (dr DailyRepository) DailyHistory(ctx context.Context, until time.Time, mode string, limit int) (*[]models.DailyQuote, error) {
+	rows, err := dr.db.Query(ctx, `SELECT DISTINCT ON (d.day) `+dailyColumns+dailyFrom+`
+		WHERE d.day <= $1::date AND quotesbook.deleted_at IS NULL AND d.mode IN ($3, $4)
+		ORDER BY d.day DESC, d.pinned DESC, d.mode = $3 DESC
+		LIMIT $2
+	`, until.Format(time.DateOnly), limit, mode, models.DailyRotation)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to get daily history: %v", err)
+	}
+	defer rows.Close()
+
+	history := []models.DailyQuote{}
+	for rows.Next() {
+		var dq models.DailyQuote
+		if err := scanDaily(rows, &dq); err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan daily quote: %v", err)
+		}
+		history = append(history, dq)
+	}
+	if rows.Err() != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "rows iteration error: %v", rows.Err())
+	}
+	return &history, nil
+}
+
+// PinDailyQuote назначает цитату на день, в том числе уже выбранный.
+// Назначенная цитата считается показанной в текущем круге.
+func (dr DailyRepository) PinDailyQuote(ctx context.Context, day time.Time, quoteID int) (*models.DailyQuote, error) {
+	date := day.Format(time.DateOnly)
+
+	tx, err := dr.db.Begin(ctx)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, dailyLock); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to lock daily quotes: %v", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO daily_quotes (day, quote_id, cycle, mode, pinned, pinned_by)
+		SELECT $1::date, id, (SELECT COALESCE(MAX(cycle), 1) FROM daily_quotes WHERE mode = $4), $4, true, $3
+		FROM quotesbook WHERE id = $2 AND deleted_at IS NULL
+		ON CONFLICT (day, mode) DO UPDATE
+		SET quote_id = EXCLUDED.quote_id, pinned = true,
+			pinned_by = EXCLUDED.pinned_by, chosen_at = now()
+	`, date, quoteID, identity.ActorFromCtx(ctx), models.DailyRotation)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to pin daily quote for %s: %v", date, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, errdefs.Wrapf(errdefs.ErrNotFound, "quote %d not found", quoteID)
+	}
+
+	var dq models.DailyQuote
+	if err := scanDaily(tx.QueryRow(ctx, dailySelect+` WHERE d.day = $1::date AND d.mode = $2`,
+		date, models.DailyRotation), &dq); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to get daily quote for %s: %v", date, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to commit tx: %v", err)
+	}
+	return &dq, nil
+}
+
+// OnThisDayQuote цитата дня day для режима on-this-day: назначенная
+// администратором, иначе уже выбранная цитата юбиляра. ErrNotFound — на этот
+// день ещё ничего не выбрано или выбранную цитату удалили.
+func (dr DailyRepository) OnThisDayQuote(ctx context.Context, day time.Time) (*models.DailyQuote, error) {
+	date := day.Format(time.DateOnly)
+	query := dailySelect + `
+		WHERE d.day = $1::date AND (d.mode = $2 OR d.pinned) AND quotesbook.deleted_at IS NULL
+		ORDER BY d.pinned DESC
+		LIMIT 1`
+
+	var dq models.DailyQuote
+	err := scanDaily(dr.db.QueryRow(ctx, query, date, models.DailyOnThisDay), &dq)
+	if errdefs.Is(err, pgx.ErrNoRows) {
+		return nil, errdefs.Wrapf(errdefs.ErrNotFound, "no on-this-day quote for %s", date)
+	}
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to get on-this-day quote for %s: %v", date, err)
+	}
+	return &dq, nil
+}
+
+// SaveOnThisDayQuote запоминает цитату юбиляра aq как цитату дня day режима
+// on-this-day. Выбор детерминирован, так что реплика, сохранившая день
+// позже, записывает то же самое.
+func (dr DailyRepository) SaveOnThisDayQuote(ctx context.Context, day time.Time, aq *models.AnniversaryQuote) (*models.DailyQuote, error) {
+	date := day.Format(time.DateOnly)
+	_, err := dr.db.Exec(ctx, `
+		INSERT INTO daily_quotes (day, quote_id, cycle, mode, anniversary_event, anniversary_date)
+		VALUES ($1::date, $2, 0, $3, $4, $5::date)
+		ON CONFLICT (day, mode) DO UPDATE
+		SET quote_id = EXCLUDED.quote_id, anniversary_event = EXCLUDED.anniversary_event,
+			anniversary_date = EXCLUDED.anniversary_date, chosen_at = now()
+	`, date, aq.Quote.ID, models.DailyOnThisDay, aq.Event, aq.Date)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to save on-this-day quote for %s: %v", date, err)
+	}
+
+	anniversary := aq.Anniversary
+	return &models.DailyQuote{
+		Date:        date,
+		Mode:        models.DailyOnThisDay,
+		Anniversary: &anniversary,
+		Quote:       aq.Quote,
+	}, nil
+}
+
+// OnThisDay цитаты авторов, родившихся или умерших в один из days (MM-DD)
+// любого года, по дате события, затем по id
+func (dr DailyRepository) OnThisDay(ctx context.Context, days []string) (*[]models.AnniversaryQuote, error) {
+	query := `
+		SELECT e.event, e.day, ` + quoteColumns + `
+		FROM authors a
+		CROSS JOIN LATERAL (VALUES ($2, a.born), ($3, a.died)) AS e(event, day)
+		JOIN quotesbook ON lower(quotesbook.author) = a.key
+		WHERE quotesbook.deleted_at IS NULL AND to_char(e.day, 'MM-DD') = ANY($1)
+		ORDER BY e.day, e.event, id
+	`
+
+	rows, err := dr.db.Query(ctx, query, days, models.AnniversaryBorn, models.AnniversaryDied)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to query anniversaries: %v", err)
+	}
+	quotes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.AnniversaryQuote, error) {
+		var (
+			aq  models.AnniversaryQuote
+			day time.Time
+		)
+		err := row.Scan(append([]any{&aq.Event, &day}, quoteFields(&aq.Quote)...)...)
+		aq.Date = day.Format(time.DateOnly)
+		return aq, err
+	})
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan anniversary: %v", err)
+	}
+	return &quotes, nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+)
+
+func TestDailyRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewDailyRepository(db, cfg)
+	quotes := NewQuoteRepository(db, cfg)
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC) }
+
+	t.Run("Empty", func(t *testing.T) {
+		clearTable(t)
+		_, err := repo.DailyQuote(ctx, day(1))
+		require.ErrorIs(t, err, errdefs.ErrNotFound)
+	})
+
+	t.Run("StableAndNoRepeats", func(t *testing.T) {
+		clearTable(t)
+		for _, text := range []string{"one", "two", "three"} {
+			_, err := quotes.CreateQuote(ctx, &models.Quote{Author: "A", Quote: text})
+			require.NoError(t, err)
+		}
+
+		first, err := repo.DailyQuote(ctx, day(1))
+		require.NoError(t, err)
+		require.Equal(t, "2024-03-01", first.Date)
+		again, err := repo.DailyQuote(ctx, day(1))
+		require.NoError(t, err)
+		require.Equal(t, first.Quote.ID, again.Quote.ID)
+
+		seen := map[int]bool{first.Quote.ID: true}
+		for d := 2; d <= 3; d++ {
+			dq, err := repo.DailyQuote(ctx, day(d))
+			require.NoError(t, err)
+			require.False(t, seen[dq.Quote.ID], "quote %d repeated before the pool was exhausted", dq.Quote.ID)
+			seen[dq.Quote.ID] = true
+		}
+
+		// круг исчерпан, четвёртый день начинает новый
+		_, err = repo.DailyQuote(ctx, day(4))
+		require.NoError(t, err)
+
+		history, err := repo.DailyHistory(ctx, day(3), models.DailyRotation, 10)
+		require.NoError(t, err)
+		require.Len(t, *history, 3)
+		require.Equal(t, "2024-03-03", (*history)[0].Date)
+	})
+
+	t.Run("PinAndDeleted", func(t *testing.T) {
+		clearTable(t)
+		a, err := quotes.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "one"})
+		require.NoError(t, err)
+		b, err := quotes.CreateQuote(ctx, &models.Quote{Author: "B", Quote: "two"})
+		require.NoError(t, err)
+
+		dq, err := repo.DailyQuote(ctx, day(1))
+		require.NoError(t, err)
+		other := a
+		if dq.Quote.ID == a {
+			other = b
+		}
+
+		pinned, err := repo.PinDailyQuote(ctx, day(1), other)
+		require.NoError(t, err)
+		require.True(t, pinned.Pinned)
+		dq, err = repo.DailyQuote(ctx, day(1))
+		require.NoError(t, err)
+		require.Equal(t, other, dq.Quote.ID)
+
+		_, err = repo.PinDailyQuote(ctx, day(2), 9999)
+		require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+		// удалённая цитата дня заменяется другой
+		require.NoError(t, quotes.DeleteQuote(ctx, other, 0))
+		dq, err = repo.DailyQuote(ctx, day(1))
+		require.NoError(t, err)
+		require.NotEqual(t, other, dq.Quote.ID)
+		require.False(t, dq.Pinned)
+
+		// назначенное на будущее в историю не попадает
+		_, err = repo.PinDailyQuote(ctx, day(20), dq.Quote.ID)
+		require.NoError(t, err)
+		history, err := repo.DailyHistory(ctx, day(2), models.DailyRotation, 10)
+		require.NoError(t, err)
+		require.Len(t, *history, 1)
+	})
+
+	t.Run("OnThisDay", func(t *testing.T) {
+		clearTable(t)
+		tolstoy, err := quotes.CreateQuote(ctx, &models.Quote{Author: "Leo Tolstoy", Quote: "All happy families are alike"})
+		require.NoError(t, err)
+		_, err = quotes.CreateQuote(ctx, &models.Quote{Author: "Rossini", Quote: "Give me a laundry list"})
+		require.NoError(t, err)
+		_, err = quotes.CreateQuote(ctx, &models.Quote{Author: "Nobody", Quote: "no dates"})
+		require.NoError(t, err)
+
+		_, err = quotes.PutAuthor(ctx, &models.Author{Name: "Leo Tolstoy", Born: "1828-09-09", Died: "1910-11-20"})
+		require.NoError(t, err)
+		_, err = quotes.PutAuthor(ctx, &models.Author{Name: "Rossini", Born: "1792-02-29"})
+		require.NoError(t, err)
+
+		got, err := repo.OnThisDay(ctx, []string{"11-20"})
+		require.NoError(t, err)
+		require.Len(t, *got, 1)
+		require.Equal(t, models.AnniversaryDied, (*got)[0].Event)
+		require.Equal(t, "1910-11-20", (*got)[0].Date)
+		require.Equal(t, tolstoy, (*got)[0].Quote.ID)
+
+		got, err = repo.OnThisDay(ctx, []string{"02-28", "02-29"})
+		require.NoError(t, err)
+		require.Len(t, *got, 1)
+		require.Equal(t, "Rossini", (*got)[0].Quote.Author)
+
+		got, err = repo.OnThisDay(ctx, []string{"01-01"})
+		require.NoError(t, err)
+		require.Empty(t, *got)
+	})
+
+	t.Run("OnThisDayQuote", func(t *testing.T) {
+		clearTable(t)
+		a, err := quotes.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "one"})
+		require.NoError(t, err)
+		b, err := quotes.CreateQuote(ctx, &models.Quote{Author: "B", Quote: "two"})
+		require.NoError(t, err)
+
+		_, err = repo.OnThisDayQuote(ctx, day(1))
+		require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+		aq := &models.AnniversaryQuote{
+			Anniversary: models.Anniversary{Event: models.AnniversaryBorn, Date: "1828-03-01", Years: 196},
+			Quote:       models.Quote{ID: a},
+		}
+		_, err = repo.SaveOnThisDayQuote(ctx, day(1), aq)
+		require.NoError(t, err)
+		dq, err := repo.OnThisDayQuote(ctx, day(1))
+		require.NoError(t, err)
+		require.Equal(t, models.DailyOnThisDay, dq.Mode)
+		require.Equal(t, a, dq.Quote.ID)
+		require.Equal(t, &aq.Anniversary, dq.Anniversary)
+
+		// цитата юбиляра не расходует круг: день 2 может получить ту же цитату
+		// по кругу, а история on-this-day показывает то, что было показано
+		_, err = repo.DailyQuote(ctx, day(2))
+		require.NoError(t, err)
+		history, err := repo.DailyHistory(ctx, day(2), models.DailyOnThisDay, 10)
+		require.NoError(t, err)
+		require.Len(t, *history, 2)
+		require.Equal(t, models.DailyRotation, (*history)[0].Mode)
+		require.Equal(t, models.DailyOnThisDay, (*history)[1].Mode)
+		require.Equal(t, a, (*history)[1].Quote.ID)
+		history, err = repo.DailyHistory(ctx, day(2), models.DailyRotation, 10)
+		require.NoError(t, err)
+		require.Len(t, *history, 1)
+
+		// назначенная цитата важнее цитаты юбиляра
+		_, err = repo.PinDailyQuote(ctx, day(1), b)
+		require.NoError(t, err)
+		dq, err = repo.OnThisDayQuote(ctx, day(1))
+		require.NoError(t, err)
+		require.True(t, dq.Pinned)
+		require.Equal(t, b, dq.Quote.ID)
+	})
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+
+	"quotebook/config"
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// колонки quote_disputes, читаются через scanDispute
+const disputeColumns = `id, quote_id, filed_by, proposed, reason, evidence, status,
+	COALESCE(reviewer, ''), review_note, created_at, reviewed_at`
+
+type DisputeRepository struct {
+	db  *pgxpool.Pool
+	cfg *config.Config
+}
+
+func NewDisputeRepository(db *pgxpool.Pool, cfg *config.Config) DisputeRepository {
+	return DisputeRepository{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+func scanDispute(row pgx.Row, d *models.Dispute) error {
+	return row.Scan(&d.ID, &d.QuoteID, &d.FiledBy, &d.Proposed, &d.Reason, &d.Evidence, &d.Status,
+		&d.Reviewer, &d.ReviewNote, &d.CreatedAt, &d.ReviewedAt)
+}
+
+func collectDisputes(rows pgx.Rows) (*[]models.Dispute, error) {
+	defer rows.Close()
+
+	disputes := []models.Dispute{}
+	for rows.Next() {
+		var d models.Dispute
+		if err := scanDispute(rows, &d); err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan dispute: %v", err)
+		}
+		disputes = append(disputes, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to iterate disputes: %v", err)
+	}
+	return &disputes, nil
+}
+
+// CreateDispute заводит оспаривание цитаты d.QuoteID; остальные поля
+// дописываются в d. Открытое оспаривание того же пользователя — ErrConflict.
+func (dr DisputeRepository) CreateDispute(ctx context.Context, d *models.Dispute) error {
+	query := `
+		INSERT INTO quote_disputes (quote_id, filed_by, proposed, reason, evidence)
+		SELECT id, $2, $3, $4, $5 FROM quotesbook WHERE id = $1 AND deleted_at IS NULL
+		ON CONFLICT (quote_id, filed_by) WHERE status = 'open' DO NOTHING
+		RETURNING ` + disputeColumns
+
+	err := scanDispute(dr.db.QueryRow(ctx, query, d.QuoteID, d.FiledBy, d.Proposed, d.Reason, d.Evidence), d)
+	if err == nil {
+		return nil
+	}
+	if !errdefs.Is(err, pgx.ErrNoRows) {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to file dispute: %v", err)
+	}
+
+	// строка не вставилась: либо цитаты нет, либо уже есть открытое оспаривание
+	var alive bool
+	err = dr.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM quotesbook WHERE id = $1 AND deleted_at IS NULL)`, d.QuoteID).Scan(&alive)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to check quote %d: %v", d.QuoteID, err)
+	}
+	if !alive {
+		return errdefs.ErrNotFound
+	}
+	return errdefs.Wrapf(errdefs.ErrConflict, "%s already has an open dispute on quote %d", d.FiledBy, d.QuoteID)
+}
+
+// QuoteDisputes оспаривания цитаты, новые первыми
+func (dr DisputeRepository) QuoteDisputes(ctx context.Context, quoteID int) (*[]models.Dispute, error) {
+	query := `
+		SELECT ` + disputeColumns + `
+		FROM quote_disputes
+		WHERE quote_id = $1
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := dr.db.Query(ctx, query, quoteID)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to list disputes of quote %d: %v", quoteID, err)
+	}
+	return collectDisputes(rows)
+}
+
+// Disputes очередь проверяющего: оспаривания в статусе status, старые первыми
+func (dr DisputeRepository) Disputes(ctx context.Context, status string, limit int) (*[]models.Dispute, error) {
+	query := `
+		SELECT ` + disputeColumns + `
+		FROM quote_disputes
+		WHERE status = $1
+		ORDER BY created_at, id
+		LIMIT $2`
+
+	rows, err := dr.db.Query(ctx, query, status, limit)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to list disputes: %v", err)
+	}
+	return collectDisputes(rows)
+}
+
+// rowQuerier пул или транзакция
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// setAttribution меняет статус авторства живой цитаты; это правка цитаты,
+// поэтому растут version и updated_at
+func setAttribution(ctx context.Context, q rowQuerier, quoteID int, attribution, note string) (*models.Quote, error) {
+	query := `
+		UPDATE quotesbook
+		SET attribution = $2, attribution_note = $3, updated_at = now(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING ` + quoteColumns
+
+	var quote models.Quote
+	err := scanQuote(q.QueryRow(ctx, query, quoteID, attribution, note), &quote)
+	if errdefs.Is(err, pgx.ErrNoRows) {
+		return nil, errdefs.ErrNotFound
+	}
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to set attribution of quote %d: %v", quoteID, err)
+	}
+	return &quote, nil
+}
+
+// ResolveDispute закрывает открытое оспаривание решением r; при accept статус
+// авторства цитаты меняется в той же транзакции. Уже закрытое — ErrConflict.
+func (dr DisputeRepository) ResolveDispute(ctx context.Context, id int, reviewer string, r *models.DisputeReview) (*models.Dispute, error) {
+	tx, err := dr.db.Begin(ctx)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var d models.Dispute
+	err = scanDispute(tx.QueryRow(ctx, `SELECT `+disputeColumns+` FROM quote_disputes WHERE id = $1 FOR UPDATE`, id), &d)
+	if errdefs.Is(err, pgx.ErrNoRows) {
+		return nil, errdefs.ErrNotFound
+	}
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to get dispute %d: %v", id, err)
+	}
+	if d.Status != models.DisputeOpen {
+		return nil, errdefs.Wrapf(errdefs.ErrConflict, "dispute %d is already %s", id, d.Status)
+	}
+
+	status := models.DisputeRejected
+	if r.Decision == models.DisputeAccepted {
+		status = models.DisputeAccepted
+		attribution := r.Attribution
+		if attribution == "" {
+			attribution = d.Proposed
+		}
+		note := r.Note
+		if note == "" {
+			note = d.Evidence
+		}
+		if _, err := setAttribution(ctx, tx, d.QuoteID, attribution, note); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		UPDATE quote_disputes
+		SET status = $2, reviewer = $3, review_note = $4, reviewed_at = now()
+		WHERE id = $1
+		RETURNING ` + disputeColumns
+	if err := scanDispute(tx.QueryRow(ctx, query, id, status, reviewer, r.Note), &d); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to resolve dispute %d: %v", id, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to commit tx: %v", err)
+	}
+	return &d, nil
+}
+
+// SetAttribution статус авторства, выставленный проверяющим без оспаривания
+func (dr DisputeRepository) SetAttribution(ctx context.Context, quoteID int, u *models.AttributionUpdate) (*models.Quote, error) {
+	return setAttribution(ctx, dr.db, quoteID, u.Attribution, u.Note)
+}
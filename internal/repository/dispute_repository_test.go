@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+)
+
+func TestDisputeRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewDisputeRepository(db, cfg)
+	quotes := NewQuoteRepository(db, cfg)
+
+	t.Run("FileAndResolve", func(t *testing.T) {
+		clearTable(t)
+		id, err := quotes.CreateQuote(ctx, &models.Quote{Author: "Albert Einstein", Quote: "Insanity is doing the same thing..."})
+		require.NoError(t, err)
+
+		q, err := quotes.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, models.AttributionUnknown, q.Attribution)
+
+		d := &models.Dispute{QuoteID: id, FiledBy: "alice", Proposed: models.AttributionMisattributed,
+			Reason: "First appears in 1981", Evidence: "https://quoteinvestigator.com/2017/03/23/same/"}
+		require.NoError(t, repo.CreateDispute(ctx, d))
+		require.Equal(t, models.DisputeOpen, d.Status)
+
+		// второе открытое от того же пользователя не принимается
+		again := &models.Dispute{QuoteID: id, FiledBy: "alice", Proposed: models.AttributionDisputed, Reason: "x"}
+		require.ErrorIs(t, repo.CreateDispute(ctx, again), errdefs.ErrConflict)
+		missing := &models.Dispute{QuoteID: id + 1000, FiledBy: "alice", Proposed: models.AttributionDisputed, Reason: "x"}
+		require.ErrorIs(t, repo.CreateDispute(ctx, missing), errdefs.ErrNotFound)
+
+		open, err := repo.Disputes(ctx, models.DisputeOpen, 10)
+		require.NoError(t, err)
+		require.Len(t, *open, 1)
+
+		resolved, err := repo.ResolveDispute(ctx, d.ID, "bob", &models.DisputeReview{Decision: models.DisputeAccepted})
+		require.NoError(t, err)
+		require.Equal(t, models.DisputeAccepted, resolved.Status)
+		require.Equal(t, "bob", resolved.Reviewer)
+		require.NotNil(t, resolved.ReviewedAt)
+
+		_, err = repo.ResolveDispute(ctx, d.ID, "bob", &models.DisputeReview{Decision: models.DisputeRejected})
+		require.ErrorIs(t, err, errdefs.ErrConflict)
+
+		q, err = quotes.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, models.AttributionMisattributed, q.Attribution)
+		require.Equal(t, d.Evidence, q.AttributionNote)
+		require.Equal(t, 2, q.Version)
+
+		// закрытое оспаривание не мешает открыть новое
+		require.NoError(t, repo.CreateDispute(ctx, again))
+		all, err := repo.QuoteDisputes(ctx, id)
+		require.NoError(t, err)
+		require.Len(t, *all, 2)
+
+		hidden := 0
+		err = quotes.StreamQuotes(ctx, &models.QuoteFilter{HideMisattributed: true}, func(*models.Quote) error {
+			hidden++
+			return nil
+		})
+		require.NoError(t, err)
+		require.Zero(t, hidden)
+	})
+
+	t.Run("SetAttribution", func(t *testing.T) {
+		clearTable(t)
+		id, err := quotes.CreateQuote(ctx, &models.Quote{Author: "Confucius", Quote: "one"})
+		require.NoError(t, err)
+
+		q, err := repo.SetAttribution(ctx, id, &models.AttributionUpdate{Attribution: models.AttributionVerified, Note: "Analects 2.17"})
+		require.NoError(t, err)
+		require.Equal(t, models.AttributionVerified, q.Attribution)
+
+		// обычная правка статус не трогает
+		require.NoError(t, quotes.UpdateQuote(ctx, &models.Quote{ID: id, Author: "Confucius", Quote: "one!"}))
+		q, err = quotes.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, models.AttributionVerified, q.Attribution)
+
+		_, err = repo.SetAttribution(ctx, id+1000, &models.AttributionUpdate{Attribution: models.AttributionVerified})
+		require.ErrorIs(t, err, errdefs.ErrNotFound)
+	})
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+
+	"quotebook/config"
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// engagementSelect счётчики цитаты $1 и отношение к ней пользователя $2
+const engagementSelect = `
+	SELECT q.favorites, q.rating_count,
+		COALESCE(round(q.rating_sum::numeric / NULLIF(q.rating_count, 0), 2), 0)::float8,
+		EXISTS (SELECT 1 FROM quote_favorites f WHERE f.quote_id = q.id AND f.principal = $2::text),
+		COALESCE((SELECT r.stars FROM quote_ratings r WHERE r.quote_id = q.id AND r.principal = $2::text), 0)
+	FROM quotesbook q
+	WHERE q.id = $1 AND q.deleted_at IS NULL`
+
+// оценки одного пользователя одной цитаты сериализуются: иначе две
+// параллельные первые оценки дважды увеличили бы rating_count
+const ratingLock = `SELECT pg_advisory_xact_lock(hashtext($2::text), $1::int)`
+
+type EngagementRepository struct {
+	db  *pgxpool.Pool
+	cfg *config.Config
+}
+
+func NewEngagementRepository(db *pgxpool.Pool, cfg *config.Config) EngagementRepository {
+	return EngagementRepository{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// withEngagement выполняет fn в транзакции и читает итоговые счётчики;
+// удалённая или несуществующая цитата — ErrNotFound, изменения откатываются
+func (er EngagementRepository) withEngagement(ctx context.Context, id int, principal string, fn func(tx pgx.Tx) error) (*models.Engagement, error) {
+	tx, err := er.db.Begin(ctx)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(tx); err != nil {
+		return nil, err
+	}
+
+	e := models.Engagement{QuoteID: id}
+	err = tx.QueryRow(ctx, engagementSelect, id, principal).
+		Scan(&e.Favorites, &e.RatingCount, &e.RatingAvg, &e.Favorited, &e.MyRating)
+	if errdefs.Is(err, pgx.ErrNoRows) {
+		return nil, errdefs.ErrNotFound
+	}
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to read engagement of quote %d: %v", id, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to commit tx: %v", err)
+	}
+	return &e, nil
+}
+
+// AddFavorite добавляет цитату в избранное principal; повторный вызов ничего не меняет.
+// Счётчик увеличивается тем же запросом и только если строка действительно вставлена.
+func (er EngagementRepository) AddFavorite(ctx context.Context, id int, principal string) (*models.Engagement, error) {
+	query := `
+		WITH ins AS (
+			INSERT INTO quote_favorites (principal, quote_id)
+			SELECT $2, id FROM quotesbook WHERE id = $1 AND deleted_at IS NULL
+			ON CONFLICT DO NOTHING
+			RETURNING quote_id
+		)
+		UPDATE quotesbook SET favorites = favorites + 1, engaged_at = now()
+		WHERE id IN (SELECT quote_id FROM ins)`
+
+	return er.withEngagement(ctx, id, principal, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, query, id, principal); err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to favorite quote %d: %v", id, err)
+		}
+		return nil
+	})
+}
+
+// RemoveFavorite убирает цитату из избранного principal; повторный вызов ничего не меняет
+func (er EngagementRepository) RemoveFavorite(ctx context.Context, id int, principal string) (*models.Engagement, error) {
+	query := `
+		WITH del AS (
+			DELETE FROM quote_favorites WHERE quote_id = $1 AND principal = $2
+			RETURNING quote_id
+		)
+		UPDATE quotesbook SET favorites = favorites - 1, engaged_at = now()
+		WHERE id IN (SELECT quote_id FROM del)`
+
+	return er.withEngagement(ctx, id, principal, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, query, id, principal); err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to unfavorite quote %d: %v", id, err)
+		}
+		return nil
+	})
+}
+
+// RateQuote ставит или меняет оценку principal; сумма и число оценок
+// на цитате меняются на разницу со старой оценкой
+func (er EngagementRepository) RateQuote(ctx context.Context, id int, principal string, stars int) (*models.Engagement, error) {
+	return er.withEngagement(ctx, id, principal, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, ratingLock, id, principal); err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to lock rating: %v", err)
+		}
+
+		var alive bool
+		err := tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM quotesbook WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&alive)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to check quote %d: %v", id, err)
+		}
+		if !alive {
+			return errdefs.ErrNotFound
+		}
+
+		var old int
+		err = tx.QueryRow(ctx,
+			`SELECT stars FROM quote_ratings WHERE quote_id = $1 AND principal = $2`, id, principal).Scan(&old)
+		if err != nil && !errdefs.Is(err, pgx.ErrNoRows) {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to read rating: %v", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO quote_ratings (quote_id, principal, stars)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (quote_id, principal) DO UPDATE
+			SET stars = EXCLUDED.stars, updated_at = now()`, id, principal, stars)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to rate quote %d: %v", id, err)
+		}
+
+		added := 0
+		if old == 0 {
+			added = 1
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE quotesbook
+			SET rating_sum = rating_sum + $2, rating_count = rating_count + $3, engaged_at = now()
+			WHERE id = $1`, id, stars-old, added)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to update rating of quote %d: %v", id, err)
+		}
+		return nil
+	})
+}
+
+// RemoveRating снимает оценку principal; повторный вызов ничего не меняет
+func (er EngagementRepository) RemoveRating(ctx context.Context, id int, principal string) (*models.Engagement, error) {
+	return er.withEngagement(ctx, id, principal, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, ratingLock, id, principal); err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to lock rating: %v", err)
+		}
+
+		var old int
+		err := tx.QueryRow(ctx, `
+			DELETE FROM quote_ratings WHERE quote_id = $1 AND principal = $2
+			RETURNING stars`, id, principal).Scan(&old)
+		if errdefs.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to unrate quote %d: %v", id, err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE quotesbook
+			SET rating_sum = rating_sum - $2, rating_count = rating_count - 1, engaged_at = now()
+			WHERE id = $1`, id, old)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to update rating of quote %d: %v", id, err)
+		}
+		return nil
+	})
+}
+
+// FavoriteQuotes избранное principal, последние добавленные первыми
+func (er EngagementRepository) FavoriteQuotes(ctx context.Context, principal string) (*[]models.Quote, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotesbook
+		JOIN (SELECT quote_id, created_at AS favorited_at FROM quote_favorites WHERE principal = $1) f
+			ON f.quote_id = quotesbook.id
+		WHERE deleted_at IS NULL
+		ORDER BY f.favorited_at DESC, id`
+
+	rows, err := er.db.Query(ctx, query, principal)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to list favorites: %v", err)
+	}
+	return collectQuotes(rows)
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+)
+
+func TestEngagementRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewEngagementRepository(db, cfg)
+	quotes := NewQuoteRepository(db, cfg)
+
+	t.Run("FavoritesIdempotent", func(t *testing.T) {
+		clearTable(t)
+		id, err := quotes.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "liked"})
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			e, err := repo.AddFavorite(ctx, id, "alice")
+			require.NoError(t, err)
+			require.Equal(t, 1, e.Favorites)
+			require.True(t, e.Favorited)
+		}
+		_, err = repo.AddFavorite(ctx, id, "bob")
+		require.NoError(t, err)
+
+		favs, err := repo.FavoriteQuotes(ctx, "alice")
+		require.NoError(t, err)
+		require.Len(t, *favs, 1)
+		require.Equal(t, 2, (*favs)[0].Favorites)
+
+		e, err := repo.RemoveFavorite(ctx, id, "alice")
+		require.NoError(t, err)
+		require.Equal(t, 1, e.Favorites)
+		require.False(t, e.Favorited)
+		e, err = repo.RemoveFavorite(ctx, id, "alice")
+		require.NoError(t, err)
+		require.Equal(t, 1, e.Favorites)
+
+		_, err = repo.AddFavorite(ctx, id+1000, "alice")
+		require.ErrorIs(t, err, errdefs.ErrNotFound)
+	})
+
+	t.Run("ConcurrentLikes", func(t *testing.T) {
+		clearTable(t)
+		id, err := quotes.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "bursty"})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 20)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				// каждый пользователь жмёт дважды
+				_, err := repo.AddFavorite(ctx, id, string(rune('a'+i%10)))
+				errs <- err
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		q, err := quotes.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, 10, q.Favorites)
+	})
+
+	t.Run("Ratings", func(t *testing.T) {
+		clearTable(t)
+		id, err := quotes.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "rated"})
+		require.NoError(t, err)
+
+		_, err = repo.RateQuote(ctx, id, "alice", 5)
+		require.NoError(t, err)
+		e, err := repo.RateQuote(ctx, id, "bob", 2)
+		require.NoError(t, err)
+		require.Equal(t, 2, e.RatingCount)
+		require.Equal(t, 3.5, e.RatingAvg)
+		require.Equal(t, 2, e.MyRating)
+
+		// повторная оценка заменяет прежнюю, число оценок не растёт
+		e, err = repo.RateQuote(ctx, id, "bob", 4)
+		require.NoError(t, err)
+		require.Equal(t, 2, e.RatingCount)
+		require.Equal(t, 4.5, e.RatingAvg)
+
+		e, err = repo.RemoveRating(ctx, id, "alice")
+		require.NoError(t, err)
+		require.Equal(t, 1, e.RatingCount)
+		require.Equal(t, 4.0, e.RatingAvg)
+		require.Zero(t, e.MyRating)
+
+		// версия цитаты от оценок не меняется
+		q, err := quotes.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, 1, q.Version)
+		require.Equal(t, 4.0, q.RatingAvg)
+	})
+
+	t.Run("SortedQuotes", func(t *testing.T) {
+		clearTable(t)
+		a, err := quotes.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "one"})
+		require.NoError(t, err)
+		b, err := quotes.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "two"})
+		require.NoError(t, err)
+
+		_, err = repo.AddFavorite(ctx, b, "alice")
+		require.NoError(t, err)
+		_, err = repo.RateQuote(ctx, a, "alice", 5)
+		require.NoError(t, err)
+		_, err = repo.RateQuote(ctx, b, "alice", 3)
+		require.NoError(t, err)
+
+		popular, err := quotes.SortedQuotes(ctx, models.SortPopular)
+		require.NoError(t, err)
+		require.Equal(t, b, (*popular)[0].ID)
+
+		rated, err := quotes.SortedQuotes(ctx, models.SortRating)
+		require.NoError(t, err)
+		require.Equal(t, a, (*rated)[0].ID)
+
+		recent, err := quotes.SortedQuotes(ctx, models.SortRecent)
+		require.NoError(t, err)
+		require.Equal(t, b, (*recent)[0].ID)
+	})
+}
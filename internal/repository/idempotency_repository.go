@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"quotebook/config"
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotencyRepository struct {
+	db  *pgxpool.Pool
+	cfg *config.Config
+}
+
+func NewIdempotencyRepository(db *pgxpool.Pool, cfg *config.Config) IdempotencyRepository {
+	return IdempotencyRepository{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// ReserveKey занимает ключ под новый запрос. Истёкший ключ занимается заново.
+// Если ключ уже занят, возвращается его запись и false.
+func (ir IdempotencyRepository) ReserveKey(ctx context.Context, principal, key string, expiresAt time.Time) (*models.IdempotencyRecord, bool, error) {
+	reserve := `
+		INSERT INTO idempotency_keys (principal, key, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (principal, key) DO UPDATE
+		SET fingerprint = '', completed = false, status = 0, content_type = '',
+			etag = '', body = NULL, created_at = now(), expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < now()
+	`
+	tag, err := ir.db.Exec(ctx, reserve, principal, key, expiresAt)
+	if err != nil {
+		return nil, false, errdefs.Wrapf(errdefs.ErrDB, "failed to reserve idempotency key: %v", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, true, nil
+	}
+
+	query := `
+		SELECT principal, key, fingerprint, completed, status, content_type, etag,
+			COALESCE(body, ''::bytea), created_at, expires_at
+		FROM idempotency_keys
+		WHERE principal = $1 AND key = $2
+	`
+	var rec models.IdempotencyRecord
+	err = ir.db.QueryRow(ctx, query, principal, key).Scan(
+		&rec.Principal, &rec.Key, &rec.Fingerprint, &rec.Completed, &rec.Status,
+		&rec.ContentType, &rec.ETag, &rec.Body, &rec.CreatedAt, &rec.ExpiresAt,
+	)
+	if err != nil {
+		// ключ успели освободить между запросами
+		if errdefs.Is(err, pgx.ErrNoRows) {
+			return nil, false, errdefs.Wrap(errdefs.ErrConflict, "idempotency key is being released, retry")
+		}
+		return nil, false, errdefs.Wrapf(errdefs.ErrDB, "failed to read idempotency key: %v", err)
+	}
+	return &rec, false, nil
+}
+
+// CompleteKey сохраняет ответ на запрос, занявший ключ
+func (ir IdempotencyRepository) CompleteKey(ctx context.Context, rec *models.IdempotencyRecord) error {
+	query := `
+		UPDATE idempotency_keys
+		SET fingerprint = $3, completed = true, status = $4, content_type = $5, etag = $6, body = $7
+		WHERE principal = $1 AND key = $2
+	`
+	_, err := ir.db.Exec(ctx, query, rec.Principal, rec.Key, rec.Fingerprint,
+		rec.Status, rec.ContentType, rec.ETag, rec.Body)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to complete idempotency key: %v", err)
+	}
+	return nil
+}
+
+// ReleaseKey освобождает ключ, чтобы повтор выполнился заново
+func (ir IdempotencyRepository) ReleaseKey(ctx context.Context, principal, key string) error {
+	_, err := ir.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE principal = $1 AND key = $2`, principal, key)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to release idempotency key: %v", err)
+	}
+	return nil
+}
+
+func (ir IdempotencyRepository) PurgeExpiredKeys(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := ir.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, errdefs.Wrapf(errdefs.ErrDB, "failed to purge idempotency keys: %v", err)
+	}
+	return tag.RowsAffected(), nil
+}
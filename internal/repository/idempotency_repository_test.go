@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"quotebook/internal/models"
+)
+
+func clearIdempotency(t *testing.T) {
+	ctx := context.Background()
+	_, err := db.Exec(ctx, fmt.Sprintf("TRUNCATE TABLE %s.idempotency_keys", cfg.DB.Schema))
+	require.NoError(t, err, "Failed to clear idempotency_keys table")
+}
+
+func TestIdempotencyRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewIdempotencyRepository(db, cfg)
+
+	t.Run("ReserveCompleteReplay", func(t *testing.T) {
+		clearIdempotency(t)
+
+		_, reserved, err := repo.ReserveKey(ctx, "alice", "k1", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		// пока запрос выполняется, ключ занят
+		rec, reserved, err := repo.ReserveKey(ctx, "alice", "k1", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.False(t, reserved)
+		require.False(t, rec.Completed)
+
+		// у другого клиента свои ключи
+		_, reserved, err = repo.ReserveKey(ctx, "bob", "k1", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		err = repo.CompleteKey(ctx, &models.IdempotencyRecord{
+			Principal: "alice", Key: "k1", Fingerprint: "fp", Status: 201,
+			ContentType: "application/json", Body: []byte("7\n"),
+		})
+		require.NoError(t, err)
+
+		rec, reserved, err = repo.ReserveKey(ctx, "alice", "k1", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.False(t, reserved)
+		require.True(t, rec.Completed)
+		require.Equal(t, 201, rec.Status)
+		require.Equal(t, "fp", rec.Fingerprint)
+		require.Equal(t, []byte("7\n"), rec.Body)
+	})
+
+	t.Run("ExpiredAndReleased", func(t *testing.T) {
+		clearIdempotency(t)
+
+		_, reserved, err := repo.ReserveKey(ctx, "alice", "old", time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		// истёкший ключ занимается заново
+		_, reserved, err = repo.ReserveKey(ctx, "alice", "old", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		require.NoError(t, repo.ReleaseKey(ctx, "alice", "old"))
+		_, reserved, err = repo.ReserveKey(ctx, "alice", "old", time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		purged, err := repo.PurgeExpiredKeys(ctx, time.Now())
+		require.NoError(t, err)
+		require.Equal(t, int64(1), purged)
+	})
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+
+	"quotebook/config"
+	"quotebook/internal/errdefs"
+	"quotebook/internal/identity"
+	"quotebook/internal/logger"
+	"quotebook/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// сколько номеров строк-дублей возвращать для отчёта
+const maxDuplicateRows = 1000
+
+type ImportRepository struct {
+	db  *pgxpool.Pool
+	cfg *config.Config
+}
+
+func NewImportRepository(db *pgxpool.Pool, cfg *config.Config) ImportRepository {
+	return ImportRepository{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// ImportQuotes заливает пачки из next через COPY во временную таблицу и затем
+// одним запросом переносит их в quotesbook по политике opts.OnDuplicate.
+// next возвращает пустую пачку, когда строки кончились.
+// Всё выполняется в одной транзакции; при DryRun она откатывается.
+func (ir ImportRepository) ImportQuotes(ctx context.Context, opts *models.ImportOptions, next func() ([]models.ImportRow, error)) (*models.ImportCounts, error) {
+	tx, err := ir.db.Begin(ctx)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE import_rows (
+			rn INT NOT NULL, author VARCHAR(255) NOT NULL, quote TEXT NOT NULL, lang VARCHAR(8) NOT NULL
+		) ON COMMIT DROP
+	`)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to create import table: %v", err)
+	}
+
+	for {
+		batch, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		_, err = tx.CopyFrom(ctx, pgx.Identifier{"import_rows"}, []string{"rn", "author", "quote", "lang"},
+			pgx.CopyFromSlice(len(batch), func(i int) ([]any, error) {
+				return []any{batch[i].Row, batch[i].Author, batch[i].Quote, batch[i].Lang}, nil
+			}),
+		)
+		if err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to copy import rows: %v", err)
+		}
+	}
+
+	// первая строка с данным текстом побеждает, остальные — дубли внутри файла
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE import_uniq ON COMMIT DROP AS
+		SELECT DISTINCT ON (md5(lower(btrim(quote))))
+			rn, author, quote, lang, md5(lower(btrim(quote))) AS qkey, NULL::INT AS existing_id
+		FROM import_rows
+		ORDER BY md5(lower(btrim(quote))), rn
+	`)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to dedupe import rows: %v", err)
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE import_uniq u
+		SET existing_id = q.id
+		FROM quotesbook q
+		WHERE md5(lower(btrim(q.quote))) = u.qkey AND q.deleted_at IS NULL
+	`)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to match existing quotes: %v", err)
+	}
+
+	counts, err := importDuplicates(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if opts.OnDuplicate == models.DuplicateFail && counts.Duplicates > 0 {
+		return counts, errdefs.Wrapf(errdefs.ErrConflict, "%d duplicate rows", counts.Duplicates)
+	}
+
+	actor := identity.ActorFromCtx(ctx)
+	requestID := logger.RequestIDFromCtx(ctx)
+
+	// вставка вместе с первой ревизией
+	tag, err := tx.Exec(ctx, `
+		WITH ins AS (
+			INSERT INTO quotesbook (author, quote, lang)
+			SELECT author, quote, lang FROM import_uniq WHERE existing_id IS NULL ORDER BY rn
+			RETURNING id, author, quote, tags, source, weight, lang
+		)
+		INSERT INTO quote_revisions (
+			quote_id, rev, action, author, quote, tags, source, weight, lang, actor, request_id
+		)
+		SELECT id, 1, $1, author, quote, tags, COALESCE(source, 'null'::jsonb), weight, lang, $2, $3
+		FROM ins
+	`, models.ActionCreate, actor, requestID)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to insert imported quotes: %v", err)
+	}
+	counts.Inserted = int(tag.RowsAffected())
+
+	if opts.OnDuplicate == models.DuplicateUpdate {
+		tag, err = tx.Exec(ctx, `
+			WITH upd AS (
+				UPDATE quotesbook q
+				SET author = u.author, updated_at = now(), version = q.version + 1
+				FROM import_uniq u
+				WHERE q.id = u.existing_id AND q.author <> u.author
+				RETURNING q.id, q.author, q.quote, q.tags, q.source, q.weight, q.lang
+			)
+			INSERT INTO quote_revisions (
+				quote_id, rev, action, author, quote, tags, source, weight, lang, actor, request_id
+			)
+			SELECT id,
+				COALESCE((SELECT MAX(rev) FROM quote_revisions r WHERE r.quote_id = upd.id), 0) + 1,
+				$1, author, quote, tags, COALESCE(source, 'null'::jsonb), weight, lang, $2, $3
+			FROM upd
+		`, models.ActionUpdate, actor, requestID)
+		if err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to update duplicate quotes: %v", err)
+		}
+		counts.Updated = int(tag.RowsAffected())
+	}
+
+	if opts.DryRun {
+		return counts, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to commit tx: %v", err)
+	}
+	return counts, nil
+}
+
+// importDuplicates считает дубли внутри файла и с уже существующими цитатами
+func importDuplicates(ctx context.Context, tx pgx.Tx) (*models.ImportCounts, error) {
+	query := `
+		SELECT rn FROM import_rows r
+		WHERE NOT EXISTS (SELECT 1 FROM import_uniq u WHERE u.rn = r.rn)
+		UNION ALL
+		SELECT rn FROM import_uniq WHERE existing_id IS NOT NULL
+		ORDER BY 1
+	`
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to find duplicate rows: %v", err)
+	}
+	defer rows.Close()
+
+	counts := &models.ImportCounts{}
+	for rows.Next() {
+		var row int
+		if err := rows.Scan(&row); err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan duplicate row: %v", err)
+		}
+		counts.Duplicates++
+		if len(counts.DuplicateRows) < maxDuplicateRows {
+			counts.DuplicateRows = append(counts.DuplicateRows, row)
+		}
+	}
+	if rows.Err() != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "rows iteration error: %v", rows.Err())
+	}
+	return counts, nil
+}
+
+func (ir ImportRepository) CreateImportJob(ctx context.Context, job *models.ImportJob) error {
+	query := `
+		INSERT INTO import_jobs (id, status, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING created_at
+	`
+	err := ir.db.QueryRow(ctx, query, job.ID, job.Status, job.CreatedBy).Scan(&job.CreatedAt)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to create import job: %v", err)
+	}
+	return nil
+}
+
+func (ir ImportRepository) UpdateImportJob(ctx context.Context, job *models.ImportJob) error {
+	query := `
+		UPDATE import_jobs
+		SET status = $2, result = $3, error = $4, finished_at = $5
+		WHERE id = $1
+	`
+	_, err := ir.db.Exec(ctx, query, job.ID, job.Status, job.Result, job.Error, job.FinishedAt)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to update import job %s: %v", job.ID, err)
+	}
+	return nil
+}
+
+func (ir ImportRepository) GetImportJob(ctx context.Context, id string) (*models.ImportJob, error) {
+	query := `
+		SELECT id::text, status, result, error, created_by, created_at, finished_at
+		FROM import_jobs
+		WHERE id = $1
+	`
+	var job models.ImportJob
+	err := ir.db.QueryRow(ctx, query, id).Scan(
+		&job.ID, &job.Status, &job.Result, &job.Error, &job.CreatedBy, &job.CreatedAt, &job.FinishedAt,
+	)
+	if err != nil {
+		if errdefs.Is(err, pgx.ErrNoRows) {
+			return nil, errdefs.ErrNotFound
+		}
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to fetch import job %s: %v", id, err)
+	}
+	return &job, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+)
+
+// batches отдаёт пачки по очереди, потом пустую
+func batches(bs ...[]models.ImportRow) func() ([]models.ImportRow, error) {
+	return func() ([]models.ImportRow, error) {
+		if len(bs) == 0 {
+			return nil, nil
+		}
+		b := bs[0]
+		bs = bs[1:]
+		return b, nil
+	}
+}
+
+func TestImportRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewImportRepository(db, cfg)
+	quotes := NewQuoteRepository(db, cfg)
+
+	t.Run("SkipDuplicates", func(t *testing.T) {
+		clearTable(t)
+		_, err := quotes.CreateQuote(ctx, &models.Quote{Author: "Old", Quote: "Existing"})
+		require.NoError(t, err)
+
+		counts, err := repo.ImportQuotes(ctx, &models.ImportOptions{OnDuplicate: models.DuplicateSkip}, batches(
+			[]models.ImportRow{{Row: 2, Author: "A", Quote: "First", Lang: "en"}, {Row: 3, Author: "B", Quote: " existing "}},
+			[]models.ImportRow{{Row: 4, Author: "C", Quote: "FIRST"}, {Row: 5, Author: "D", Quote: "Second"}},
+		))
+		require.NoError(t, err)
+		require.Equal(t, 2, counts.Inserted)
+		require.Equal(t, 2, counts.Duplicates)
+		require.Equal(t, []int{3, 4}, counts.DuplicateRows)
+
+		all, err := quotes.QuotesAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, *all, 3)
+
+		revisions, err := quotes.QuoteRevisions(ctx, (*all)[1].ID)
+		require.NoError(t, err)
+		require.Equal(t, models.ActionCreate, (*revisions)[0].Action)
+		require.Equal(t, "en", (*revisions)[0].Lang)
+		require.Equal(t, 1.0, *(*revisions)[0].Weight)
+	})
+
+	t.Run("UpdateDuplicates", func(t *testing.T) {
+		clearTable(t)
+		weight := 2.5
+		source := &models.Source{Type: models.SourceBook, Title: "Analects"}
+		id, err := quotes.CreateQuote(ctx, &models.Quote{Author: "Old", Quote: "Existing", Lang: "en",
+			Weight: &weight, Source: source})
+		require.NoError(t, err)
+
+		counts, err := repo.ImportQuotes(ctx, &models.ImportOptions{OnDuplicate: models.DuplicateUpdate}, batches(
+			[]models.ImportRow{{Row: 1, Author: "New", Quote: "Existing"}},
+		))
+		require.NoError(t, err)
+		require.Equal(t, 0, counts.Inserted)
+		require.Equal(t, 1, counts.Updated)
+
+		q, err := quotes.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, "New", q.Author)
+		require.Equal(t, 2, q.Version)
+
+		// ревизия обновления хранит всю цитату, как и правка через API
+		revisions, err := quotes.QuoteRevisions(ctx, id)
+		require.NoError(t, err)
+		rev := (*revisions)[len(*revisions)-1]
+		require.Equal(t, "New", rev.Author)
+		require.Equal(t, "en", rev.Lang)
+		require.Equal(t, &weight, rev.Weight)
+		require.Equal(t, source, rev.Source)
+	})
+
+	t.Run("FailOnDuplicates", func(t *testing.T) {
+		clearTable(t)
+		_, err := quotes.CreateQuote(ctx, &models.Quote{Author: "Old", Quote: "Existing"})
+		require.NoError(t, err)
+
+		counts, err := repo.ImportQuotes(ctx, &models.ImportOptions{OnDuplicate: models.DuplicateFail}, batches(
+			[]models.ImportRow{{Row: 1, Author: "A", Quote: "Fresh"}, {Row: 2, Author: "B", Quote: "Existing"}},
+		))
+		require.ErrorIs(t, err, errdefs.ErrConflict)
+		require.Equal(t, []int{2}, counts.DuplicateRows)
+
+		all, err := quotes.QuotesAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, *all, 1)
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		clearTable(t)
+
+		counts, err := repo.ImportQuotes(ctx, &models.ImportOptions{OnDuplicate: models.DuplicateSkip, DryRun: true}, batches(
+			[]models.ImportRow{{Row: 1, Author: "A", Quote: "One"}},
+		))
+		require.NoError(t, err)
+		require.Equal(t, 1, counts.Inserted)
+
+		all, err := quotes.QuotesAll(ctx)
+		require.NoError(t, err)
+		require.Empty(t, *all)
+	})
+
+	t.Run("Jobs", func(t *testing.T) {
+		job := &models.ImportJob{ID: "7f0c6c52-8d56-4f4e-9a51-0d3a1c4b2e11", Status: models.ImportPending, CreatedBy: "alice"}
+		_, err := db.Exec(ctx, `DELETE FROM import_jobs WHERE id = $1`, job.ID)
+		require.NoError(t, err)
+		require.NoError(t, repo.CreateImportJob(ctx, job))
+
+		job.Status = models.ImportDone
+		job.Result = &models.ImportResult{Total: 3, Errors: []models.ImportRowError{}}
+		require.NoError(t, repo.UpdateImportJob(ctx, job))
+
+		got, err := repo.GetImportJob(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, models.ImportDone, got.Status)
+		require.Equal(t, 3, got.Result.Total)
+
+		_, err = repo.GetImportJob(ctx, "00000000-0000-0000-0000-000000000000")
+		require.ErrorIs(t, err, errdefs.ErrNotFound)
+	})
+}
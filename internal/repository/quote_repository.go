@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"quotebook/config"
 	"quotebook/internal/errdefs"
@@ -11,6 +12,16 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// колонки, которые читаются в models.Quote через scanQuote
+const quoteColumns = `id, author, quote, tags, lang, weight, source, attribution, attribution_note, favorites, rating_count,
+	COALESCE(round(rating_sum::numeric / NULLIF(rating_count, 0), 2), 0)::float8,
+	created_at, updated_at, version`
+
+// langMatch фильтр ?lang=: оригинал на языке $4 или перевод на него
+const langMatch = `lang = $4 OR EXISTS (
+	SELECT 1 FROM quote_translations t WHERE t.quote_id = quotesbook.id AND t.lang = $4
+)`
+
 type QuoteRepository struct {
 	db  *pgxpool.Pool
 	cfg *config.Config
@@ -23,112 +34,483 @@ func NewQuoteRepository(db *pgxpool.Pool, cfg *config.Config) QuoteRepository {
 	}
 }
 
+// quoteFields адреса полей q в порядке quoteColumns
+func quoteFields(q *models.Quote) []any {
+	return []any{&q.ID, &q.Author, &q.Quote, &q.Tags, &q.Lang, &q.Weight, &q.Source, &q.Attribution, &q.AttributionNote, &q.Favorites, &q.RatingCount, &q.RatingAvg,
+		&q.CreatedAt, &q.UpdatedAt, &q.Version}
+}
+
+func scanQuote(row pgx.Row, q *models.Quote) error {
+	return row.Scan(quoteFields(q)...)
+}
+
+func collectQuotes(rows pgx.Rows) (*[]models.Quote, error) {
+	defer rows.Close()
+
+	var quotes []models.Quote
+	for rows.Next() {
+		var quote models.Quote
+		if err := scanQuote(rows, &quote); err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan quote: %v", err)
+		}
+		quotes = append(quotes, quote)
+	}
+
+	if rows.Err() != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "rows iteration error: %v", rows.Err())
+	}
+
+	return &quotes, nil
+}
+
+// withTx выполняет fn в транзакции, при ошибке всё откатывается
+func (qr QuoteRepository) withTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := qr.db.Begin(ctx)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to commit tx: %v", err)
+	}
+	return nil
+}
+
 func (qr QuoteRepository) CreateQuote(ctx context.Context, q *models.Quote) (int, error) {
 	query := `
  		INSERT INTO quotesbook (
- 			author, quote
- 		) VALUES ($1, $2)
+ 			author, quote, tags, weight, source, lang
+ 		) VALUES ($1, $2, COALESCE($3::text[], '{}'), COALESCE($4::float8, 1), $5::jsonb, COALESCE(NULLIF($6, ''), 'und'))
  		RETURNING id
 	`
 	var id int
-    err := qr.db.QueryRow(ctx, query,
-        q.Author,
-        q.Quote,
-    ).Scan(&id)
-    if err != nil {
-        return 0, errdefs.Wrapf(errdefs.ErrDB, "failed to create quote: %v", err)
-    }
+	err := qr.withTx(ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query,
+			q.Author,
+			q.Quote,
+			q.Tags,
+			q.Weight,
+			q.Source,
+			q.Lang,
+		).Scan(&id)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to create quote: %v", err)
+		}
+		return recordRevision(ctx, tx, id, models.ActionCreate)
+	})
+	if err != nil {
+		return 0, err
+	}
 	return id, nil
 }
 
 func (qr QuoteRepository) QuotesAll(ctx context.Context) (*[]models.Quote, error) {
 	query := `
-		SELECT id, author, quote, created_at
+		SELECT ` + quoteColumns + `
 		FROM quotesbook
+		WHERE deleted_at IS NULL
 	`
 	rows, err := qr.db.Query(ctx, query)
 	if err != nil {
 		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to list quotes: %v", err)
 	}
+	return collectQuotes(rows)
+}
+
+// QuoteByAuthor цитаты автора; имя сравнивается без учёта регистра
+func (qr QuoteRepository) QuoteByAuthor(ctx context.Context, author string) (*[]models.Quote, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotesbook
+		WHERE lower(author) = lower($1) AND deleted_at IS NULL
+		ORDER BY id
+	`
+
+	rows, err := qr.db.Query(ctx, query, author)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to query quotes by author: %v", err)
+	}
+	return collectQuotes(rows)
+}
+
+// StreamQuotes читает строки курсором pgx и отдаёт их fn по одной,
+// вся выборка в памяти не держится
+func (qr QuoteRepository) StreamQuotes(ctx context.Context, f *models.QuoteFilter, fn func(q *models.Quote) error) error {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotesbook
+		WHERE deleted_at IS NULL AND ($1::text = '' OR lower(author) = lower($1))
+			AND ($2::text = '' OR tags @> ARRAY[$2::text])
+			AND NOT ($3::bool AND attribution = 'misattributed')
+			AND ($4::text = '' OR ` + langMatch + `)
+		ORDER BY id
+	`
+
+	rows, err := qr.db.Query(ctx, query, f.Author, f.Tag, f.HideMisattributed, f.Lang)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to stream quotes: %v", err)
+	}
 	defer rows.Close()
 
-	var quotes []models.Quote
-    for rows.Next() {
-        var quote models.Quote
-        if err := rows.Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.CreatedAt); err != nil {
-            return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan quote: %v", err)
-        }
-        quotes = append(quotes, quote)
-    }
+	for rows.Next() {
+		var quote models.Quote
+		if err := scanQuote(rows, &quote); err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to scan quote: %v", err)
+		}
+		if err := fn(&quote); err != nil {
+			return err
+		}
+	}
 
 	if rows.Err() != nil {
-		return nil, errdefs.Wrapf(errdefs.ErrDB, "rows iteration error: %v", rows.Err())
+		return errdefs.Wrapf(errdefs.ErrDB, "rows iteration error: %v", rows.Err())
+	}
+	return nil
+}
+
+// RecentQuotes последние добавленные цитаты, новые первыми
+func (qr QuoteRepository) RecentQuotes(ctx context.Context, f *models.QuoteFilter, limit int) (*[]models.Quote, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotesbook
+		WHERE deleted_at IS NULL AND ($1::text = '' OR lower(author) = lower($1))
+			AND ($2::text = '' OR tags @> ARRAY[$2::text])
+			AND NOT ($3::bool AND attribution = 'misattributed')
+			AND ($4::text = '' OR ` + langMatch + `)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $5
+	`
+
+	rows, err := qr.db.Query(ctx, query, f.Author, f.Tag, f.HideMisattributed, f.Lang, limit)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to list recent quotes: %v", err)
+	}
+	return collectQuotes(rows)
+}
+
+func (qr QuoteRepository) GetQuote(ctx context.Context, id int) (*models.Quote, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotesbook
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	var quote models.Quote
+	err := scanQuote(qr.db.QueryRow(ctx, query, id), &quote)
+	if err != nil {
+		if errdefs.Is(err, pgx.ErrNoRows) {
+			return nil, errdefs.ErrNotFound
+		}
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to fetch quote %d: %v", id, err)
+	}
+	return &quote, nil
+}
+
+// QuotesByIDs возвращает найденные цитаты в произвольном порядке
+func (qr QuoteRepository) QuotesByIDs(ctx context.Context, ids []int) (*[]models.Quote, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotesbook
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`
+
+	rows, err := qr.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to query quotes by ids: %v", err)
 	}
+	return collectQuotes(rows)
+}
 
+// QuotesChangedSince цитаты, изменённые позже since, включая удалённые в
+// корзину (у них заполнен DeletedAt), в порядке updated_at
+func (qr QuoteRepository) QuotesChangedSince(ctx context.Context, since time.Time) (*[]models.Quote, error) {
+	query := `
+		SELECT ` + quoteColumns + `, deleted_at
+		FROM quotesbook
+		WHERE updated_at > $1
+		ORDER BY updated_at, id
+	`
+	rows, err := qr.db.Query(ctx, query, since)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to query changed quotes: %v", err)
+	}
+	quotes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Quote, error) {
+		var q models.Quote
+		err := row.Scan(append(quoteFields(&q), &q.DeletedAt)...)
+		return q, err
+	})
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan changed quote: %v", err)
+	}
 	return &quotes, nil
 }
 
-func (qr QuoteRepository) QuoteByAuthor(ctx context.Context, author string) (*[]models.Quote, error) {
-    query := `
-        SELECT id, author, quote, created_at
-        FROM quotesbook
-        WHERE author = $1
-    `
+// QuotesStamp считает живые цитаты и время последнего изменения любой строки,
+// включая удаление в корзину и изменение счётчиков избранного и оценок
+func (qr QuoteRepository) QuotesStamp(ctx context.Context) (*models.QuotesStamp, error) {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE deleted_at IS NULL),
+			COALESCE(GREATEST(MAX(updated_at), MAX(engaged_at)), 'epoch'::timestamptz)
+		FROM quotesbook
+	`
 
-	rows, err := qr.db.Query(ctx, query, author)
-    if err != nil {
-        return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to query quotes by author: %v", err)
-    }
-    defer rows.Close()
+	var stamp models.QuotesStamp
+	err := qr.db.QueryRow(ctx, query).Scan(&stamp.Count, &stamp.LastModified)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to stamp quotes: %v", err)
+	}
+	return &stamp, nil
+}
+
+func (qr QuoteRepository) RandQuote(ctx context.Context) (*models.Quote, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotesbook
+		WHERE deleted_at IS NULL
+		ORDER BY RANDOM()
+		LIMIT 1
+	`
+
+	var quote models.Quote
+	err := scanQuote(qr.db.QueryRow(ctx, query), &quote)
+	if err != nil {
+		if errdefs.Is(err, pgx.ErrNoRows) {
+			return nil, errdefs.ErrNotFound
+		}
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to fetch random quote: %v", err)
+	}
+	return &quote, nil
+}
+
+// RandQuoteByLang случайная цитата на языке lang или с переводом на него
+func (qr QuoteRepository) RandQuoteByLang(ctx context.Context, lang string) (*models.Quote, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotesbook
+		WHERE deleted_at IS NULL AND (lang = $1 OR EXISTS (
+			SELECT 1 FROM quote_translations t WHERE t.quote_id = quotesbook.id AND t.lang = $1
+		))
+		ORDER BY RANDOM()
+		LIMIT 1
+	`
+
+	var quote models.Quote
+	err := scanQuote(qr.db.QueryRow(ctx, query, lang), &quote)
+	if err != nil {
+		if errdefs.Is(err, pgx.ErrNoRows) {
+			return nil, errdefs.ErrNotFound
+		}
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to fetch random quote in %q: %v", lang, err)
+	}
+	return &quote, nil
+}
+
+// порядок GET /quotes?sort=, значения проверяет сервис
+var quoteOrders = map[string]string{
+	models.SortPopular: `favorites DESC, id`,
+	models.SortRating:  `rating_sum::float8 / NULLIF(rating_count, 0) DESC NULLS LAST, rating_count DESC, id`,
+	models.SortRecent:  `created_at DESC, id DESC`,
+}
+
+// SortedQuotes живые цитаты в порядке sort
+func (qr QuoteRepository) SortedQuotes(ctx context.Context, sort string) (*[]models.Quote, error) {
+	order, ok := quoteOrders[sort]
+	if !ok {
+		return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "unknown sort %q", sort)
+	}
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotesbook
+		WHERE deleted_at IS NULL
+		ORDER BY ` + order
+
+	rows, err := qr.db.Query(ctx, query)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to list sorted quotes: %v", err)
+	}
+	return collectQuotes(rows)
+}
+
+// QuoteWeights веса всех живых цитат; читается целиком, но редко —
+// по этому снимку сервис строит таблицы для случайного выбора
+func (qr QuoteRepository) QuoteWeights(ctx context.Context) (*[]models.QuoteWeight, error) {
+	query := `
+		SELECT id, weight, rating_sum, rating_count, views, created_at
+		FROM quotesbook
+		WHERE deleted_at IS NULL
+	`
+	rows, err := qr.db.Query(ctx, query)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to query quote weights: %v", err)
+	}
+	defer rows.Close()
+
+	weights := []models.QuoteWeight{}
+	for rows.Next() {
+		var w models.QuoteWeight
+		if err := rows.Scan(&w.ID, &w.Weight, &w.RatingSum, &w.RatingCount, &w.Views, &w.CreatedAt); err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan quote weight: %v", err)
+		}
+		weights = append(weights, w)
+	}
+	if rows.Err() != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "rows iteration error: %v", rows.Err())
+	}
+	return &weights, nil
+}
+
+// missedUpdate объясняет, почему условный UPDATE не затронул строку:
+// цитаты нет или её версия уже другая
+func missedUpdate(ctx context.Context, tx pgx.Tx, id int) error {
+	var version int
+	err := tx.QueryRow(ctx, `SELECT version FROM quotesbook WHERE id = $1 AND deleted_at IS NULL`, id).Scan(&version)
+	if err != nil {
+		if errdefs.Is(err, pgx.ErrNoRows) {
+			return errdefs.ErrNotFound
+		}
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to check quote %d: %v", id, err)
+	}
+	return errdefs.Wrapf(errdefs.ErrPreconditionFailed, "quote %d is at version %d", id, version)
+}
+
+// UpdateQuote заменяет цитату целиком: незаданные теги, вес, источник и язык
+// получают те же значения, что при создании. Если q.Version не 0, изменение
+// проходит только при совпадении версии; новая версия пишется в q.Version.
+func (qr QuoteRepository) UpdateQuote(ctx context.Context, q *models.Quote) error {
+	query := `
+		UPDATE quotesbook
+		SET author = $2, quote = $3, tags = COALESCE($5::text[], '{}'),
+			weight = COALESCE($6::float8, 1), source = $7::jsonb,
+			lang = COALESCE(NULLIF($8, ''), 'und'), updated_at = now(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($4 = 0 OR version = $4)
+		RETURNING version
+	`
+
+	return qr.withTx(ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query, q.ID, q.Author, q.Quote, q.Version, q.Tags, q.Weight, q.Source, q.Lang).Scan(&q.Version)
+		if err != nil {
+			if errdefs.Is(err, pgx.ErrNoRows) {
+				return missedUpdate(ctx, tx, q.ID)
+			}
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to update quote %d: %v", q.ID, err)
+		}
+		return recordRevision(ctx, tx, q.ID, models.ActionUpdate)
+	})
+}
+
+// PatchQuote меняет только заданные поля, version как в UpdateQuote. Источник
+// меняется, если p.Source.Set, и снимается, если при этом Value nil.
+func (qr QuoteRepository) PatchQuote(ctx context.Context, id, version int, p *models.QuotePatch) (*models.Quote, error) {
+	query := `
+		UPDATE quotesbook
+		SET author = COALESCE($2, author), quote = COALESCE($3, quote),
+			tags = COALESCE($5::text[], tags), weight = COALESCE($6::float8, weight),
+			source = CASE WHEN $9 THEN $7::jsonb ELSE source END, lang = COALESCE($8, lang),
+			updated_at = now(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($4 = 0 OR version = $4)
+		RETURNING ` + quoteColumns
+
+	var quote models.Quote
+	err := qr.withTx(ctx, func(tx pgx.Tx) error {
+		err := scanQuote(tx.QueryRow(ctx, query, id, p.Author, p.Quote, version, p.Tags, p.Weight, p.Source.Value, p.Lang, p.Source.Set), &quote)
+		if err != nil {
+			if errdefs.Is(err, pgx.ErrNoRows) {
+				return missedUpdate(ctx, tx, id)
+			}
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to patch quote %d: %v", id, err)
+		}
+		return recordRevision(ctx, tx, id, models.ActionUpdate)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// DeleteQuote переносит цитату в корзину, version как в UpdateQuote
+func (qr QuoteRepository) DeleteQuote(ctx context.Context, id, version int) error {
+	// жёсткого удаления нет, строка уходит в корзину
+	query := `
+		UPDATE quotesbook
+		SET deleted_at = now(), updated_at = now(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)
+	`
+
+	return qr.withTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, id, version)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to delete quote %d: %v", id, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return missedUpdate(ctx, tx, id)
+		}
+		return recordRevision(ctx, tx, id, models.ActionDelete)
+	})
+}
+
+func (qr QuoteRepository) TrashQuotes(ctx context.Context) (*[]models.Quote, error) {
+	query := `
+		SELECT ` + quoteColumns + `, deleted_at
+		FROM quotesbook
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`
+	rows, err := qr.db.Query(ctx, query)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to list trash: %v", err)
+	}
+	defer rows.Close()
 
 	var quotes []models.Quote
-    for rows.Next() {
-        var quote models.Quote
-        if err := rows.Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.CreatedAt); err != nil {
-            return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan quote: %v", err)
-        }
-        quotes = append(quotes, quote)
-    }
-
-    if rows.Err() != nil {
-        return nil, errdefs.Wrapf(errdefs.ErrDB, "rows iteration error: %v", rows.Err())
-    }
+	for rows.Next() {
+		var q models.Quote
+		if err := rows.Scan(append(quoteFields(&q), &q.DeletedAt)...); err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan quote: %v", err)
+		}
+		quotes = append(quotes, q)
+	}
+
+	if rows.Err() != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "rows iteration error: %v", rows.Err())
+	}
 
 	return &quotes, nil
 }
 
-func (qr QuoteRepository) RandQuote(ctx context.Context) (*models.Quote, error) {
+func (qr QuoteRepository) RestoreQuote(ctx context.Context, id int) error {
 	query := `
-        SELECT id, author, quote, created_at
-        FROM quotesbook
-        ORDER BY RANDOM()
-        LIMIT 1
-    `
-
-    var quote models.Quote
-    err := qr.db.QueryRow(ctx, query).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.CreatedAt)
-    if err != nil {
-        if errdefs.Is(err, pgx.ErrNoRows) {
-		    return nil, errdefs.ErrNotFound
-		}
-        return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to fetch random quote: %v", err)
-    }
-    return &quote, nil
-}
-
-func (qr QuoteRepository) DeleteQuote(ctx context.Context, id int) error {
-    query := `
-        DELETE FROM quotesbook
-        WHERE id = $1
-    `
-
-	tag, err := qr.db.Exec(ctx, query, id)
-    if err != nil {
-        return errdefs.Wrapf(errdefs.ErrDB, "failed to delete quote %d: %v", id, err)
-    }
-
-    if tag.RowsAffected() == 0 {
-        return errdefs.ErrNotFound
-    }
-    return nil
-}
\ No newline at end of file
+		UPDATE quotesbook
+		SET deleted_at = NULL, updated_at = now(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	return qr.withTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, id)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to restore quote %d: %v", id, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return errdefs.ErrNotFound
+		}
+		return recordRevision(ctx, tx, id, models.ActionRestore)
+	})
+}
+
+// PurgeQuotes окончательно удаляет цитаты, попавшие в корзину раньше before
+func (qr QuoteRepository) PurgeQuotes(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM quotesbook
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+	`
+
+	tag, err := qr.db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, errdefs.Wrapf(errdefs.ErrDB, "failed to purge trash: %v", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -48,7 +49,7 @@ func TestMain(m *testing.M) {
 
 func clearTable(t *testing.T) {
 	ctx := context.Background()
-	_, err := db.Exec(ctx, fmt.Sprintf("TRUNCATE TABLE %s.quotesbook RESTART IDENTITY CASCADE", cfg.DB.Schema))
+	_, err := db.Exec(ctx, fmt.Sprintf("TRUNCATE TABLE %[1]s.quotesbook, %[1]s.quote_revisions, %[1]s.authors RESTART IDENTITY CASCADE", cfg.DB.Schema))
 	require.NoError(t, err, "Failed to clear quotesbook table")
 }
 
@@ -78,7 +79,7 @@ func TestQuoteRepository(t *testing.T) {
 		require.Equal(t, quote.Quote, (*allQuotes)[0].Quote)
 
 		// Delete
-		err = repo.DeleteQuote(ctx, id)
+		err = repo.DeleteQuote(ctx, id, 0)
 		require.NoError(t, err, "Error when deleting quote")
 
 		allQuotesAfter, err := repo.QuotesAll(ctx)
@@ -154,8 +155,442 @@ func TestQuoteRepository(t *testing.T) {
 	t.Run("DeleteNotFound", func(t *testing.T) {
 		clearTable(t)
 
-		err := repo.DeleteQuote(ctx, 9999)
+		err := repo.DeleteQuote(ctx, 9999, 0)
 		require.Error(t, err, "Expected an error when deleting non-existent ID")
 		require.Equal(t, errdefs.ErrNotFound, err, "Expected ErrNotFound")
 	})
+
+	t.Run("SoftDeleteRestore", func(t *testing.T) {
+		clearTable(t)
+
+		quote := &models.Quote{
+			Author: "TrashAuthor",
+			Quote:  "Deleted by mistake",
+		}
+		id, err := repo.CreateQuote(ctx, quote)
+		require.NoError(t, err)
+
+		require.NoError(t, repo.DeleteQuote(ctx, id, 0))
+
+		// удалённая цитата не видна в обычных выборках
+		all, err := repo.QuotesAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, *all, 0)
+		byAuthor, err := repo.QuoteByAuthor(ctx, "TrashAuthor")
+		require.NoError(t, err)
+		require.Len(t, *byAuthor, 0)
+		_, err = repo.RandQuote(ctx)
+		require.Equal(t, errdefs.ErrNotFound, err)
+
+		// повторное удаление → ErrNotFound
+		require.Equal(t, errdefs.ErrNotFound, repo.DeleteQuote(ctx, id, 0))
+
+		trash, err := repo.TrashQuotes(ctx)
+		require.NoError(t, err)
+		require.Len(t, *trash, 1)
+		require.Equal(t, id, (*trash)[0].ID)
+		require.NotNil(t, (*trash)[0].DeletedAt)
+
+		require.NoError(t, repo.RestoreQuote(ctx, id))
+		require.Equal(t, errdefs.ErrNotFound, repo.RestoreQuote(ctx, id))
+
+		all, err = repo.QuotesAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, *all, 1)
+	})
+
+	t.Run("PurgeQuotes", func(t *testing.T) {
+		clearTable(t)
+
+		id, err := repo.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "old"})
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteQuote(ctx, id, 0))
+
+		// ещё не истёк срок хранения
+		purged, err := repo.PurgeQuotes(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, int64(0), purged)
+
+		purged, err = repo.PurgeQuotes(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), purged)
+
+		trash, err := repo.TrashQuotes(ctx)
+		require.NoError(t, err)
+		require.Len(t, *trash, 0)
+	})
+
+	t.Run("RevisionsAndRevert", func(t *testing.T) {
+		clearTable(t)
+
+		id, err := repo.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "first"})
+		require.NoError(t, err)
+		require.NoError(t, repo.UpdateQuote(ctx, &models.Quote{ID: id, Author: "B", Quote: "second"}))
+
+		err = repo.UpdateQuote(ctx, &models.Quote{ID: 9999, Author: "B", Quote: "none"})
+		require.Equal(t, errdefs.ErrNotFound, err)
+
+		revisions, err := repo.QuoteRevisions(ctx, id)
+		require.NoError(t, err)
+		require.Len(t, *revisions, 2)
+		require.Equal(t, models.ActionCreate, (*revisions)[0].Action)
+		require.Equal(t, "first", (*revisions)[0].Quote)
+		require.Equal(t, models.ActionUpdate, (*revisions)[1].Action)
+		require.Equal(t, 2, (*revisions)[1].Rev)
+
+		require.NoError(t, repo.RevertQuote(ctx, id, 1))
+		all, err := repo.QuotesAll(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "A", (*all)[0].Author)
+		require.Equal(t, "first", (*all)[0].Quote)
+
+		rev, err := repo.QuoteRevision(ctx, id, 3)
+		require.NoError(t, err)
+		require.Equal(t, models.ActionRevert, rev.Action)
+
+		_, err = repo.QuoteRevision(ctx, id, 42)
+		require.Equal(t, errdefs.ErrNotFound, err)
+		require.Equal(t, errdefs.ErrNotFound, repo.RevertQuote(ctx, id, 42))
+
+		// источник, вес и язык тоже в истории и откатываются
+		weight := 3.0
+		source := &models.Source{Type: models.SourceBook, Title: "Analects"}
+		patched, err := repo.PatchQuote(ctx, id, 0, &models.QuotePatch{Source: models.Some(source), Weight: &weight})
+		require.NoError(t, err)
+		require.Equal(t, 4, patched.Version)
+		rev, err = repo.QuoteRevision(ctx, id, 4)
+		require.NoError(t, err)
+		require.Equal(t, source, rev.Source)
+		require.Equal(t, &weight, rev.Weight)
+		require.Equal(t, "und", rev.Lang)
+
+		require.NoError(t, repo.RevertQuote(ctx, id, 3))
+		reverted, err := repo.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Nil(t, reverted.Source)
+		require.Equal(t, 1.0, *reverted.Weight)
+
+		// удаление тоже попадает в историю
+		require.NoError(t, repo.DeleteQuote(ctx, id, 0))
+		revisions, err = repo.QuoteRevisions(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, models.ActionDelete, (*revisions)[len(*revisions)-1].Action)
+	})
+
+	t.Run("OptimisticConcurrency", func(t *testing.T) {
+		clearTable(t)
+
+		id, err := repo.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "v1"})
+		require.NoError(t, err)
+
+		q := &models.Quote{ID: id, Author: "A", Quote: "v2", Version: 1}
+		require.NoError(t, repo.UpdateQuote(ctx, q))
+		require.Equal(t, 2, q.Version)
+
+		// второй редактор всё ещё держит версию 1
+		stale := &models.Quote{ID: id, Author: "B", Quote: "lost update", Version: 1}
+		require.ErrorIs(t, repo.UpdateQuote(ctx, stale), errdefs.ErrPreconditionFailed)
+
+		text := "v3"
+		_, err = repo.PatchQuote(ctx, id, 1, &models.QuotePatch{Quote: &text})
+		require.ErrorIs(t, err, errdefs.ErrPreconditionFailed)
+		patched, err := repo.PatchQuote(ctx, id, 2, &models.QuotePatch{Quote: &text})
+		require.NoError(t, err)
+		require.Equal(t, "A", patched.Author)
+		require.Equal(t, "v3", patched.Quote)
+		require.Equal(t, 3, patched.Version)
+
+		require.ErrorIs(t, repo.DeleteQuote(ctx, id, 2), errdefs.ErrPreconditionFailed)
+		require.NoError(t, repo.DeleteQuote(ctx, id, 3))
+		require.Equal(t, errdefs.ErrNotFound, repo.DeleteQuote(ctx, id, 4))
+	})
+
+	t.Run("QuotesStamp", func(t *testing.T) {
+		clearTable(t)
+
+		empty, err := repo.QuotesStamp(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 0, empty.Count)
+
+		id, err := repo.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "q"})
+		require.NoError(t, err)
+		created, err := repo.QuotesStamp(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, created.Count)
+		require.True(t, created.LastModified.After(empty.LastModified))
+
+		// удаление меняет и число, и время
+		require.NoError(t, repo.DeleteQuote(ctx, id, 0))
+		deleted, err := repo.QuotesStamp(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 0, deleted.Count)
+		require.False(t, deleted.LastModified.Before(created.LastModified))
+	})
+
+	t.Run("GetQuoteAndByIDs", func(t *testing.T) {
+		clearTable(t)
+
+		id1, err := repo.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "one"})
+		require.NoError(t, err)
+		id2, err := repo.CreateQuote(ctx, &models.Quote{Author: "B", Quote: "two"})
+		require.NoError(t, err)
+
+		got, err := repo.GetQuote(ctx, id1)
+		require.NoError(t, err)
+		require.Equal(t, "one", got.Quote)
+		require.Equal(t, 1, got.Version)
+
+		_, err = repo.GetQuote(ctx, 9999)
+		require.Equal(t, errdefs.ErrNotFound, err)
+
+		// удалённые не отдаются ни по одному, ни пачкой
+		require.NoError(t, repo.DeleteQuote(ctx, id2, 0))
+		_, err = repo.GetQuote(ctx, id2)
+		require.Equal(t, errdefs.ErrNotFound, err)
+
+		batch, err := repo.QuotesByIDs(ctx, []int{id1, id2, 9999})
+		require.NoError(t, err)
+		require.Len(t, *batch, 1)
+		require.Equal(t, id1, (*batch)[0].ID)
+	})
+	t.Run("StreamQuotes", func(t *testing.T) {
+		clearTable(t)
+
+		for _, q := range []models.Quote{{Author: "A", Quote: "one"}, {Author: "B", Quote: "two"}, {Author: "A", Quote: "three"}} {
+			_, err := repo.CreateQuote(ctx, &q)
+			require.NoError(t, err)
+		}
+
+		var got []string
+		err := repo.StreamQuotes(ctx, &models.QuoteFilter{Author: "A"}, func(q *models.Quote) error {
+			got = append(got, q.Quote)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"one", "three"}, got)
+
+		// ошибка из fn прерывает выгрузку
+		stop := errors.New("stop")
+		calls := 0
+		err = repo.StreamQuotes(ctx, &models.QuoteFilter{}, func(q *models.Quote) error {
+			calls++
+			return stop
+		})
+		require.ErrorIs(t, err, stop)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("TagsAndRecentQuotes", func(t *testing.T) {
+		clearTable(t)
+
+		id, err := repo.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "one", Tags: []string{"life"}})
+		require.NoError(t, err)
+		_, err = repo.CreateQuote(ctx, &models.Quote{Author: "B", Quote: "two"})
+		require.NoError(t, err)
+		_, err = repo.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "three", Tags: []string{"life", "war"}})
+		require.NoError(t, err)
+
+		recent, err := repo.RecentQuotes(ctx, &models.QuoteFilter{}, 2)
+		require.NoError(t, err)
+		require.Len(t, *recent, 2)
+		require.Equal(t, "three", (*recent)[0].Quote)
+		require.Equal(t, []string{}, (*recent)[1].Tags)
+
+		recent, err = repo.RecentQuotes(ctx, &models.QuoteFilter{Tag: "life"}, 10)
+		require.NoError(t, err)
+		require.Len(t, *recent, 2)
+
+		// PATCH без тегов их не трогает, с пустым списком снимает
+		author := "B"
+		q, err := repo.PatchQuote(ctx, id, 0, &models.QuotePatch{Author: &author})
+		require.NoError(t, err)
+		require.Equal(t, []string{"life"}, q.Tags)
+
+		empty := []string{}
+		q, err = repo.PatchQuote(ctx, id, 0, &models.QuotePatch{Tags: &empty})
+		require.NoError(t, err)
+		require.Empty(t, q.Tags)
+
+		// PUT заменяет цитату целиком: без тегов их нет
+		require.NoError(t, repo.RevertQuote(ctx, id, 1))
+		require.NoError(t, repo.UpdateQuote(ctx, &models.Quote{ID: id, Author: "A", Quote: "one!"}))
+		q, err = repo.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Empty(t, q.Tags)
+
+		require.NoError(t, repo.RevertQuote(ctx, id, 1))
+		q, err = repo.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, []string{"life"}, q.Tags)
+	})
+
+	t.Run("WeightsAndQuoteWeights", func(t *testing.T) {
+		clearTable(t)
+
+		id, err := repo.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "one"})
+		require.NoError(t, err)
+		heavy := 4.5
+		_, err = repo.CreateQuote(ctx, &models.Quote{Author: "B", Quote: "two", Weight: &heavy})
+		require.NoError(t, err)
+
+		q, err := repo.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, 1.0, *q.Weight)
+
+		// PATCH меняет только вес; PUT без веса возвращает 1, как при создании
+		zero := 0.0
+		require.NoError(t, repo.UpdateQuote(ctx, &models.Quote{ID: id, Author: "A", Quote: "one!", Weight: &heavy}))
+		require.NoError(t, repo.UpdateQuote(ctx, &models.Quote{ID: id, Author: "A", Quote: "one!"}))
+		q, err = repo.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, 1.0, *q.Weight)
+		_, err = repo.PatchQuote(ctx, id, 0, &models.QuotePatch{Weight: &zero})
+		require.NoError(t, err)
+
+		weights, err := repo.QuoteWeights(ctx)
+		require.NoError(t, err)
+		require.Len(t, *weights, 2)
+		byID := map[int]float64{}
+		for _, w := range *weights {
+			byID[w.ID] = w.Weight
+		}
+		require.Equal(t, 0.0, byID[id])
+		require.Equal(t, heavy, byID[id+1])
+	})
+	t.Run("Source", func(t *testing.T) {
+		clearTable(t)
+
+		src := &models.Source{Type: models.SourceBook, Title: "Analects", Year: 1998, ISBN: "9780140443486"}
+		id, err := repo.CreateQuote(ctx, &models.Quote{Author: "Confucius", Quote: "one", Source: src})
+		require.NoError(t, err)
+		plain, err := repo.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "two"})
+		require.NoError(t, err)
+
+		q, err := repo.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, src, q.Source)
+		q, err = repo.GetQuote(ctx, plain)
+		require.NoError(t, err)
+		require.Nil(t, q.Source)
+
+		// PATCH без source его не трогает, с source заменяет, с null снимает
+		author := "Kong Qiu"
+		patched, err := repo.PatchQuote(ctx, id, 0, &models.QuotePatch{Author: &author})
+		require.NoError(t, err)
+		require.Equal(t, src, patched.Source)
+		page := &models.Source{Type: models.SourceBook, Title: "Analects", Page: "12"}
+		patched, err = repo.PatchQuote(ctx, id, 0, &models.QuotePatch{Source: models.Some(page)})
+		require.NoError(t, err)
+		require.Equal(t, page, patched.Source)
+		patched, err = repo.PatchQuote(ctx, id, 0, &models.QuotePatch{Source: models.Some[models.Source](nil)})
+		require.NoError(t, err)
+		require.Nil(t, patched.Source)
+
+		// PUT заменяет цитату целиком: без source источник снимается, без
+		// lang язык становится und, как при создании
+		require.NoError(t, repo.UpdateQuote(ctx, &models.Quote{ID: id, Author: "Confucius", Quote: "one!", Source: page, Lang: "en"}))
+		q, err = repo.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, "en", q.Lang)
+		require.NoError(t, repo.UpdateQuote(ctx, &models.Quote{ID: id, Author: "Confucius", Quote: "one!"}))
+		q, err = repo.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Nil(t, q.Source)
+		require.Equal(t, "und", q.Lang)
+	})
+	t.Run("Authors", func(t *testing.T) {
+		clearTable(t)
+		for _, q := range []models.Quote{
+			{Author: "Albert Einstein", Quote: "one"},
+			{Author: "Albert Einstein", Quote: "two"},
+			{Author: "Albert Camus", Quote: "three"},
+			{Author: "Confucius", Quote: "four"},
+			{Author: "Ein_Weg", Quote: "five"},
+		} {
+			_, err := repo.CreateQuote(ctx, &q)
+			require.NoError(t, err)
+		}
+
+		quotes, err := repo.QuoteByAuthor(ctx, "confucius")
+		require.NoError(t, err)
+		require.Len(t, *quotes, 1)
+
+		// совпадение с начала имени раньше совпадения со словом, "_" — не шаблон
+		authors, err := repo.SuggestAuthors(ctx, "ein", 10)
+		require.NoError(t, err)
+		require.Equal(t, []models.AuthorSuggestion{
+			{Author: "Ein_Weg", Quotes: 1},
+			{Author: "Albert Einstein", Quotes: 2},
+		}, *authors)
+		authors, err = repo.SuggestAuthors(ctx, "ein_", 10)
+		require.NoError(t, err)
+		require.Len(t, *authors, 1)
+		authors, err = repo.SuggestAuthors(ctx, "al", 1)
+		require.NoError(t, err)
+		require.Equal(t, "Albert Einstein", (*authors)[0].Author)
+
+		quotes, err = repo.FuzzyQuotesByAuthor(ctx, "Konfucius", 0.5)
+		require.NoError(t, err)
+		require.Len(t, *quotes, 1)
+		require.Equal(t, "Confucius", (*quotes)[0].Author)
+		quotes, err = repo.FuzzyQuotesByAuthor(ctx, "Aynstein", 0.5)
+		require.NoError(t, err)
+		require.Empty(t, *quotes)
+
+		similar, err := repo.SimilarAuthors(ctx, "Albert Aynstein", 0.3, 10)
+		require.NoError(t, err)
+		require.NotEmpty(t, *similar)
+		require.Equal(t, "Albert Einstein", (*similar)[0].Author)
+		require.Greater(t, (*similar)[0].Similarity, 0.3)
+	})
+
+	t.Run("QuotesChangedSince", func(t *testing.T) {
+		clearTable(t)
+		kept, err := repo.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "kept"})
+		require.NoError(t, err)
+		gone, err := repo.CreateQuote(ctx, &models.Quote{Author: "B", Quote: "gone"})
+		require.NoError(t, err)
+
+		all, err := repo.QuotesChangedSince(ctx, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, *all, 2)
+		since := (*all)[1].UpdatedAt
+
+		require.NoError(t, repo.DeleteQuote(ctx, gone, 0))
+		changed, err := repo.QuotesChangedSince(ctx, since)
+		require.NoError(t, err)
+		require.Len(t, *changed, 1)
+		require.Equal(t, gone, (*changed)[0].ID)
+		require.NotNil(t, (*changed)[0].DeletedAt)
+
+		q, err := repo.GetQuote(ctx, kept)
+		require.NoError(t, err)
+		require.Nil(t, q.DeletedAt)
+	})
+
+	t.Run("AuthorDates", func(t *testing.T) {
+		clearTable(t)
+		for _, name := range []string{"Leo Tolstoy", "leo tolstoy", "Leo Tolstoy"} {
+			_, err := repo.CreateQuote(ctx, &models.Quote{Author: name, Quote: "q"})
+			require.NoError(t, err)
+		}
+
+		author, err := repo.GetAuthor(ctx, "LEO TOLSTOY")
+		require.NoError(t, err)
+		require.Equal(t, models.Author{Name: "Leo Tolstoy", Quotes: 3}, *author)
+		_, err = repo.GetAuthor(ctx, "Nobody")
+		require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+		author, err = repo.PutAuthor(ctx, &models.Author{Name: "leo tolstoy", Born: "1828-09-09", Died: "1910-11-20"})
+		require.NoError(t, err)
+		require.Equal(t, models.Author{Name: "Leo Tolstoy", Born: "1828-09-09", Died: "1910-11-20", Quotes: 3}, *author)
+
+		// пустая дата стирает прежнюю
+		author, err = repo.PutAuthor(ctx, &models.Author{Name: "Leo Tolstoy", Born: "1828-09-09"})
+		require.NoError(t, err)
+		require.Empty(t, author.Died)
+
+		_, err = repo.PutAuthor(ctx, &models.Author{Name: "Nobody", Born: "1900-01-01"})
+		require.ErrorIs(t, err, errdefs.ErrNotFound)
+	})
 }
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/identity"
+	"quotebook/internal/logger"
+	"quotebook/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const revisionColumns = `quote_id, rev, action, author, quote, tags, source, weight, COALESCE(lang, ''),
+	actor, request_id, created_at`
+
+func scanRevision(row pgx.Row, r *models.QuoteRevision) error {
+	return row.Scan(&r.QuoteID, &r.Rev, &r.Action, &r.Author, &r.Quote, &r.Tags, &r.Source, &r.Weight, &r.Lang,
+		&r.Actor, &r.RequestID, &r.CreatedAt)
+}
+
+// recordRevision сохраняет текущее состояние цитаты как очередную ревизию.
+// Вызывается в той же транзакции, что и само изменение: строка цитаты уже
+// заблокирована, поэтому номера ревизий не пересекаются.
+func recordRevision(ctx context.Context, tx pgx.Tx, id int, action string) error {
+	query := `
+		INSERT INTO quote_revisions (
+			quote_id, rev, action, author, quote, tags, source, weight, lang, actor, request_id
+		)
+		SELECT q.id,
+			COALESCE((SELECT MAX(rev) FROM quote_revisions WHERE quote_id = q.id), 0) + 1,
+			$2, q.author, q.quote, q.tags, COALESCE(q.source, 'null'::jsonb), q.weight, q.lang, $3, $4
+		FROM quotesbook q
+		WHERE q.id = $1
+	`
+
+	_, err := tx.Exec(ctx, query, id, action,
+		identity.ActorFromCtx(ctx),
+		logger.RequestIDFromCtx(ctx),
+	)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to record revision of quote %d: %v", id, err)
+	}
+	return nil
+}
+
+func (qr QuoteRepository) QuoteRevisions(ctx context.Context, id int) (*[]models.QuoteRevision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM quote_revisions
+		WHERE quote_id = $1
+		ORDER BY rev
+	`
+	rows, err := qr.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to list revisions of quote %d: %v", id, err)
+	}
+	defer rows.Close()
+
+	var revisions []models.QuoteRevision
+	for rows.Next() {
+		var rev models.QuoteRevision
+		if err := scanRevision(rows, &rev); err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan revision: %v", err)
+		}
+		revisions = append(revisions, rev)
+	}
+
+	if rows.Err() != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "rows iteration error: %v", rows.Err())
+	}
+
+	if len(revisions) == 0 {
+		return nil, errdefs.ErrNotFound
+	}
+	return &revisions, nil
+}
+
+func (qr QuoteRepository) QuoteRevision(ctx context.Context, id, rev int) (*models.QuoteRevision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM quote_revisions
+		WHERE quote_id = $1 AND rev = $2
+	`
+
+	var revision models.QuoteRevision
+	err := scanRevision(qr.db.QueryRow(ctx, query, id, rev), &revision)
+	if err != nil {
+		if errdefs.Is(err, pgx.ErrNoRows) {
+			return nil, errdefs.ErrNotFound
+		}
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to fetch revision %d of quote %d: %v", rev, id, err)
+	}
+	return &revision, nil
+}
+
+// RevertQuote возвращает цитате текст, автора, теги, источник, вес и язык из
+// ревизии rev. Источник, вес и язык, которых в старой ревизии нет, остаются.
+func (qr QuoteRepository) RevertQuote(ctx context.Context, id, rev int) error {
+	query := `
+		UPDATE quotesbook q
+		SET author = r.author, quote = r.quote, tags = r.tags,
+			source = CASE WHEN r.source IS NULL THEN q.source ELSE NULLIF(r.source, 'null'::jsonb) END,
+			weight = COALESCE(r.weight, q.weight), lang = COALESCE(r.lang, q.lang),
+			updated_at = now(), version = q.version + 1
+		FROM quote_revisions r
+		WHERE q.id = $1 AND q.deleted_at IS NULL
+			AND r.quote_id = q.id AND r.rev = $2
+	`
+
+	return qr.withTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, id, rev)
+		if err != nil {
+			return errdefs.Wrapf(errdefs.ErrDB, "failed to revert quote %d to revision %d: %v", id, rev, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return errdefs.ErrNotFound
+		}
+		return recordRevision(ctx, tx, id, models.ActionRevert)
+	})
+}
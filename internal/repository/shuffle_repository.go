@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"quotebook/config"
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ShuffleRepository struct {
+	db  *pgxpool.Pool
+	cfg *config.Config
+}
+
+func NewShuffleRepository(db *pgxpool.Pool, cfg *config.Config) ShuffleRepository {
+	return ShuffleRepository{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+func (sr ShuffleRepository) CreateShuffle(ctx context.Context, s *models.ShuffleSession) error {
+	_, err := sr.db.Exec(ctx, `
+		INSERT INTO shuffle_sessions (token, seed, size, snapshot_id, position, round)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, s.Token, s.Seed, s.Size, s.SnapshotID, s.Position, s.Round)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to create shuffle session: %v", err)
+	}
+	return nil
+}
+
+func (sr ShuffleRepository) GetShuffle(ctx context.Context, token string) (*models.ShuffleSession, error) {
+	s := models.ShuffleSession{Token: token}
+	err := sr.db.QueryRow(ctx, `
+		SELECT seed, size, COALESCE(snapshot_id, 0), position, round FROM shuffle_sessions WHERE token = $1
+	`, token).Scan(&s.Seed, &s.Size, &s.SnapshotID, &s.Position, &s.Round)
+	if err != nil {
+		if errdefs.Is(err, pgx.ErrNoRows) {
+			return nil, errdefs.ErrNotFound
+		}
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to get shuffle session: %v", err)
+	}
+	return &s, nil
+}
+
+// UpdateShuffle сохраняет s, только если обход всё ещё на позиции
+// fromPosition круга fromRound; иначе его уже продвинул параллельный запрос
+// и возвращается ErrPreconditionFailed
+func (sr ShuffleRepository) UpdateShuffle(ctx context.Context, s *models.ShuffleSession, fromPosition, fromRound int) error {
+	tag, err := sr.db.Exec(ctx, `
+		UPDATE shuffle_sessions
+		SET seed = $2, size = $3, snapshot_id = $4, position = $5, round = $6, updated_at = now()
+		WHERE token = $1 AND position = $7 AND round = $8
+	`, s.Token, s.Seed, s.Size, s.SnapshotID, s.Position, s.Round, fromPosition, fromRound)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to update shuffle session: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errdefs.Wrap(errdefs.ErrPreconditionFailed, "shuffle session moved on")
+	}
+	return nil
+}
+
+// ShuffleRound снимок для нового круга: живые сейчас цитаты с рангами по
+// порядку id. Если такой же набор цитат уже снят, снимок используется снова,
+// иначе снимается новый — это единственный запрос обхода, который читает
+// все цитаты. Size 0 — живых цитат нет.
+func (sr ShuffleRepository) ShuffleRound(ctx context.Context) (*models.ShuffleSession, error) {
+	var (
+		s           models.ShuffleSession
+		fingerprint string
+		snapshotID  *int64
+	)
+	err := sr.db.QueryRow(ctx, `
+		WITH live AS (
+			SELECT COUNT(*) AS size, md5(COALESCE(string_agg(id::text, ',' ORDER BY id), '')) AS fingerprint
+			FROM quotesbook
+			WHERE deleted_at IS NULL
+		)
+		SELECT live.size, live.fingerprint, snap.id
+		FROM live
+		LEFT JOIN LATERAL (
+			SELECT id FROM shuffle_snapshots
+			WHERE fingerprint = live.fingerprint AND size = live.size
+			ORDER BY id DESC
+			LIMIT 1
+		) snap ON true
+	`).Scan(&s.Size, &fingerprint, &snapshotID)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to fingerprint live quotes: %v", err)
+	}
+	if s.Size == 0 {
+		return &s, nil
+	}
+	if snapshotID != nil {
+		s.SnapshotID = *snapshotID
+		_, err := sr.db.Exec(ctx, `UPDATE shuffle_snapshots SET used_at = now() WHERE id = $1`, s.SnapshotID)
+		if err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to reuse shuffle snapshot: %v", err)
+		}
+		return &s, nil
+	}
+
+	tx, err := sr.db.Begin(ctx)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO shuffle_snapshots (fingerprint, size) VALUES ('', 0) RETURNING id
+	`).Scan(&s.SnapshotID)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to create shuffle snapshot: %v", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO shuffle_members (snapshot_id, rank, quote_id)
+		SELECT $1, row_number() OVER (ORDER BY id) - 1, id
+		FROM quotesbook
+		WHERE deleted_at IS NULL
+	`, s.SnapshotID)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to fill shuffle snapshot: %v", err)
+	}
+	// цитаты могли измениться после подсчёта, поэтому размер и отпечаток
+	// берутся из самого снимка
+	err = tx.QueryRow(ctx, `
+		UPDATE shuffle_snapshots
+		SET size = m.size, fingerprint = m.fingerprint
+		FROM (
+			SELECT COUNT(*) AS size, md5(COALESCE(string_agg(quote_id::text, ',' ORDER BY rank), '')) AS fingerprint
+			FROM shuffle_members
+			WHERE snapshot_id = $1
+		) m
+		WHERE id = $1
+		RETURNING shuffle_snapshots.size
+	`, s.SnapshotID).Scan(&s.Size)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to seal shuffle snapshot: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to commit tx: %v", err)
+	}
+	return &s, nil
+}
+
+// ShuffleQuotes живые сейчас цитаты с рангами ranks в снимке круга s, по
+// рангу. Цитаты, удалённые после начала круга, сохраняют ранг, но не отдаются.
+func (sr ShuffleRepository) ShuffleQuotes(ctx context.Context, s *models.ShuffleSession, ranks []int) (map[int]models.Quote, error) {
+	query := `
+		SELECT m.rank, ` + quoteColumns + `
+		FROM shuffle_members m
+		JOIN quotesbook ON quotesbook.id = m.quote_id
+		WHERE m.snapshot_id = $1 AND m.rank = ANY($2) AND quotesbook.deleted_at IS NULL
+	`
+	rows, err := sr.db.Query(ctx, query, s.SnapshotID, ranks)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to query shuffle quotes: %v", err)
+	}
+	defer rows.Close()
+
+	quotes := make(map[int]models.Quote, len(ranks))
+	for rows.Next() {
+		var (
+			rank int
+			q    models.Quote
+		)
+		if err := rows.Scan(append([]any{&rank}, quoteFields(&q)...)...); err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan shuffle quote: %v", err)
+		}
+		quotes[rank] = q
+	}
+	if rows.Err() != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "rows iteration error: %v", rows.Err())
+	}
+	return quotes, nil
+}
+
+// PurgeShuffles удаляет обходы, к которым не обращались с before, и снимки,
+// на которые больше не ссылается ни один обход
+func (sr ShuffleRepository) PurgeShuffles(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := sr.db.Exec(ctx, `DELETE FROM shuffle_sessions WHERE updated_at < $1`, before)
+	if err != nil {
+		return 0, errdefs.Wrapf(errdefs.ErrDB, "failed to purge shuffle sessions: %v", err)
+	}
+	_, err = sr.db.Exec(ctx, `
+		DELETE FROM shuffle_snapshots snap
+		WHERE used_at < $1
+			AND NOT EXISTS (SELECT 1 FROM shuffle_sessions WHERE snapshot_id = snap.id)
+	`, before)
+	if err != nil {
+		return 0, errdefs.Wrapf(errdefs.ErrDB, "failed to purge shuffle snapshots: %v", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+)
+
+func TestShuffleRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewShuffleRepository(db, cfg)
+	quotes := NewQuoteRepository(db, cfg)
+
+	t.Run("CompareAndSet", func(t *testing.T) {
+		s := &models.ShuffleSession{Token: uuid.NewString(), Seed: -42, Size: 10, SnapshotID: 12,
+			Position: 1, Round: 1}
+		require.NoError(t, repo.CreateShuffle(ctx, s))
+
+		got, err := repo.GetShuffle(ctx, s.Token)
+		require.NoError(t, err)
+		require.Equal(t, s, got)
+
+		next := *s
+		next.Position = 2
+		require.NoError(t, repo.UpdateShuffle(ctx, &next, 1, 1))
+		// тот же переход второй раз уже не проходит
+		require.ErrorIs(t, repo.UpdateShuffle(ctx, &next, 1, 1), errdefs.ErrPreconditionFailed)
+
+		_, err = repo.GetShuffle(ctx, uuid.NewString())
+		require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+		purged, err := repo.PurgeShuffles(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, purged, int64(1))
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		clearTable(t)
+		round, err := repo.ShuffleRound(ctx)
+		require.NoError(t, err)
+		require.Zero(t, round.Size)
+
+		var ids []int
+		for _, text := range []string{"one", "two", "three", "four"} {
+			id, err := quotes.CreateQuote(ctx, &models.Quote{Author: "A", Quote: text})
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+		// удалённая до начала круга в снимок не попадает
+		require.NoError(t, quotes.DeleteQuote(ctx, ids[1], 0))
+
+		round, err = repo.ShuffleRound(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 3, round.Size)
+		// тот же набор цитат — тот же снимок
+		again, err := repo.ShuffleRound(ctx)
+		require.NoError(t, err)
+		require.Equal(t, round.SnapshotID, again.SnapshotID)
+
+		got, err := repo.ShuffleQuotes(ctx, round, []int{0, 1, 2})
+		require.NoError(t, err)
+		require.Equal(t, ids[0], got[0].ID)
+		require.Equal(t, ids[2], got[1].ID)
+		require.Equal(t, ids[3], got[2].ID)
+
+		// восстановленная и новая ждут следующего круга, удалённая и стёртая
+		// пропускаются, ранги остальных не сдвигаются
+		require.NoError(t, quotes.RestoreQuote(ctx, ids[1]))
+		_, err = quotes.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "five"})
+		require.NoError(t, err)
+		require.NoError(t, quotes.DeleteQuote(ctx, ids[2], 0))
+		_, err = quotes.PurgeQuotes(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		got, err = repo.ShuffleQuotes(ctx, round, []int{0, 1, 2, 3})
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		require.Equal(t, ids[0], got[0].ID)
+		require.Equal(t, ids[3], got[2].ID)
+
+		next, err := repo.ShuffleRound(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 4, next.Size)
+		require.NotEqual(t, round.SnapshotID, next.SnapshotID)
+
+		// снимки без обходов удаляются вместе с обходами
+		_, err = repo.PurgeShuffles(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		got, err = repo.ShuffleQuotes(ctx, round, []int{0})
+		require.NoError(t, err)
+		require.Empty(t, got)
+	})
+}
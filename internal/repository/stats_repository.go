@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+
+	"quotebook/config"
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type StatsRepository struct {
+	db  *pgxpool.Pool
+	cfg *config.Config
+}
+
+func NewStatsRepository(db *pgxpool.Pool, cfg *config.Config) StatsRepository {
+	return StatsRepository{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// statCounts читает пары (ключ, число) в порядке запроса
+func statCounts(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]models.StatCount, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.StatCount, error) {
+		var c models.StatCount
+		err := row.Scan(&c.Key, &c.Count)
+		return c, err
+	})
+}
+
+// Stats считает сводку агрегатами по quotesbook. Запросы идут в одной
+// транзакции REPEATABLE READ, поэтому все части сводки видят один снимок.
+// interval — day, week или month (проверяет сервис), buckets последних
+// периодов, пустые тоже.
+func (sr StatsRepository) Stats(ctx context.Context, top int, interval string, buckets int) (*models.Stats, error) {
+	tx, err := sr.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	stats := &models.Stats{Interval: interval}
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(DISTINCT lower(author)),
+			COALESCE(round(AVG(char_length(quote)), 1), 0)::float8, now()
+		FROM quotesbook
+		WHERE deleted_at IS NULL
+	`).Scan(&stats.Quotes, &stats.Authors, &stats.AvgLength, &stats.GeneratedAt)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to count quotes: %v", err)
+	}
+
+	// автор без учёта регистра, как в ?author=; имя — самое частое написание
+	stats.TopAuthors, err = statCounts(ctx, tx, `
+		SELECT mode() WITHIN GROUP (ORDER BY author), COUNT(*)
+		FROM quotesbook
+		WHERE deleted_at IS NULL
+		GROUP BY lower(author)
+		ORDER BY COUNT(*) DESC, lower(author)
+		LIMIT $1
+	`, top)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to count quotes per author: %v", err)
+	}
+
+	stats.Tags, err = statCounts(ctx, tx, `
+		SELECT tag, COUNT(*)
+		FROM quotesbook, unnest(tags) AS tag
+		WHERE deleted_at IS NULL
+		GROUP BY tag
+		ORDER BY COUNT(*) DESC, tag
+		LIMIT $1
+	`, top)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to count quotes per tag: %v", err)
+	}
+
+	// языков немного, отдаются все
+	stats.Languages, err = statCounts(ctx, tx, `
+		SELECT lang, COUNT(*)
+		FROM quotesbook
+		WHERE deleted_at IS NULL
+		GROUP BY lang
+		ORDER BY COUNT(*) DESC, lang
+	`)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to count quotes per language: %v", err)
+	}
+
+	// периоды по часовому поясу сессии; диапазон created_at, а не date_trunc
+	// от каждой строки, чтобы работал индекс
+	rows, err := tx.Query(ctx, `
+		WITH periods AS (
+			SELECT p AS start, p + ('1 ' || $1)::interval AS finish
+			FROM generate_series(
+				date_trunc($1, now()) - ($2::int - 1) * ('1 ' || $1)::interval,
+				date_trunc($1, now()),
+				('1 ' || $1)::interval
+			) AS p
+		)
+		SELECT periods.start, COUNT(q.id)
+		FROM periods
+		LEFT JOIN quotesbook q ON q.deleted_at IS NULL
+			AND q.created_at >= periods.start AND q.created_at < periods.finish
+		GROUP BY periods.start
+		ORDER BY periods.start
+	`, interval, buckets)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to build creation histogram: %v", err)
+	}
+	stats.Created, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.StatBucket, error) {
+		var b models.StatBucket
+		err := row.Scan(&b.Start, &b.Count)
+		return b, err
+	})
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan creation histogram: %v", err)
+	}
+
+	rows, err = tx.Query(ctx, `
+		SELECT id, author, quote, served
+		FROM quotesbook
+		WHERE deleted_at IS NULL AND served > 0
+		ORDER BY served DESC, id
+		LIMIT $1
+	`, top)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to list most served quotes: %v", err)
+	}
+	stats.MostServed, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ServedQuote, error) {
+		var s models.ServedQuote
+		err := row.Scan(&s.ID, &s.Author, &s.Quote, &s.Served)
+		return s, err
+	})
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan most served quotes: %v", err)
+	}
+
+	return stats, nil
+}
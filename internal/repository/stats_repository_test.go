@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"quotebook/internal/models"
+)
+
+func TestStatsRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewStatsRepository(db, cfg)
+	quotes := NewQuoteRepository(db, cfg)
+	views := NewViewRepository(db, cfg)
+
+	t.Run("Empty", func(t *testing.T) {
+		clearTable(t)
+
+		stats, err := repo.Stats(ctx, 5, models.IntervalDay, 7)
+		require.NoError(t, err)
+		require.Zero(t, stats.Quotes)
+		require.Zero(t, stats.AvgLength)
+		require.Empty(t, stats.TopAuthors)
+		require.Empty(t, stats.MostServed)
+		// пустые периоды тоже отдаются
+		require.Len(t, stats.Created, 7)
+	})
+
+	t.Run("Aggregates", func(t *testing.T) {
+		clearTable(t)
+		for _, q := range []models.Quote{
+			{Author: "Confucius", Quote: "abcd", Tags: []string{"wisdom"}, Lang: "en"},
+			{Author: "confucius", Quote: "ab", Tags: []string{"wisdom", "life"}, Lang: "en"},
+			{Author: "Confucius", Quote: "abc", Lang: "en"},
+			{Author: "Seneca", Quote: "abcdef", Lang: "la"},
+		} {
+			_, err := quotes.CreateQuote(ctx, &q)
+			require.NoError(t, err)
+		}
+		deleted, err := quotes.CreateQuote(ctx, &models.Quote{Author: "Seneca", Quote: "gone"})
+		require.NoError(t, err)
+		require.NoError(t, quotes.DeleteQuote(ctx, deleted, 0))
+
+		hour := time.Now().Truncate(time.Hour)
+		require.NoError(t, views.AddViews(ctx, []models.ViewCount{
+			{QuoteID: 1, Hour: hour, Views: 3, Served: 1},
+			{QuoteID: 4, Hour: hour, Views: 2, Served: 2},
+		}))
+
+		stats, err := repo.Stats(ctx, 2, models.IntervalMonth, 3)
+		require.NoError(t, err)
+		require.Equal(t, 4, stats.Quotes)
+		require.Equal(t, 2, stats.Authors)
+		require.Equal(t, 3.8, stats.AvgLength)
+		require.Equal(t, []models.StatCount{{Key: "Confucius", Count: 3}, {Key: "Seneca", Count: 1}}, stats.TopAuthors)
+		require.Equal(t, []models.StatCount{{Key: "wisdom", Count: 2}, {Key: "life", Count: 1}}, stats.Tags)
+		require.Equal(t, []models.StatCount{{Key: "en", Count: 3}, {Key: "la", Count: 1}}, stats.Languages)
+		require.Len(t, stats.Created, 3)
+		require.Equal(t, 4, stats.Created[2].Count)
+		require.Equal(t, []models.ServedQuote{
+			{ID: 4, Author: "Seneca", Quote: "abcdef", Served: 2},
+			{ID: 1, Author: "Confucius", Quote: "abcd", Served: 1},
+		}, stats.MostServed)
+	})
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+
+	"quotebook/config"
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// колонки quote_translations, читаются через scanTranslation
+const translationColumns = `quote_id, lang, quote, translator, created_at, updated_at`
+
+type TranslationRepository struct {
+	db  *pgxpool.Pool
+	cfg *config.Config
+}
+
+func NewTranslationRepository(db *pgxpool.Pool, cfg *config.Config) TranslationRepository {
+	return TranslationRepository{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+func scanTranslation(row pgx.Row, t *models.Translation) error {
+	return row.Scan(&t.QuoteID, &t.Lang, &t.Quote, &t.Translator, &t.CreatedAt, &t.UpdatedAt)
+}
+
+func collectTranslations(rows pgx.Rows) (*[]models.Translation, error) {
+	defer rows.Close()
+
+	translations := []models.Translation{}
+	for rows.Next() {
+		var t models.Translation
+		if err := scanTranslation(rows, &t); err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan translation: %v", err)
+		}
+		translations = append(translations, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to iterate translations: %v", err)
+	}
+	return &translations, nil
+}
+
+// touchQuote отмечает изменение перевода на оригинале: растут version и
+// updated_at, поэтому меняются ETag цитаты и списков, а в истории появляется
+// ревизия с новой версией. Возвращает язык оригинала.
+func touchQuote(ctx context.Context, tx pgx.Tx, quoteID int) (string, error) {
+	query := `
+		UPDATE quotesbook
+		SET updated_at = now(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING lang`
+
+	var lang string
+	err := tx.QueryRow(ctx, query, quoteID).Scan(&lang)
+	if errdefs.Is(err, pgx.ErrNoRows) {
+		return "", errdefs.ErrNotFound
+	}
+	if err != nil {
+		return "", errdefs.Wrapf(errdefs.ErrDB, "failed to touch quote %d: %v", quoteID, err)
+	}
+	if err := recordRevision(ctx, tx, quoteID, models.ActionUpdate); err != nil {
+		return "", err
+	}
+	return lang, nil
+}
+
+// PutTranslation создаёт или заменяет перевод t.QuoteID на t.Lang; остальные
+// поля дописываются в t. Второе значение — перевод создан, а не заменён.
+// Перевод на язык оригинала — ErrInvalidInput.
+func (tr TranslationRepository) PutTranslation(ctx context.Context, t *models.Translation) (bool, error) {
+	tx, err := tr.db.Begin(ctx)
+	if err != nil {
+		return false, errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// строка цитаты блокируется до конца транзакции, язык оригинала не поменяется
+	lang, err := touchQuote(ctx, tx, t.QuoteID)
+	if err != nil {
+		return false, err
+	}
+	if lang == t.Lang {
+		return false, errdefs.Wrapf(errdefs.ErrInvalidInput, "quote %d is already in %s", t.QuoteID, lang)
+	}
+
+	query := `
+		INSERT INTO quote_translations (quote_id, lang, quote, translator)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (quote_id, lang) DO UPDATE
+		SET quote = EXCLUDED.quote, translator = EXCLUDED.translator, updated_at = now()
+		RETURNING ` + translationColumns + `, xmax = 0`
+
+	var created bool
+	err = tx.QueryRow(ctx, query, t.QuoteID, t.Lang, t.Quote, t.Translator).Scan(
+		&t.QuoteID, &t.Lang, &t.Quote, &t.Translator, &t.CreatedAt, &t.UpdatedAt, &created)
+	if err != nil {
+		return false, errdefs.Wrapf(errdefs.ErrDB, "failed to put translation of quote %d: %v", t.QuoteID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, errdefs.Wrapf(errdefs.ErrDB, "failed to commit tx: %v", err)
+	}
+	return created, nil
+}
+
+// GetTranslation перевод цитаты quoteID на lang
+func (tr TranslationRepository) GetTranslation(ctx context.Context, quoteID int, lang string) (*models.Translation, error) {
+	query := `
+		SELECT ` + translationColumns + `
+		FROM quote_translations
+		WHERE quote_id = $1 AND lang = $2`
+
+	var t models.Translation
+	err := scanTranslation(tr.db.QueryRow(ctx, query, quoteID, lang), &t)
+	if errdefs.Is(err, pgx.ErrNoRows) {
+		return nil, errdefs.ErrNotFound
+	}
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to get translation of quote %d: %v", quoteID, err)
+	}
+	return &t, nil
+}
+
+// Translations переводы цитаты по алфавиту языков
+func (tr TranslationRepository) Translations(ctx context.Context, quoteID int) (*[]models.Translation, error) {
+	query := `
+		SELECT ` + translationColumns + `
+		FROM quote_translations
+		WHERE quote_id = $1
+		ORDER BY lang`
+
+	rows, err := tr.db.Query(ctx, query, quoteID)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to list translations of quote %d: %v", quoteID, err)
+	}
+	return collectTranslations(rows)
+}
+
+// TranslationsOf переводы цитат ids на любой из langs, одним запросом для списка
+func (tr TranslationRepository) TranslationsOf(ctx context.Context, ids []int, langs []string) (*[]models.Translation, error) {
+	query := `
+		SELECT ` + translationColumns + `
+		FROM quote_translations
+		WHERE quote_id = ANY($1) AND lang = ANY($2)`
+
+	rows, err := tr.db.Query(ctx, query, ids, langs)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to query translations: %v", err)
+	}
+	return collectTranslations(rows)
+}
+
+// DeleteTranslation удаляет перевод; цитата при этом тоже считается изменённой
+func (tr TranslationRepository) DeleteTranslation(ctx context.Context, quoteID int, lang string) error {
+	tx, err := tr.db.Begin(ctx)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := touchQuote(ctx, tx, quoteID); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM quote_translations WHERE quote_id = $1 AND lang = $2`, quoteID, lang)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to delete translation of quote %d: %v", quoteID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errdefs.ErrNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to commit tx: %v", err)
+	}
+	return nil
+}
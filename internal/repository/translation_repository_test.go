@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+)
+
+func TestTranslationRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewTranslationRepository(db, cfg)
+	quotes := NewQuoteRepository(db, cfg)
+
+	t.Run("PutListDelete", func(t *testing.T) {
+		clearTable(t)
+		id, err := quotes.CreateQuote(ctx, &models.Quote{Author: "Шекспир", Quote: "Быть или не быть", Lang: "ru"})
+		require.NoError(t, err)
+
+		tr := &models.Translation{QuoteID: id, Lang: "en", Quote: "To be or not to be"}
+		created, err := repo.PutTranslation(ctx, tr)
+		require.NoError(t, err)
+		require.True(t, created)
+
+		tr.Translator = "W. S."
+		created, err = repo.PutTranslation(ctx, tr)
+		require.NoError(t, err)
+		require.False(t, created)
+
+		// перевод на язык оригинала не нужен
+		_, err = repo.PutTranslation(ctx, &models.Translation{QuoteID: id, Lang: "ru", Quote: "x"})
+		require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+		_, err = repo.PutTranslation(ctx, &models.Translation{QuoteID: id + 1000, Lang: "en", Quote: "x"})
+		require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+		got, err := repo.GetTranslation(ctx, id, "en")
+		require.NoError(t, err)
+		require.Equal(t, "W. S.", got.Translator)
+
+		// каждая запись перевода — новая версия оригинала
+		q, err := quotes.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, 3, q.Version)
+
+		_, err = repo.PutTranslation(ctx, &models.Translation{QuoteID: id, Lang: "de", Quote: "Sein oder Nichtsein"})
+		require.NoError(t, err)
+		all, err := repo.Translations(ctx, id)
+		require.NoError(t, err)
+		require.Len(t, *all, 2)
+		require.Equal(t, "de", (*all)[0].Lang)
+
+		some, err := repo.TranslationsOf(ctx, []int{id}, []string{"en", "fr"})
+		require.NoError(t, err)
+		require.Len(t, *some, 1)
+
+		require.NoError(t, repo.DeleteTranslation(ctx, id, "de"))
+		require.ErrorIs(t, repo.DeleteTranslation(ctx, id, "de"), errdefs.ErrNotFound)
+		_, err = repo.GetTranslation(ctx, id, "de")
+		require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+		// у каждой новой версии есть ревизия, отклонённые запросы её не пишут
+		q, err = quotes.GetQuote(ctx, id)
+		require.NoError(t, err)
+		revisions, err := quotes.QuoteRevisions(ctx, id)
+		require.NoError(t, err)
+		require.Len(t, *revisions, q.Version)
+		require.Equal(t, models.ActionUpdate, (*revisions)[q.Version-1].Action)
+	})
+
+	t.Run("LangFilter", func(t *testing.T) {
+		clearTable(t)
+		ru, err := quotes.CreateQuote(ctx, &models.Quote{Author: "Пушкин", Quote: "Я помню чудное мгновенье", Lang: "ru"})
+		require.NoError(t, err)
+		en, err := quotes.CreateQuote(ctx, &models.Quote{Author: "Wilde", Quote: "Be yourself", Lang: "en"})
+		require.NoError(t, err)
+		_, err = quotes.CreateQuote(ctx, &models.Quote{Author: "Goethe", Quote: "Mehr Licht", Lang: "de"})
+		require.NoError(t, err)
+		_, err = repo.PutTranslation(ctx, &models.Translation{QuoteID: en, Lang: "ru", Quote: "Будь собой"})
+		require.NoError(t, err)
+
+		var ids []int
+		err = quotes.StreamQuotes(ctx, &models.QuoteFilter{Lang: "ru"}, func(q *models.Quote) error {
+			ids = append(ids, q.ID)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int{ru, en}, ids)
+
+		q, err := quotes.RandQuoteByLang(ctx, "de")
+		require.NoError(t, err)
+		require.Equal(t, "Goethe", q.Author)
+		_, err = quotes.RandQuoteByLang(ctx, "fr")
+		require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+		// без языка при создании остаётся und
+		id, err := quotes.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "B"})
+		require.NoError(t, err)
+		q, err = quotes.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, "und", q.Lang)
+	})
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"quotebook/config"
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ViewRepository struct {
+	db  *pgxpool.Pool
+	cfg *config.Config
+}
+
+func NewViewRepository(db *pgxpool.Pool, cfg *config.Config) ViewRepository {
+	return ViewRepository{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// AddViews добавляет накопленные просмотры к почасовым и к итогам цитат.
+// Пары (цитата, час) в counts не повторяются; просмотры цитат, которых уже
+// нет, пропускаются. Как и избранное, просмотры не меняют ни версию, ни
+// updated_at цитаты.
+func (vr ViewRepository) AddViews(ctx context.Context, counts []models.ViewCount) error {
+	ids := make([]int, len(counts))
+	hours := make([]time.Time, len(counts))
+	views := make([]int64, len(counts))
+	served := make([]int64, len(counts))
+	for i, c := range counts {
+		ids[i], hours[i], views[i], served[i] = c.QuoteID, c.Hour, c.Views, c.Served
+	}
+
+	tx, err := vr.db.Begin(ctx)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO quote_views (quote_id, hour, hits)
+		SELECT v.quote_id, v.hour, v.hits
+		FROM unnest($1::int[], $2::timestamptz[], $3::bigint[]) AS v(quote_id, hour, hits)
+		JOIN quotesbook q ON q.id = v.quote_id
+		ON CONFLICT (quote_id, hour) DO UPDATE SET hits = quote_views.hits + EXCLUDED.hits
+	`, ids, hours, views)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to add hourly views: %v", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE quotesbook q
+		SET views = q.views + v.views, served = q.served + v.served
+		FROM (
+			SELECT quote_id, SUM(views) AS views, SUM(served) AS served
+			FROM unnest($1::int[], $2::bigint[], $3::bigint[]) AS u(quote_id, views, served)
+			GROUP BY quote_id
+		) v
+		WHERE q.id = v.quote_id
+	`, ids, views, served)
+	if err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to add quote views: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errdefs.Wrapf(errdefs.ErrDB, "failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+// Trending живые цитаты, которые смотрели с since, по убыванию оценки: каждый
+// просмотр весит вдвое меньше за каждые halfLife своего возраста
+func (vr ViewRepository) Trending(ctx context.Context, since time.Time, halfLife time.Duration, limit int) (*[]models.TrendingQuote, error) {
+	query := `
+		WITH scores AS (
+			SELECT quote_id, SUM(hits) AS hits,
+				SUM(hits * power(2, -extract(epoch FROM now() - hour)::float8 / $2))::float8 AS score
+			FROM quote_views
+			WHERE hour >= $1
+			GROUP BY quote_id
+		)
+		SELECT scores.hits, scores.score, ` + quoteColumns + `
+		FROM scores
+		JOIN quotesbook ON quotesbook.id = scores.quote_id
+		WHERE deleted_at IS NULL
+		ORDER BY scores.score DESC, id
+		LIMIT $3
+	`
+
+	rows, err := vr.db.Query(ctx, query, since, halfLife.Seconds(), limit)
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to query trending quotes: %v", err)
+	}
+	trending, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.TrendingQuote, error) {
+		var t models.TrendingQuote
+		err := row.Scan(append([]any{&t.Views, &t.Score}, quoteFields(&t.Quote)...)...)
+		return t, err
+	})
+	if err != nil {
+		return nil, errdefs.Wrapf(errdefs.ErrDB, "failed to scan trending quote: %v", err)
+	}
+	return &trending, nil
+}
+
+// PurgeViews удаляет почасовые просмотры старше before; итоги в quotesbook остаются
+func (vr ViewRepository) PurgeViews(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := vr.db.Exec(ctx, `DELETE FROM quote_views WHERE hour < $1`, before)
+	if err != nil {
+		return 0, errdefs.Wrapf(errdefs.ErrDB, "failed to purge views: %v", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"quotebook/internal/models"
+)
+
+func TestViewRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewViewRepository(db, cfg)
+	quotes := NewQuoteRepository(db, cfg)
+
+	t.Run("AddViews", func(t *testing.T) {
+		clearTable(t)
+		id, err := quotes.CreateQuote(ctx, &models.Quote{Author: "Seneca", Quote: "Per aspera ad astra"})
+		require.NoError(t, err)
+
+		hour := time.Now().Truncate(time.Hour)
+		require.NoError(t, repo.AddViews(ctx, []models.ViewCount{
+			{QuoteID: id, Hour: hour.Add(-time.Hour), Views: 2},
+			{QuoteID: id, Hour: hour, Views: 3, Served: 1},
+			// цитаты нет — просмотр пропускается
+			{QuoteID: id + 1000, Hour: hour, Views: 5},
+		}))
+		require.NoError(t, repo.AddViews(ctx, []models.ViewCount{{QuoteID: id, Hour: hour, Views: 1}}))
+
+		var views, served int64
+		err = db.QueryRow(ctx, `SELECT views, served FROM quotesbook WHERE id = $1`, id).Scan(&views, &served)
+		require.NoError(t, err)
+		require.Equal(t, int64(6), views)
+		require.Equal(t, int64(1), served)
+
+		// просмотры не меняют версию цитаты
+		q, err := quotes.GetQuote(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, 1, q.Version)
+
+		trending, err := repo.Trending(ctx, hour.Add(-24*time.Hour), 6*time.Hour, 10)
+		require.NoError(t, err)
+		require.Len(t, *trending, 1)
+		require.Equal(t, int64(6), (*trending)[0].Views)
+		require.Equal(t, id, (*trending)[0].Quote.ID)
+	})
+
+	t.Run("TrendingDecay", func(t *testing.T) {
+		clearTable(t)
+		old, err := quotes.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "old"})
+		require.NoError(t, err)
+		fresh, err := quotes.CreateQuote(ctx, &models.Quote{Author: "B", Quote: "fresh"})
+		require.NoError(t, err)
+		gone, err := quotes.CreateQuote(ctx, &models.Quote{Author: "C", Quote: "gone"})
+		require.NoError(t, err)
+
+		hour := time.Now().Truncate(time.Hour)
+		require.NoError(t, repo.AddViews(ctx, []models.ViewCount{
+			// больше просмотров, но полсуток назад: вес падает в 4 раза
+			{QuoteID: old, Hour: hour.Add(-12 * time.Hour), Views: 10},
+			{QuoteID: fresh, Hour: hour, Views: 4},
+			{QuoteID: gone, Hour: hour, Views: 100},
+			// за пределами окна
+			{QuoteID: old, Hour: hour.Add(-48 * time.Hour), Views: 1000},
+		}))
+		require.NoError(t, quotes.DeleteQuote(ctx, gone, 0))
+
+		trending, err := repo.Trending(ctx, hour.Add(-24*time.Hour), 6*time.Hour, 10)
+		require.NoError(t, err)
+		require.Len(t, *trending, 2)
+		require.Equal(t, fresh, (*trending)[0].Quote.ID)
+		require.Equal(t, old, (*trending)[1].Quote.ID)
+		require.Equal(t, int64(10), (*trending)[1].Views)
+		require.Less(t, (*trending)[1].Score, (*trending)[0].Score)
+
+		purged, err := repo.PurgeViews(ctx, hour.Add(-24*time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), purged)
+	})
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+    "context"
+    "errors"
+
+    "quotebook/config"
+    "quotebook/internal/audit"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/interfaces"
+    "quotebook/internal/models"
+)
+
+const (
+    defaultAuditLimit = 100
+    maxAuditLimit     = 1000
+)
+
+// прерывает обход цепочки после первого расхождения
+var errChainBroken = errors.New("audit chain broken")
+
+type AuditService struct {
+    repo interfaces.IAuditRepository
+    cfg *config.Config
+}
+
+func NewAuditService(cfg *config.Config, repo interfaces.IAuditRepository) AuditService {
+    return AuditService{
+        repo: repo,
+        cfg: cfg,
+    }
+}
+
+func (as AuditService) Record(ctx context.Context, e *models.AuditEntry) error {
+    if !as.cfg.Audit.Enabled {
+        return nil
+    }
+    return as.repo.AppendAudit(ctx, e)
+}
+
+func validateAuditFilter(f *models.AuditFilter) error {
+    if f.Outcome != "" && f.Outcome != models.OutcomeSuccess && f.Outcome != models.OutcomeFailure {
+        return errdefs.Wrapf(errdefs.ErrInvalidInput, "unknown outcome %q", f.Outcome)
+    }
+    if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+        return errdefs.Wrap(errdefs.ErrInvalidInput, "from must be before to")
+    }
+    if f.Limit < 0 {
+        return errdefs.Wrap(errdefs.ErrInvalidInput, "limit must be positive")
+    }
+    return nil
+}
+
+// Entries постранично отдаёт журнал, следующая страница — AfterID последней записи
+func (as AuditService) Entries(ctx context.Context, f *models.AuditFilter) (*[]models.AuditEntry, error) {
+    if err := validateAuditFilter(f); err != nil {
+        return nil, err
+    }
+    if f.Limit == 0 {
+        f.Limit = defaultAuditLimit
+    }
+    f.Limit = min(f.Limit, maxAuditLimit)
+    return as.repo.AuditEntries(ctx, f)
+}
+
+// Export отдаёт всю подходящую выборку без ограничения по количеству
+func (as AuditService) Export(ctx context.Context, f *models.AuditFilter, fn func(e *models.AuditEntry) error) error {
+    if err := validateAuditFilter(f); err != nil {
+        return err
+    }
+    return as.repo.StreamAudit(ctx, f, fn)
+}
+
+// Verify проходит по всей цепочке и ищет первую запись, хеш которой не сходится
+func (as AuditService) Verify(ctx context.Context) (*models.AuditVerification, error) {
+    res := &models.AuditVerification{Valid: true}
+    prevHash := ""
+    err := as.repo.StreamAudit(ctx, &models.AuditFilter{}, func(e *models.AuditEntry) error {
+        res.Checked++
+        if e.PrevHash != prevHash || audit.ChainHash(prevHash, e) != e.Hash {
+            res.Valid = false
+            res.BrokenAt = e.ID
+            return errChainBroken
+        }
+        prevHash = e.Hash
+        return nil
+    })
+    if err != nil && !errdefs.Is(err, errChainBroken) {
+        return nil, err
+    }
+    return res, nil
+}
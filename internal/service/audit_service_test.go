@@ -0,0 +1,152 @@
+package service
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/audit"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+type MockAuditRepository struct {
+    mock.Mock
+}
+
+func (m *MockAuditRepository) AppendAudit(ctx context.Context, e *models.AuditEntry) error {
+    args := m.Called(ctx, e)
+    return args.Error(0)
+}
+
+func (m *MockAuditRepository) AuditEntries(ctx context.Context, f *models.AuditFilter) (*[]models.AuditEntry, error) {
+    args := m.Called(ctx, f)
+    return args.Get(0).(*[]models.AuditEntry), args.Error(1)
+}
+
+// StreamAudit отдаёт в fn записи, переданные в Return
+func (m *MockAuditRepository) StreamAudit(ctx context.Context, f *models.AuditFilter, fn func(e *models.AuditEntry) error) error {
+    args := m.Called(ctx, f)
+    for _, e := range args.Get(0).([]models.AuditEntry) {
+        if err := fn(&e); err != nil {
+            return err
+        }
+    }
+    return args.Error(1)
+}
+
+// buildChain собирает корректную цепочку из n записей
+func buildChain(n int) []models.AuditEntry {
+    chain := make([]models.AuditEntry, n)
+    prev := ""
+    for i := range chain {
+        e := &chain[i]
+        e.ID = int64(i + 1)
+        e.CreatedAt = time.Date(2025, 1, 1, 0, 0, i, 0, time.UTC)
+        e.Principal = "alice"
+        e.Method = "POST"
+        e.Route = "/quotes"
+        e.Status = 201
+        e.Outcome = models.OutcomeSuccess
+        e.PrevHash = prev
+        e.Hash = audit.ChainHash(prev, e)
+        prev = e.Hash
+    }
+    return chain
+}
+
+func TestAuditVerify_Valid(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockAuditRepository)
+    svc := NewAuditService(cfg, mockRepo)
+
+    mockRepo.On("StreamAudit", ctx, mock.Anything).Return(buildChain(3), nil).Once()
+
+    res, err := svc.Verify(ctx)
+    require.NoError(t, err)
+    require.True(t, res.Valid)
+    require.Equal(t, 3, res.Checked)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestAuditVerify_Tampered(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockAuditRepository)
+    svc := NewAuditService(cfg, mockRepo)
+
+    chain := buildChain(4)
+    chain[1].Principal = "mallory"
+    mockRepo.On("StreamAudit", ctx, mock.Anything).Return(chain, nil).Once()
+
+    res, err := svc.Verify(ctx)
+    require.NoError(t, err)
+    require.False(t, res.Valid)
+    require.Equal(t, int64(2), res.BrokenAt)
+    require.Equal(t, 2, res.Checked)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestAuditVerify_RemovedRow(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockAuditRepository)
+    svc := NewAuditService(cfg, mockRepo)
+
+    chain := buildChain(4)
+    chain = append(chain[:2], chain[3:]...)
+    mockRepo.On("StreamAudit", ctx, mock.Anything).Return(chain, nil).Once()
+
+    res, err := svc.Verify(ctx)
+    require.NoError(t, err)
+    require.False(t, res.Valid)
+    require.Equal(t, int64(4), res.BrokenAt)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestAuditEntries_LimitClamped(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockAuditRepository)
+    svc := NewAuditService(cfg, mockRepo)
+
+    mockRepo.On("AuditEntries", ctx, mock.MatchedBy(func(f *models.AuditFilter) bool {
+        return f.Limit == maxAuditLimit
+    })).Return(&[]models.AuditEntry{}, nil).Once()
+
+    _, err := svc.Entries(ctx, &models.AuditFilter{Limit: 1000000})
+    require.NoError(t, err)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestAuditEntries_InvalidFilter(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockAuditRepository)
+    svc := NewAuditService(cfg, mockRepo)
+
+    _, err := svc.Entries(ctx, &models.AuditFilter{Outcome: "maybe"})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.AssertNotCalled(t, "AuditEntries", mock.Anything, mock.Anything)
+}
+
+func TestAuditRecord_Disabled(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Audit.Enabled = false
+    mockRepo := new(MockAuditRepository)
+    svc := NewAuditService(cfg, mockRepo)
+
+    require.NoError(t, svc.Record(ctx, &models.AuditEntry{}))
+
+    mockRepo.AssertNotCalled(t, "AppendAudit", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+    "context"
+    "strings"
+    "time"
+    "unicode/utf8"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+const (
+    defaultAuthorSimilarity  = 0.5
+    defaultSuggestSimilarity = 0.3
+    defaultSuggestLimit      = 10
+    maxSuggestLimit          = 50
+)
+
+func (qs QuoteService) authorSimilarity() float64 {
+    if s := qs.cfg.Authors.Similarity; s > 0 && s <= 1 {
+        return s
+    }
+    return defaultAuthorSimilarity
+}
+
+func (qs QuoteService) suggestLimit() int {
+    if n := qs.cfg.Authors.SuggestLimit; n > 0 {
+        return min(n, maxSuggestLimit)
+    }
+    return defaultSuggestLimit
+}
+
+// QuoteByAuthor цитаты автора без учёта регистра; если таких нет — цитаты
+// авторов, похожих на author не меньше чем на authors.similarity (опечатки)
+func (qs QuoteService) QuoteByAuthor(ctx context.Context, author string) (*[]models.Quote, error) {
+    author = strings.TrimSpace(author)
+    quotes, err := qs.repo.QuoteByAuthor(ctx, author)
+    if err != nil || author == "" || quotes != nil && len(*quotes) > 0 {
+        return quotes, err
+    }
+    return qs.repo.FuzzyQuotesByAuthor(ctx, author, qs.authorSimilarity())
+}
+
+// FilterQuotesByAuthor цитаты автора f.Author, найденного как в QuoteByAuthor
+// (с опечатками), по остальным полям фильтра: сначала определяются имена,
+// потом по каждому из них применяются тег, язык и hide_misattributed
+func (qs QuoteService) FilterQuotesByAuthor(ctx context.Context, f *models.QuoteFilter) (*[]models.Quote, error) {
+    quotes, err := qs.QuoteByAuthor(ctx, f.Author)
+    if err != nil || quotes == nil || f.Tag == "" && f.Lang == "" && !f.HideMisattributed {
+        return quotes, err
+    }
+
+    // имена в порядке QuoteByAuthor: самые похожие первыми
+    var names []string
+    seen := map[string]bool{}
+    for _, q := range *quotes {
+        if key := strings.ToLower(q.Author); !seen[key] {
+            seen[key] = true
+            names = append(names, q.Author)
+        }
+    }
+    filtered := []models.Quote{}
+    for _, name := range names {
+        byName := *f
+        byName.Author = name
+        err := qs.ExportQuotes(ctx, &byName, func(q *models.Quote) error {
+            filtered = append(filtered, *q)
+            return nil
+        })
+        if err != nil {
+            return nil, err
+        }
+    }
+    return &filtered, nil
+}
+
+// DidYouMean имена для подсказки, когда по author ничего не нашлось:
+// похожие хотя бы на authors.suggestSimilarity, самые похожие первыми
+func (qs QuoteService) DidYouMean(ctx context.Context, author string) ([]string, error) {
+    author = strings.TrimSpace(author)
+    if author == "" {
+        return nil, nil
+    }
+    threshold := qs.cfg.Authors.SuggestSimilarity
+    if threshold <= 0 || threshold > 1 {
+        threshold = defaultSuggestSimilarity
+    }
+    similar, err := qs.repo.SimilarAuthors(ctx, author, threshold, qs.suggestLimit())
+    if err != nil {
+        return nil, err
+    }
+    names := make([]string, 0, len(*similar))
+    for _, a := range *similar {
+        names = append(names, a.Author)
+    }
+    return names, nil
+}
+
+// SuggestAuthors автодополнение автора по началу имени или любого слова в нём;
+// limit 0 — authors.suggestLimit
+func (qs QuoteService) SuggestAuthors(ctx context.Context, prefix string, limit int) (*[]models.AuthorSuggestion, error) {
+    prefix = strings.TrimSpace(prefix)
+    if prefix == "" {
+        return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "prefix required")
+    }
+    if utf8.RuneCountInString(prefix) > maxAuthorLen {
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "prefix longer than %d characters", maxAuthorLen)
+    }
+    switch {
+    case limit == 0:
+        limit = qs.suggestLimit()
+    case limit < 0 || limit > maxSuggestLimit:
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "limit must be between 1 and %d", maxSuggestLimit)
+    }
+    return qs.repo.SuggestAuthors(ctx, prefix, limit)
+}
+
+// authorName имя из пути запроса: без пробелов по краям и не длиннее maxAuthorLen
+func authorName(name string) (string, error) {
+    name = strings.TrimSpace(name)
+    if name == "" {
+        return "", errdefs.Wrap(errdefs.ErrInvalidInput, "author required")
+    }
+    if utf8.RuneCountInString(name) > maxAuthorLen {
+        return "", errdefs.Wrapf(errdefs.ErrInvalidInput, "author longer than %d characters", maxAuthorLen)
+    }
+    return name, nil
+}
+
+// parseLifeDate дата жизни YYYY-MM-DD; пустая — неизвестна, будущая — ошибка
+func parseLifeDate(field, s string) (time.Time, error) {
+    s = strings.TrimSpace(s)
+    if s == "" {
+        return time.Time{}, nil
+    }
+    d, err := time.Parse(time.DateOnly, s)
+    if err != nil {
+        return time.Time{}, errdefs.Wrapf(errdefs.ErrInvalidInput, "%s must be YYYY-MM-DD, got %q", field, s)
+    }
+    if d.After(time.Now()) {
+        return time.Time{}, errdefs.Wrapf(errdefs.ErrInvalidInput, "%s is in the future", field)
+    }
+    return d, nil
+}
+
+// Author автор name (без учёта регистра) с датами жизни
+func (qs QuoteService) Author(ctx context.Context, name string) (*models.Author, error) {
+    name, err := authorName(name)
+    if err != nil {
+        return nil, err
+    }
+    return qs.repo.GetAuthor(ctx, name)
+}
+
+// SetAuthorDates задаёт даты рождения и смерти автора name из dates;
+// пустая дата — неизвестна, прежнее значение стирается
+func (qs QuoteService) SetAuthorDates(ctx context.Context, name string, dates *models.Author) (*models.Author, error) {
+    name, err := authorName(name)
+    if err != nil {
+        return nil, err
+    }
+    born, err := parseLifeDate("born", dates.Born)
+    if err != nil {
+        return nil, err
+    }
+    died, err := parseLifeDate("died", dates.Died)
+    if err != nil {
+        return nil, err
+    }
+    if !born.IsZero() && !died.IsZero() && died.Before(born) {
+        return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "died before born")
+    }
+
+    a := &models.Author{Name: name}
+    if !born.IsZero() {
+        a.Born = born.Format(time.DateOnly)
+    }
+    if !died.IsZero() {
+        a.Died = died.Format(time.DateOnly)
+    }
+    return qs.repo.PutAuthor(ctx, a)
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+    "bytes"
+    "container/list"
+    "context"
+    "fmt"
+    "strconv"
+    "strings"
+    "sync"
+
+    "quotebook/config"
+    "quotebook/internal/card"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/interfaces"
+    "quotebook/internal/models"
+)
+
+const (
+    defaultCardCache = 64 << 20
+    defaultCardSide  = 2400
+    minCardSide      = 200
+)
+
+// готовые размеры карточек
+var cardSizes = map[string][2]int{
+    "og":     {1200, 630},
+    "square": {1080, 1080},
+    "story":  {1080, 1920},
+}
+
+// cardCache LRU готовых картинок, ограниченный суммарным размером тел:
+// PNG размера story весит в десятки раз больше SVG og. Ключ содержит версию
+// цитаты, поэтому после правки старые картинки просто вытесняются.
+type cardCache struct {
+    mu       sync.Mutex
+    maxBytes int
+    bytes    int
+    order    *list.List
+    items    map[string]*list.Element
+}
+
+type cardEntry struct {
+    key  string
+    card *models.Card
+}
+
+func newCardCache(maxBytes int) *cardCache {
+    return &cardCache{maxBytes: maxBytes, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *cardCache) get(key string) (*models.Card, bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    el, ok := c.items[key]
+    if !ok {
+        return nil, false
+    }
+    c.order.MoveToFront(el)
+    return el.Value.(*cardEntry).card, true
+}
+
+func (c *cardCache) put(key string, cd *models.Card) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if el, ok := c.items[key]; ok {
+        c.order.MoveToFront(el)
+        return
+    }
+    // картинка больше всего кеша вытеснила бы всё и всё равно не поместилась
+    if len(cd.Body) > c.maxBytes {
+        return
+    }
+    c.items[key] = c.order.PushFront(&cardEntry{key: key, card: cd})
+    c.bytes += len(cd.Body)
+    for c.bytes > c.maxBytes {
+        oldest := c.order.Back()
+        c.order.Remove(oldest)
+        entry := oldest.Value.(*cardEntry)
+        delete(c.items, entry.key)
+        c.bytes -= len(entry.card.Body)
+    }
+}
+
+type CardService struct {
+    repo interfaces.IQuoteRepository
+    cfg *config.Config
+    cache *cardCache
+}
+
+func NewCardService(cfg *config.Config, repo interfaces.IQuoteRepository) CardService {
+    maxBytes := cfg.Card.CacheBytes
+    if maxBytes <= 0 {
+        maxBytes = defaultCardCache
+    }
+    return CardService{
+        repo: repo,
+        cfg: cfg,
+        cache: newCardCache(maxBytes),
+    }
+}
+
+// cardSize разбирает пресет или WxH
+func (cs CardService) cardSize(size string) (int, int, error) {
+    if size == "" {
+        size = "og"
+    }
+    if wh, ok := cardSizes[size]; ok {
+        return wh[0], wh[1], nil
+    }
+
+    maxSide := cs.cfg.Card.MaxSide
+    if maxSide <= 0 {
+        maxSide = defaultCardSide
+    }
+    ws, hs, ok := strings.Cut(size, "x")
+    w, errW := strconv.Atoi(ws)
+    h, errH := strconv.Atoi(hs)
+    if !ok || errW != nil || errH != nil {
+        return 0, 0, errdefs.Wrapf(errdefs.ErrInvalidInput, "size must be og, square, story or WIDTHxHEIGHT, got %q", size)
+    }
+    if w < minCardSide || h < minCardSide || w > maxSide || h > maxSide {
+        return 0, 0, errdefs.Wrapf(errdefs.ErrInvalidInput, "card sides must be between %d and %d pixels", minCardSide, maxSide)
+    }
+    return w, h, nil
+}
+
+func (cs CardService) cardOptions(opts *models.CardOptions) (card.Options, error) {
+    if opts.Format != models.CardPNG && opts.Format != models.CardSVG {
+        return card.Options{}, errdefs.Wrapf(errdefs.ErrInvalidInput, "unknown card format %q", opts.Format)
+    }
+
+    w, h, err := cs.cardSize(opts.Size)
+    if err != nil {
+        return card.Options{}, err
+    }
+    o := card.Options{Width: w, Height: h, Theme: opts.Theme, Font: opts.Font}
+    if o.Theme == "" {
+        o.Theme = cs.cfg.Card.Theme
+    }
+    if o.Font == "" {
+        o.Font = cs.cfg.Card.Font
+    }
+    if !card.HasTheme(o.Theme) {
+        return card.Options{}, errdefs.Wrapf(errdefs.ErrInvalidInput, "theme must be one of %s", strings.Join(card.Themes(), ", "))
+    }
+    if !card.HasFont(o.Font) {
+        return card.Options{}, errdefs.Wrapf(errdefs.ErrInvalidInput, "font must be one of %s", strings.Join(card.Fonts(), ", "))
+    }
+    return o, nil
+}
+
+// QuoteCard рисует карточку цитаты или отдаёт уже нарисованную для той же версии
+func (cs CardService) QuoteCard(ctx context.Context, id int, opts *models.CardOptions) (*models.Card, error) {
+    o, err := cs.cardOptions(opts)
+    if err != nil {
+        return nil, err
+    }
+    quote, err := cs.repo.GetQuote(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    key := fmt.Sprintf("%d-%d-%dx%d-%s-%s.%s", quote.ID, quote.Version, o.Width, o.Height, o.Theme, o.Font, opts.Format)
+    if cd, ok := cs.cache.get(key); ok {
+        return cd, nil
+    }
+
+    var buf bytes.Buffer
+    cd := &models.Card{ETag: `"` + key + `"`, LastModified: quote.UpdatedAt}
+    switch opts.Format {
+    case models.CardPNG:
+        cd.ContentType = "image/png"
+        err = card.PNG(&buf, quote.Quote, quote.Author, o)
+    case models.CardSVG:
+        cd.ContentType = "image/svg+xml"
+        err = card.SVG(&buf, quote.Quote, quote.Author, o)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("render card for quote %d: %w", id, err)
+    }
+    cd.Body = buf.Bytes()
+
+    cs.cache.put(key, cd)
+    return cd, nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+    "bytes"
+    "context"
+    "image/png"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+func TestQuoteCard_PNG(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewCardService(cfg, mockRepo)
+
+    quote := &models.Quote{ID: 1, Author: "Confucius", Quote: "Everything has beauty, but not everyone sees it.", Version: 2}
+    mockRepo.On("GetQuote", ctx, 1).Return(quote, nil).Twice()
+
+    cd, err := svc.QuoteCard(ctx, 1, &models.CardOptions{Format: models.CardPNG, Size: "square", Theme: "dark"})
+    require.NoError(t, err)
+    require.Equal(t, "image/png", cd.ContentType)
+    require.Contains(t, cd.ETag, "1-2-")
+
+    img, err := png.Decode(bytes.NewReader(cd.Body))
+    require.NoError(t, err)
+    require.Equal(t, 1080, img.Bounds().Dx())
+    require.Equal(t, 1080, img.Bounds().Dy())
+
+    // второй раз картинка берётся из кеша
+    again, err := svc.QuoteCard(ctx, 1, &models.CardOptions{Format: models.CardPNG, Size: "square", Theme: "dark"})
+    require.NoError(t, err)
+    require.Same(t, cd, again)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestQuoteCard_NewVersionRerenders(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewCardService(cfg, mockRepo)
+
+    mockRepo.On("GetQuote", ctx, 1).Return(&models.Quote{ID: 1, Author: "A", Quote: "old", Version: 1}, nil).Once()
+    mockRepo.On("GetQuote", ctx, 1).Return(&models.Quote{ID: 1, Author: "A", Quote: "new", Version: 2}, nil).Once()
+
+    opts := &models.CardOptions{Format: models.CardSVG}
+    first, err := svc.QuoteCard(ctx, 1, opts)
+    require.NoError(t, err)
+    second, err := svc.QuoteCard(ctx, 1, opts)
+    require.NoError(t, err)
+
+    require.NotEqual(t, first.ETag, second.ETag)
+    require.Contains(t, string(second.Body), "new")
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestQuoteCard_SVGEscapesAndWraps(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewCardService(cfg, mockRepo)
+
+    quote := &models.Quote{ID: 3, Author: "Tom & Jerry", Quote: strings.Repeat("<cat> chases mouse ", 30), Version: 1}
+    mockRepo.On("GetQuote", ctx, 3).Return(quote, nil).Once()
+
+    cd, err := svc.QuoteCard(ctx, 3, &models.CardOptions{Format: models.CardSVG, Size: "600x400", Font: "mono"})
+    require.NoError(t, err)
+    require.Equal(t, "image/svg+xml", cd.ContentType)
+
+    body := string(cd.Body)
+    require.True(t, strings.HasPrefix(body, `<svg xmlns="http://www.w3.org/2000/svg" width="600" height="400"`))
+    require.Contains(t, body, "Tom &amp; Jerry")
+    require.Contains(t, body, "&lt;cat&gt;")
+    require.NotContains(t, body, "<cat>")
+    require.Greater(t, strings.Count(body, "<tspan"), 3)
+}
+
+func TestQuoteCard_InvalidOptions(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewCardService(cfg, mockRepo)
+
+    for _, opts := range []*models.CardOptions{
+        {Format: "gif"},
+        {Format: models.CardPNG, Size: "huge"},
+        {Format: models.CardPNG, Size: "100x100"},
+        {Format: models.CardPNG, Size: "5000x1000"},
+        {Format: models.CardPNG, Theme: "neon"},
+        {Format: models.CardPNG, Font: "comic"},
+    } {
+        _, err := svc.QuoteCard(ctx, 1, opts)
+        require.ErrorIs(t, err, errdefs.ErrInvalidInput, "%+v", opts)
+    }
+
+    mockRepo.AssertNotCalled(t, "GetQuote", mock.Anything, mock.Anything)
+}
+
+func TestQuoteCard_NotFound(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewCardService(cfg, mockRepo)
+
+    mockRepo.On("GetQuote", ctx, 9).Return((*models.Quote)(nil), errdefs.ErrNotFound).Once()
+
+    _, err := svc.QuoteCard(ctx, 9, &models.CardOptions{Format: models.CardPNG})
+    require.ErrorIs(t, err, errdefs.ErrNotFound)
+}
+
+func TestCardCache_BoundedByBytes(t *testing.T) {
+    c := newCardCache(100)
+    card := func(n int) *models.Card { return &models.Card{Body: make([]byte, n)} }
+
+    c.put("a", card(40))
+    c.put("b", card(40))
+    _, ok := c.get("a")
+    require.True(t, ok)
+
+    // c не помещается вместе с a и b: вытесняется давно не читанная b
+    c.put("c", card(40))
+    _, ok = c.get("b")
+    require.False(t, ok)
+    _, ok = c.get("a")
+    require.True(t, ok)
+    require.Equal(t, 80, c.bytes)
+
+    // больше всего кеша — не кешируется и ничего не вытесняет
+    c.put("huge", card(101))
+    _, ok = c.get("huge")
+    require.False(t, ok)
+    require.Equal(t, 80, c.bytes)
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+    "context"
+    "strings"
+    "unicode/utf8"
+
+    "quotebook/config"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/identity"
+    "quotebook/internal/interfaces"
+    "quotebook/internal/models"
+)
+
+const (
+    maxCollectionName        = 200
+    maxCollectionDescription = 2000
+)
+
+type CollectionService struct {
+    repo interfaces.ICollectionRepository
+    cfg *config.Config
+}
+
+func NewCollectionService(cfg *config.Config, repo interfaces.ICollectionRepository) CollectionService {
+    return CollectionService{
+        repo: repo,
+        cfg: cfg,
+    }
+}
+
+// visible подборка, если её можно видеть текущему пользователю. Чужая
+// приватная подборка неотличима от несуществующей.
+func (cs CollectionService) visible(ctx context.Context, id int) (*models.Collection, error) {
+    c, err := cs.repo.GetCollection(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    if !c.Public && c.Owner != identity.ActorFromCtx(ctx) {
+        return nil, errdefs.ErrNotFound
+    }
+    return c, nil
+}
+
+// owned подборка, если её может менять текущий пользователь
+func (cs CollectionService) owned(ctx context.Context, id int) (*models.Collection, error) {
+    p, err := principal(ctx)
+    if err != nil {
+        return nil, err
+    }
+    c, err := cs.visible(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    if c.Owner != p {
+        return nil, errdefs.Wrapf(errdefs.ErrForbidden, "collection %d belongs to another user", id)
+    }
+    return c, nil
+}
+
+func validateCollectionName(name string) (string, error) {
+    name = strings.TrimSpace(name)
+    if name == "" {
+        return "", errdefs.Wrap(errdefs.ErrInvalidInput, "collection name is required")
+    }
+    if utf8.RuneCountInString(name) > maxCollectionName {
+        return "", errdefs.Wrapf(errdefs.ErrInvalidInput, "collection name is longer than %d characters", maxCollectionName)
+    }
+    return name, nil
+}
+
+func validateCollectionDescription(description string) error {
+    if utf8.RuneCountInString(description) > maxCollectionDescription {
+        return errdefs.Wrapf(errdefs.ErrInvalidInput, "description is longer than %d characters", maxCollectionDescription)
+    }
+    return nil
+}
+
+// CreateCollection новая подборка текущего пользователя, по умолчанию приватная
+func (cs CollectionService) CreateCollection(ctx context.Context, c *models.Collection) (*models.Collection, error) {
+    p, err := principal(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if c.Name, err = validateCollectionName(c.Name); err != nil {
+        return nil, err
+    }
+    if err := validateCollectionDescription(c.Description); err != nil {
+        return nil, err
+    }
+    c.Owner = p
+    c.Size = 0
+    c.Quotes = nil
+    if err := cs.repo.CreateCollection(ctx, c); err != nil {
+        return nil, err
+    }
+    return c, nil
+}
+
+// Collections публичные подборки и свои; owner непустой — только его подборки
+func (cs CollectionService) Collections(ctx context.Context, owner string) (*[]models.Collection, error) {
+    return cs.repo.ListCollections(ctx, identity.ActorFromCtx(ctx), owner)
+}
+
+// GetCollection подборка с цитатами по порядку
+func (cs CollectionService) GetCollection(ctx context.Context, id int) (*models.Collection, error) {
+    c, err := cs.visible(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    quotes, err := cs.repo.CollectionQuotes(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    c.Quotes = *quotes
+    return c, nil
+}
+
+func (cs CollectionService) UpdateCollection(ctx context.Context, id int, p *models.CollectionPatch) (*models.Collection, error) {
+    if p.Name == nil && p.Description == nil && p.Public == nil {
+        return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "nothing to update")
+    }
+    if p.Name != nil {
+        name, err := validateCollectionName(*p.Name)
+        if err != nil {
+            return nil, err
+        }
+        p.Name = &name
+    }
+    if p.Description != nil {
+        if err := validateCollectionDescription(*p.Description); err != nil {
+            return nil, err
+        }
+    }
+    if _, err := cs.owned(ctx, id); err != nil {
+        return nil, err
+    }
+    return cs.repo.UpdateCollection(ctx, id, p)
+}
+
+func (cs CollectionService) DeleteCollection(ctx context.Context, id int) error {
+    if _, err := cs.owned(ctx, id); err != nil {
+        return err
+    }
+    return cs.repo.DeleteCollection(ctx, id)
+}
+
+// AddQuote добавляет цитату на позицию position (с 1), 0 — в конец
+func (cs CollectionService) AddQuote(ctx context.Context, id, quoteID, position int) (*models.Collection, error) {
+    if position < 0 {
+        return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "position must be positive")
+    }
+    if _, err := cs.owned(ctx, id); err != nil {
+        return nil, err
+    }
+    if err := cs.repo.AddToCollection(ctx, id, quoteID, position); err != nil {
+        return nil, err
+    }
+    return cs.GetCollection(ctx, id)
+}
+
+func (cs CollectionService) RemoveQuote(ctx context.Context, id, quoteID int) (*models.Collection, error) {
+    if _, err := cs.owned(ctx, id); err != nil {
+        return nil, err
+    }
+    if err := cs.repo.RemoveFromCollection(ctx, id, quoteID); err != nil {
+        return nil, err
+    }
+    return cs.GetCollection(ctx, id)
+}
+
+// ReorderCollection новый порядок: все цитаты подборки ровно по разу
+func (cs CollectionService) ReorderCollection(ctx context.Context, id int, quoteIDs []int) (*models.Collection, error) {
+    seen := make(map[int]bool, len(quoteIDs))
+    for _, qid := range quoteIDs {
+        if seen[qid] {
+            return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "quote %d is listed twice", qid)
+        }
+        seen[qid] = true
+    }
+    if _, err := cs.owned(ctx, id); err != nil {
+        return nil, err
+    }
+    if err := cs.repo.ReorderCollection(ctx, id, quoteIDs); err != nil {
+        return nil, err
+    }
+    return cs.GetCollection(ctx, id)
+}
+
+// RandomQuote случайная цитата подборки
+func (cs CollectionService) RandomQuote(ctx context.Context, id int) (*models.Quote, error) {
+    if _, err := cs.visible(ctx, id); err != nil {
+        return nil, err
+    }
+    return cs.repo.RandCollectionQuote(ctx, id)
+}
+
+// ExportCollection передаёт в fn цитаты подборки по порядку
+func (cs CollectionService) ExportCollection(ctx context.Context, id int, fn func(q *models.Quote) error) error {
+    if _, err := cs.visible(ctx, id); err != nil {
+        return err
+    }
+    quotes, err := cs.repo.CollectionQuotes(ctx, id)
+    if err != nil {
+        return err
+    }
+    for i := range *quotes {
+        if err := fn(&(*quotes)[i]); err != nil {
+            return err
+        }
+    }
+    return nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+    "context"
+    "testing"
+
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/identity"
+    "quotebook/internal/models"
+)
+
+type MockCollectionRepository struct {
+    mock.Mock
+}
+
+func (m *MockCollectionRepository) CreateCollection(ctx context.Context, c *models.Collection) error {
+    args := m.Called(ctx, c)
+    return args.Error(0)
+}
+
+func (m *MockCollectionRepository) GetCollection(ctx context.Context, id int) (*models.Collection, error) {
+    args := m.Called(ctx, id)
+    c, _ := args.Get(0).(*models.Collection)
+    if c != nil {
+        // сервис дописывает цитаты в результат, мок отдаёт копию
+        cp := *c
+        c = &cp
+    }
+    return c, args.Error(1)
+}
+
+func (m *MockCollectionRepository) ListCollections(ctx context.Context, viewer, owner string) (*[]models.Collection, error) {
+    args := m.Called(ctx, viewer, owner)
+    return args.Get(0).(*[]models.Collection), args.Error(1)
+}
+
+func (m *MockCollectionRepository) UpdateCollection(ctx context.Context, id int, p *models.CollectionPatch) (*models.Collection, error) {
+    args := m.Called(ctx, id, p)
+    return args.Get(0).(*models.Collection), args.Error(1)
+}
+
+func (m *MockCollectionRepository) DeleteCollection(ctx context.Context, id int) error {
+    args := m.Called(ctx, id)
+    return args.Error(0)
+}
+
+func (m *MockCollectionRepository) CollectionQuotes(ctx context.Context, id int) (*[]models.Quote, error) {
+    args := m.Called(ctx, id)
+    return args.Get(0).(*[]models.Quote), args.Error(1)
+}
+
+func (m *MockCollectionRepository) RandCollectionQuote(ctx context.Context, id int) (*models.Quote, error) {
+    args := m.Called(ctx, id)
+    return args.Get(0).(*models.Quote), args.Error(1)
+}
+
+func (m *MockCollectionRepository) AddToCollection(ctx context.Context, id, quoteID, position int) error {
+    args := m.Called(ctx, id, quoteID, position)
+    return args.Error(0)
+}
+
+func (m *MockCollectionRepository) RemoveFromCollection(ctx context.Context, id, quoteID int) error {
+    args := m.Called(ctx, id, quoteID)
+    return args.Error(0)
+}
+
+func (m *MockCollectionRepository) ReorderCollection(ctx context.Context, id int, quoteIDs []int) error {
+    args := m.Called(ctx, id, quoteIDs)
+    return args.Error(0)
+}
+
+func TestCreateCollection_OwnerFromPrincipal(t *testing.T) {
+    ctx := identity.CtxWithActor(context.Background(), "alice")
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockCollectionRepository)
+    svc := NewCollectionService(cfg, mockRepo)
+
+    mockRepo.On("CreateCollection", ctx, mock.MatchedBy(func(c *models.Collection) bool {
+        return c.Owner == "alice" && c.Name == "Monday motivation"
+    })).Return(nil).Once()
+
+    c, err := svc.CreateCollection(ctx, &models.Collection{Owner: "bob", Name: "  Monday motivation "})
+    require.NoError(t, err)
+    require.Equal(t, "alice", c.Owner)
+
+    _, err = svc.CreateCollection(ctx, &models.Collection{Name: " "})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    _, err = svc.CreateCollection(context.Background(), &models.Collection{Name: "x"})
+    require.ErrorIs(t, err, errdefs.ErrUnauthorized)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestGetCollection_PrivateHiddenFromOthers(t *testing.T) {
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockCollectionRepository)
+    svc := NewCollectionService(cfg, mockRepo)
+
+    private := &models.Collection{ID: 1, Owner: "alice", Public: false}
+    mockRepo.On("GetCollection", mock.Anything, 1).Return(private, nil)
+    mockRepo.On("CollectionQuotes", mock.Anything, 1).Return(&[]models.Quote{{ID: 5}, {ID: 3}}, nil)
+
+    _, err := svc.GetCollection(identity.CtxWithActor(context.Background(), "bob"), 1)
+    require.ErrorIs(t, err, errdefs.ErrNotFound)
+    _, err = svc.GetCollection(context.Background(), 1)
+    require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+    c, err := svc.GetCollection(identity.CtxWithActor(context.Background(), "alice"), 1)
+    require.NoError(t, err)
+    require.Equal(t, []models.Quote{{ID: 5}, {ID: 3}}, c.Quotes)
+}
+
+func TestAddQuote_ForbiddenForOthersPublic(t *testing.T) {
+    ctx := identity.CtxWithActor(context.Background(), "bob")
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockCollectionRepository)
+    svc := NewCollectionService(cfg, mockRepo)
+
+    mockRepo.On("GetCollection", ctx, 1).Return(&models.Collection{ID: 1, Owner: "alice", Public: true}, nil)
+
+    _, err := svc.AddQuote(ctx, 1, 7, 0)
+    require.ErrorIs(t, err, errdefs.ErrForbidden)
+    mockRepo.AssertNotCalled(t, "AddToCollection", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReorderCollection_Duplicates(t *testing.T) {
+    ctx := identity.CtxWithActor(context.Background(), "alice")
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockCollectionRepository)
+    svc := NewCollectionService(cfg, mockRepo)
+
+    _, err := svc.ReorderCollection(ctx, 1, []int{3, 1, 3})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.On("GetCollection", ctx, 1).Return(&models.Collection{ID: 1, Owner: "alice"}, nil)
+    mockRepo.On("ReorderCollection", ctx, 1, []int{3, 1}).Return(nil).Once()
+    mockRepo.On("CollectionQuotes", ctx, 1).Return(&[]models.Quote{{ID: 3}, {ID: 1}}, nil)
+
+    c, err := svc.ReorderCollection(ctx, 1, []int{3, 1})
+    require.NoError(t, err)
+    require.Equal(t, 3, c.Quotes[0].ID)
+
+    mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+    "context"
+    "fmt"
+    "hash/fnv"
+    "time"
+
+    "quotebook/config"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/interfaces"
+    "quotebook/internal/models"
+)
+
+const (
+    defaultDailyHistory = 30
+    maxDailyHistory     = 366
+)
+
+type DailyService struct {
+    repo interfaces.IDailyRepository
+    cfg *config.Config
+}
+
+func NewDailyService(cfg *config.Config, repo interfaces.IDailyRepository) DailyService {
+    return DailyService{
+        repo: repo,
+        cfg: cfg,
+    }
+}
+
+// location пояс из запроса, без него — daily.timezone
+func (ds DailyService) location(tz string) (*time.Location, error) {
+    if tz == "" {
+        tz = ds.cfg.Daily.Timezone
+    }
+    // "Local" зависел бы от настроек конкретной реплики
+    if tz == "Local" {
+        return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "timezone must be an IANA name, e.g. Europe/Moscow")
+    }
+    loc, err := time.LoadLocation(tz)
+    if err != nil {
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "unknown timezone %q", tz)
+    }
+    return loc, nil
+}
+
+// dailyMode режим из запроса, без него — daily.mode
+func (ds DailyService) dailyMode(mode string) (string, error) {
+    if mode == "" {
+        mode = ds.cfg.Daily.Mode
+    }
+    switch mode {
+    case "":
+        return models.DailyRotation, nil
+    case models.DailyRotation, models.DailyOnThisDay:
+        return mode, nil
+    default:
+        return "", errdefs.Wrapf(errdefs.ErrInvalidInput, "mode must be %s or %s, got %q",
+            models.DailyRotation, models.DailyOnThisDay, mode)
+    }
+}
+
+// DailyQuote цитата на сегодняшний день в поясе tz. Один и тот же день во всех
+// поясах получает одну цитату, просто в Токио он наступает раньше. mode —
+// rotation или on-this-day, пустой — daily.mode; в режиме on-this-day цитата
+// назначенного администратором дня не заменяется, а без годовщин цитата
+// выбирается по кругу.
+func (ds DailyService) DailyQuote(ctx context.Context, tz, mode string) (*models.DailyQuote, error) {
+    mode, err := ds.dailyMode(mode)
+    if err != nil {
+        return nil, err
+    }
+    loc, err := ds.location(tz)
+    if err != nil {
+        return nil, err
+    }
+    today := time.Now().In(loc)
+
+    var dq *models.DailyQuote
+    if mode == models.DailyOnThisDay {
+        if dq, err = ds.onThisDayQuote(ctx, today); err != nil {
+            return nil, err
+        }
+    }
+    if dq == nil {
+        if dq, err = ds.repo.DailyQuote(ctx, today); err != nil {
+            return nil, err
+        }
+    }
+    dq.Timezone = loc.String()
+    return dq, nil
+}
+
+// onThisDayQuote цитата дня режима on-this-day: назначенная или уже выбранная
+// сегодня, иначе цитата юбиляра, которая запоминается на весь день. nil —
+// годовщин сегодня нет; только тогда берётся и расходуется цитата по кругу.
+func (ds DailyService) onThisDayQuote(ctx context.Context, today time.Time) (*models.DailyQuote, error) {
+    dq, err := ds.repo.OnThisDayQuote(ctx, today)
+    if err == nil {
+        return dq, nil
+    }
+    if !errdefs.Is(err, errdefs.ErrNotFound) {
+        return nil, err
+    }
+
+    otd, err := ds.onThisDay(ctx, today)
+    if err != nil {
+        return nil, err
+    }
+    aq := pickAnniversary(otd.Date, otd.Quotes)
+    if aq == nil {
+        return nil, nil
+    }
+    return ds.repo.SaveOnThisDayQuote(ctx, today, aq)
+}
+
+// anniversaryDays дни MM-DD, годовщины которых отмечаются в day: 29 февраля
+// в невисокосный год отмечается 28-го
+func anniversaryDays(day time.Time) []string {
+    days := []string{day.Format("01-02")}
+    leap := time.Date(day.Year(), time.February, 29, 0, 0, 0, 0, time.UTC).Day() == 29
+    if day.Month() == time.February && day.Day() == 28 && !leap {
+        days = append(days, "02-29")
+    }
+    return days
+}
+
+// pickAnniversary одна из quotes для дня date: выбор зависит только от даты
+// и id, поэтому одинаков на всех репликах; nil, если quotes пуст
+func pickAnniversary(date string, quotes []models.AnniversaryQuote) *models.AnniversaryQuote {
+    var (
+        best   *models.AnniversaryQuote
+        lowest uint64
+    )
+    for i := range quotes {
+        h := fnv.New64a()
+        fmt.Fprintf(h, "%s-%d", date, quotes[i].Quote.ID)
+        if sum := h.Sum64(); best == nil || sum < lowest {
+            best, lowest = &quotes[i], sum
+        }
+    }
+    return best
+}
+
+// OnThisDay цитаты авторов, родившихся или умерших в сегодняшний день пояса
+// tz в другие годы
+func (ds DailyService) OnThisDay(ctx context.Context, tz string) (*models.OnThisDay, error) {
+    loc, err := ds.location(tz)
+    if err != nil {
+        return nil, err
+    }
+    otd, err := ds.onThisDay(ctx, time.Now().In(loc))
+    if err != nil {
+        return nil, err
+    }
+    otd.Timezone = loc.String()
+    return otd, nil
+}
+
+func (ds DailyService) onThisDay(ctx context.Context, day time.Time) (*models.OnThisDay, error) {
+    quotes, err := ds.repo.OnThisDay(ctx, anniversaryDays(day))
+    if err != nil {
+        return nil, err
+    }
+    for i := range *quotes {
+        aq := &(*quotes)[i]
+        if event, err := time.Parse(time.DateOnly, aq.Date); err == nil {
+            aq.Years = day.Year() - event.Year()
+        }
+    }
+    return &models.OnThisDay{Date: day.Format(time.DateOnly), Quotes: *quotes}, nil
+}
+
+// DailyHistory цитаты прошедших дней по сегодняшний в поясе tz в том виде,
+// в каком их показывал режим mode (пустой — daily.mode), limit 0 — daily.historySize
+func (ds DailyService) DailyHistory(ctx context.Context, tz, mode string, limit int) (*[]models.DailyQuote, error) {
+    mode, err := ds.dailyMode(mode)
+    if err != nil {
+        return nil, err
+    }
+    loc, err := ds.location(tz)
+    if err != nil {
+        return nil, err
+    }
+    if limit == 0 {
+        limit = ds.cfg.Daily.HistorySize
+    }
+    if limit <= 0 {
+        limit = defaultDailyHistory
+    }
+    if limit > maxDailyHistory {
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "limit must be at most %d", maxDailyHistory)
+    }
+
+    history, err := ds.repo.DailyHistory(ctx, time.Now().In(loc), mode, limit)
+    if err != nil {
+        return nil, err
+    }
+    for i := range *history {
+        (*history)[i].Timezone = loc.String()
+    }
+    return history, nil
+}
+
+// PinDailyQuote назначает цитату на дату YYYY-MM-DD, в том числе на уже прошедшую
+func (ds DailyService) PinDailyQuote(ctx context.Context, date string, quoteID int) (*models.DailyQuote, error) {
+    day, err := time.Parse(time.DateOnly, date)
+    if err != nil {
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "date must be YYYY-MM-DD, got %q", date)
+    }
+    if quoteID <= 0 {
+        return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "quote_id required")
+    }
+    return ds.repo.PinDailyQuote(ctx, day, quoteID)
+}
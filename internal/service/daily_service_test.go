@@ -0,0 +1,227 @@
+package service
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+type MockDailyRepository struct {
+    mock.Mock
+}
+
+func (m *MockDailyRepository) DailyQuote(ctx context.Context, day time.Time) (*models.DailyQuote, error) {
+    args := m.Called(ctx, day)
+    return args.Get(0).(*models.DailyQuote), args.Error(1)
+}
+
+func (m *MockDailyRepository) DailyHistory(ctx context.Context, until time.Time, mode string, limit int) (*[]models.DailyQuote, error) {
+    args := m.Called(ctx, until, mode, limit)
+    return args.Get(0).(*[]models.DailyQuote), args.Error(1)
+}
+
+func (m *MockDailyRepository) PinDailyQuote(ctx context.Context, day time.Time, quoteID int) (*models.DailyQuote, error) {
+    args := m.Called(ctx, day, quoteID)
+    return args.Get(0).(*models.DailyQuote), args.Error(1)
+}
+
+func (m *MockDailyRepository) OnThisDayQuote(ctx context.Context, day time.Time) (*models.DailyQuote, error) {
+    args := m.Called(ctx, day)
+    return args.Get(0).(*models.DailyQuote), args.Error(1)
+}
+
+func (m *MockDailyRepository) SaveOnThisDayQuote(ctx context.Context, day time.Time, aq *models.AnniversaryQuote) (*models.DailyQuote, error) {
+    args := m.Called(ctx, day, aq)
+    if saved, ok := args.Get(0).(func(context.Context, time.Time, *models.AnniversaryQuote) *models.DailyQuote); ok {
+        return saved(ctx, day, aq), args.Error(1)
+    }
+    return args.Get(0).(*models.DailyQuote), args.Error(1)
+}
+
+func (m *MockDailyRepository) OnThisDay(ctx context.Context, days []string) (*[]models.AnniversaryQuote, error) {
+    args := m.Called(ctx, days)
+    return args.Get(0).(*[]models.AnniversaryQuote), args.Error(1)
+}
+
+// inZone день передаётся в репозиторий в поясе запроса
+func inZone(name string) any {
+    return mock.MatchedBy(func(day time.Time) bool { return day.Location().String() == name })
+}
+
+func TestDailyQuote_UsesTimezone(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockDailyRepository)
+    svc := NewDailyService(cfg, mockRepo)
+
+    mockRepo.On("DailyQuote", ctx, inZone("Asia/Tokyo")).
+        Return(&models.DailyQuote{Date: "2024-05-01", Quote: models.Quote{ID: 3}}, nil).Once()
+
+    dq, err := svc.DailyQuote(ctx, "Asia/Tokyo", "")
+    require.NoError(t, err)
+    require.Equal(t, "Asia/Tokyo", dq.Timezone)
+    require.Equal(t, 3, dq.Quote.ID)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestDailyQuote_DefaultTimezone(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Daily.Timezone = "Europe/Moscow"
+    mockRepo := new(MockDailyRepository)
+    svc := NewDailyService(cfg, mockRepo)
+
+    mockRepo.On("DailyQuote", ctx, inZone("Europe/Moscow")).
+        Return(&models.DailyQuote{Date: "2024-05-01"}, nil).Once()
+
+    _, err := svc.DailyQuote(ctx, "", "")
+    require.NoError(t, err)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestDailyQuote_InvalidTimezone(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockDailyRepository)
+    svc := NewDailyService(cfg, mockRepo)
+
+    for _, tz := range []string{"Mars/Olympus", "Local"} {
+        _, err := svc.DailyQuote(ctx, tz, "")
+        require.ErrorIs(t, err, errdefs.ErrInvalidInput, tz)
+    }
+
+    mockRepo.AssertNotCalled(t, "DailyQuote", mock.Anything, mock.Anything)
+}
+
+func TestDailyQuote_OnThisDay(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockDailyRepository)
+    svc := NewDailyService(cfg, mockRepo)
+
+    year := time.Now().UTC().Year()
+    mockRepo.On("OnThisDayQuote", ctx, mock.Anything).
+        Return((*models.DailyQuote)(nil), errdefs.ErrNotFound).Once()
+    mockRepo.On("OnThisDay", ctx, mock.Anything).Return(&[]models.AnniversaryQuote{
+        {Anniversary: models.Anniversary{Event: models.AnniversaryBorn, Date: "1828-09-09"}, Quote: models.Quote{ID: 10}},
+        {Anniversary: models.Anniversary{Event: models.AnniversaryDied, Date: "1910-11-20"}, Quote: models.Quote{ID: 11}},
+    }, nil).Once()
+    // сохраняется именно та цитата, что будет показана
+    mockRepo.On("SaveOnThisDayQuote", ctx, mock.Anything, mock.Anything).
+        Return(func(_ context.Context, day time.Time, aq *models.AnniversaryQuote) *models.DailyQuote {
+            return &models.DailyQuote{Date: day.Format(time.DateOnly), Mode: models.DailyOnThisDay,
+                Anniversary: &aq.Anniversary, Quote: aq.Quote}
+        }, nil).Once()
+
+    dq, err := svc.DailyQuote(ctx, "", models.DailyOnThisDay)
+    require.NoError(t, err)
+    require.Equal(t, models.DailyOnThisDay, dq.Mode)
+    require.Contains(t, []int{10, 11}, dq.Quote.ID)
+    require.NotNil(t, dq.Anniversary)
+    if dq.Quote.ID == 10 {
+        require.Equal(t, year-1828, dq.Anniversary.Years)
+    }
+
+    // выбранная сегодня цитата юбиляра не выбирается заново
+    mockRepo.On("OnThisDayQuote", ctx, mock.Anything).Return(dq, nil).Once()
+    again, err := svc.DailyQuote(ctx, "", models.DailyOnThisDay)
+    require.NoError(t, err)
+    require.Equal(t, dq.Quote.ID, again.Quote.ID)
+
+    _, err = svc.DailyQuote(ctx, "", "random")
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    // цитата по кругу при годовщинах не выбирается и круг не расходует
+    mockRepo.AssertNotCalled(t, "DailyQuote", mock.Anything, mock.Anything)
+    mockRepo.AssertExpectations(t)
+}
+
+func TestDailyQuote_OnThisDayFallback(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Daily.Mode = models.DailyOnThisDay
+    mockRepo := new(MockDailyRepository)
+    svc := NewDailyService(cfg, mockRepo)
+
+    // годовщин нет — цитата по кругу
+    mockRepo.On("OnThisDayQuote", ctx, mock.Anything).
+        Return((*models.DailyQuote)(nil), errdefs.ErrNotFound).Once()
+    mockRepo.On("OnThisDay", ctx, mock.Anything).Return(&[]models.AnniversaryQuote{}, nil).Once()
+    mockRepo.On("DailyQuote", ctx, mock.Anything).
+        Return(&models.DailyQuote{Date: "2024-05-01", Mode: models.DailyRotation, Quote: models.Quote{ID: 3}}, nil).Once()
+    dq, err := svc.DailyQuote(ctx, "", "")
+    require.NoError(t, err)
+    require.Equal(t, models.DailyRotation, dq.Mode)
+    require.Equal(t, 3, dq.Quote.ID)
+    require.Nil(t, dq.Anniversary)
+
+    // назначенную администратором цитату годовщина не вытесняет
+    mockRepo.On("OnThisDayQuote", ctx, mock.Anything).
+        Return(&models.DailyQuote{Date: "2024-05-02", Pinned: true, Mode: models.DailyRotation, Quote: models.Quote{ID: 4}}, nil).Once()
+    dq, err = svc.DailyQuote(ctx, "", "")
+    require.NoError(t, err)
+    require.Equal(t, 4, dq.Quote.ID)
+
+    mockRepo.AssertNotCalled(t, "SaveOnThisDayQuote", mock.Anything, mock.Anything, mock.Anything)
+    mockRepo.AssertExpectations(t)
+}
+
+func TestAnniversaryDays(t *testing.T) {
+    day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+    require.Equal(t, []string{"05-01"}, anniversaryDays(day(2024, time.May, 1)))
+    // 29 февраля в невисокосный год отмечается 28-го
+    require.Equal(t, []string{"02-28", "02-29"}, anniversaryDays(day(2025, time.February, 28)))
+    require.Equal(t, []string{"02-28"}, anniversaryDays(day(2024, time.February, 28)))
+    require.Equal(t, []string{"02-29"}, anniversaryDays(day(2024, time.February, 29)))
+}
+
+func TestDailyHistory_Limit(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Daily.HistorySize = 14
+    mockRepo := new(MockDailyRepository)
+    svc := NewDailyService(cfg, mockRepo)
+
+    mockRepo.On("DailyHistory", ctx, mock.Anything, models.DailyRotation, 14).Return(&[]models.DailyQuote{{Date: "2024-05-01"}}, nil).Once()
+
+    history, err := svc.DailyHistory(ctx, "", "", 0)
+    require.NoError(t, err)
+    require.Equal(t, "UTC", (*history)[0].Timezone)
+
+    _, err = svc.DailyHistory(ctx, "", "", maxDailyHistory+1)
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    _, err = svc.DailyHistory(ctx, "", "random", 0)
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestPinDailyQuote(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockDailyRepository)
+    svc := NewDailyService(cfg, mockRepo)
+
+    day := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+    mockRepo.On("PinDailyQuote", ctx, day, 7).Return(&models.DailyQuote{Date: "2024-12-31", Pinned: true}, nil).Once()
+
+    dq, err := svc.PinDailyQuote(ctx, "2024-12-31", 7)
+    require.NoError(t, err)
+    require.True(t, dq.Pinned)
+
+    _, err = svc.PinDailyQuote(ctx, "31.12.2024", 7)
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    _, err = svc.PinDailyQuote(ctx, "2024-12-31", 0)
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+
+    "quotebook/internal/langdetect"
+    "quotebook/internal/models"
+)
+
+// revisionLines раскладывает снимок цитаты на строки для сравнения
+func revisionLines(r *models.QuoteRevision) []string {
+    lines := []string{"author: " + r.Author}
+    // у старых цитат тегов нет, строку не показываем
+    if len(r.Tags) > 0 {
+        lines = append(lines, "tags: "+strings.Join(r.Tags, ", "))
+    }
+    if r.Source != nil {
+        lines = append(lines, "source: "+sourceLine(r.Source))
+    }
+    // вес и язык по умолчанию не показываем, как и в старых ревизиях без них
+    if r.Weight != nil && *r.Weight != 1 {
+        lines = append(lines, "weight: "+strconv.FormatFloat(*r.Weight, 'g', -1, 64))
+    }
+    if r.Lang != "" && r.Lang != langdetect.Undetermined {
+        lines = append(lines, "lang: "+r.Lang)
+    }
+    return append(lines, strings.Split(r.Quote, "\n")...)
+}
+
+// sourceLine источник одной строкой: заданные поля через запятую
+func sourceLine(s *models.Source) string {
+    parts := []string{s.Type, s.Title}
+    if s.Year != 0 {
+        parts = append(parts, strconv.Itoa(s.Year))
+    }
+    for _, p := range []string{s.Publisher, s.Page, s.URL, s.ISBN} {
+        if p != "" {
+            parts = append(parts, p)
+        }
+    }
+    return strings.Join(parts, ", ")
+}
+
+// diffLines строит построчный diff по наибольшей общей подпоследовательности.
+// Цитаты короткие, поэтому квадратичной таблицы достаточно.
+func diffLines(a, b []string) []string {
+    lcs := make([][]int, len(a)+1)
+    for i := range lcs {
+        lcs[i] = make([]int, len(b)+1)
+    }
+    for i := len(a) - 1; i >= 0; i-- {
+        for j := len(b) - 1; j >= 0; j-- {
+            if a[i] == b[j] {
+                lcs[i][j] = lcs[i+1][j+1] + 1
+            } else {
+                lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+            }
+        }
+    }
+
+    var out []string
+    i, j := 0, 0
+    for i < len(a) && j < len(b) {
+        switch {
+        case a[i] == b[j]:
+            out = append(out, " "+a[i])
+            i++
+            j++
+        case lcs[i+1][j] >= lcs[i][j+1]:
+            out = append(out, "-"+a[i])
+            i++
+        default:
+            out = append(out, "+"+b[j])
+            j++
+        }
+    }
+    for ; i < len(a); i++ {
+        out = append(out, "-"+a[i])
+    }
+    for ; j < len(b); j++ {
+        out = append(out, "+"+b[j])
+    }
+    return out
+}
+
+// DiffRevisions возвращает текстовый diff в духе unified diff между двумя ревизиями
+func DiffRevisions(from, to *models.QuoteRevision) string {
+    var sb strings.Builder
+    fmt.Fprintf(&sb, "--- quote %d rev %d (%s by %s)\n", from.QuoteID, from.Rev, from.Action, from.Actor)
+    fmt.Fprintf(&sb, "+++ quote %d rev %d (%s by %s)\n", to.QuoteID, to.Rev, to.Action, to.Actor)
+    for _, line := range diffLines(revisionLines(from), revisionLines(to)) {
+        sb.WriteString(line)
+        sb.WriteByte('\n')
+    }
+    return sb.String()
+}
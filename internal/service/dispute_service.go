@@ -0,0 +1,140 @@
+package service
+
+import (
+    "context"
+    "strings"
+    "unicode/utf8"
+
+    "quotebook/config"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/identity"
+    "quotebook/internal/interfaces"
+    "quotebook/internal/models"
+)
+
+const (
+    maxDisputeText       = 2000
+    defaultDisputesLimit = 50
+    maxDisputesLimit     = 500
+)
+
+var attributions = map[string]bool{
+    models.AttributionVerified: true, models.AttributionDisputed: true,
+    models.AttributionMisattributed: true, models.AttributionUnknown: true,
+}
+
+type DisputeService struct {
+    repo interfaces.IDisputeRepository
+    cfg *config.Config
+}
+
+func NewDisputeService(cfg *config.Config, repo interfaces.IDisputeRepository) DisputeService {
+    return DisputeService{
+        repo: repo,
+        cfg: cfg,
+    }
+}
+
+func validateAttribution(a string) error {
+    if !attributions[a] {
+        return errdefs.Wrapf(errdefs.ErrInvalidInput,
+            "attribution must be verified, disputed, misattributed or unknown, got %q", a)
+    }
+    return nil
+}
+
+func validateDisputeText(field, s string) error {
+    if utf8.RuneCountInString(s) > maxDisputeText {
+        return errdefs.Wrapf(errdefs.ErrInvalidInput, "%s is longer than %d characters", field, maxDisputeText)
+    }
+    return nil
+}
+
+// FileDispute оспаривание авторства цитаты от текущего пользователя
+func (ds DisputeService) FileDispute(ctx context.Context, quoteID int, d *models.Dispute) (*models.Dispute, error) {
+    p, err := principal(ctx)
+    if err != nil {
+        return nil, err
+    }
+    d.Proposed = strings.ToLower(strings.TrimSpace(d.Proposed))
+    if err := validateAttribution(d.Proposed); err != nil {
+        return nil, err
+    }
+    d.Reason = strings.TrimSpace(d.Reason)
+    if d.Reason == "" {
+        return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "reason is required")
+    }
+    if err := validateDisputeText("reason", d.Reason); err != nil {
+        return nil, err
+    }
+    d.Evidence = strings.TrimSpace(d.Evidence)
+    if err := validateDisputeText("evidence", d.Evidence); err != nil {
+        return nil, err
+    }
+
+    d.QuoteID = quoteID
+    d.FiledBy = p
+    if err := ds.repo.CreateDispute(ctx, d); err != nil {
+        return nil, err
+    }
+    return d, nil
+}
+
+func (ds DisputeService) QuoteDisputes(ctx context.Context, quoteID int) (*[]models.Dispute, error) {
+    return ds.repo.QuoteDisputes(ctx, quoteID)
+}
+
+// Disputes очередь проверяющего, status по умолчанию open
+func (ds DisputeService) Disputes(ctx context.Context, status string, limit int) (*[]models.Dispute, error) {
+    switch status {
+    case "":
+        status = models.DisputeOpen
+    case models.DisputeOpen, models.DisputeAccepted, models.DisputeRejected:
+    default:
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "status must be open, accepted or rejected, got %q", status)
+    }
+    if limit == 0 {
+        limit = defaultDisputesLimit
+    }
+    if limit < 0 || limit > maxDisputesLimit {
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "limit must be between 1 and %d", maxDisputesLimit)
+    }
+    return ds.repo.Disputes(ctx, status, limit)
+}
+
+// ResolveDispute решение проверяющего; проверяющий — X-User запроса к /admin
+func (ds DisputeService) ResolveDispute(ctx context.Context, id int, r *models.DisputeReview) (*models.Dispute, error) {
+    switch r.Decision {
+    case "accept":
+        r.Decision = models.DisputeAccepted
+    case "reject":
+        r.Decision = models.DisputeRejected
+    default:
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "decision must be accept or reject, got %q", r.Decision)
+    }
+    if r.Attribution != "" {
+        if r.Decision == models.DisputeRejected {
+            return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "attribution can only be set when accepting")
+        }
+        if err := validateAttribution(r.Attribution); err != nil {
+            return nil, err
+        }
+    }
+    r.Note = strings.TrimSpace(r.Note)
+    if err := validateDisputeText("note", r.Note); err != nil {
+        return nil, err
+    }
+    return ds.repo.ResolveDispute(ctx, id, identity.ActorFromCtx(ctx), r)
+}
+
+// SetAttribution статус авторства, выставленный проверяющим напрямую
+func (ds DisputeService) SetAttribution(ctx context.Context, quoteID int, u *models.AttributionUpdate) (*models.Quote, error) {
+    if err := validateAttribution(u.Attribution); err != nil {
+        return nil, err
+    }
+    u.Note = strings.TrimSpace(u.Note)
+    if err := validateDisputeText("note", u.Note); err != nil {
+        return nil, err
+    }
+    return ds.repo.SetAttribution(ctx, quoteID, u)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+    "context"
+    "testing"
+
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/identity"
+    "quotebook/internal/models"
+)
+
+type MockDisputeRepository struct {
+    mock.Mock
+}
+
+func (m *MockDisputeRepository) CreateDispute(ctx context.Context, d *models.Dispute) error {
+    args := m.Called(ctx, d)
+    return args.Error(0)
+}
+
+func (m *MockDisputeRepository) QuoteDisputes(ctx context.Context, quoteID int) (*[]models.Dispute, error) {
+    args := m.Called(ctx, quoteID)
+    return args.Get(0).(*[]models.Dispute), args.Error(1)
+}
+
+func (m *MockDisputeRepository) Disputes(ctx context.Context, status string, limit int) (*[]models.Dispute, error) {
+    args := m.Called(ctx, status, limit)
+    return args.Get(0).(*[]models.Dispute), args.Error(1)
+}
+
+func (m *MockDisputeRepository) ResolveDispute(ctx context.Context, id int, reviewer string, r *models.DisputeReview) (*models.Dispute, error) {
+    args := m.Called(ctx, id, reviewer, r)
+    return args.Get(0).(*models.Dispute), args.Error(1)
+}
+
+func (m *MockDisputeRepository) SetAttribution(ctx context.Context, quoteID int, u *models.AttributionUpdate) (*models.Quote, error) {
+    args := m.Called(ctx, quoteID, u)
+    return args.Get(0).(*models.Quote), args.Error(1)
+}
+
+func TestFileDispute_Validation(t *testing.T) {
+    ctx := identity.CtxWithActor(context.Background(), "alice")
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockDisputeRepository)
+    svc := NewDisputeService(cfg, mockRepo)
+
+    _, err := svc.FileDispute(context.Background(), 1, &models.Dispute{Proposed: "misattributed", Reason: "x"})
+    require.ErrorIs(t, err, errdefs.ErrUnauthorized)
+    _, err = svc.FileDispute(ctx, 1, &models.Dispute{Proposed: "fake", Reason: "x"})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    _, err = svc.FileDispute(ctx, 1, &models.Dispute{Proposed: "misattributed", Reason: "  "})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.On("CreateDispute", ctx, mock.MatchedBy(func(d *models.Dispute) bool {
+        return d.QuoteID == 1 && d.FiledBy == "alice" && d.Proposed == models.AttributionMisattributed
+    })).Return(nil).Once()
+
+    d, err := svc.FileDispute(ctx, 1, &models.Dispute{FiledBy: "mallory", Proposed: " Misattributed ", Reason: "Not in any of his works"})
+    require.NoError(t, err)
+    require.Equal(t, "alice", d.FiledBy)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestResolveDispute_Decision(t *testing.T) {
+    ctx := identity.CtxWithActor(context.Background(), "reviewer")
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockDisputeRepository)
+    svc := NewDisputeService(cfg, mockRepo)
+
+    _, err := svc.ResolveDispute(ctx, 3, &models.DisputeReview{Decision: "maybe"})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    _, err = svc.ResolveDispute(ctx, 3, &models.DisputeReview{Decision: "reject", Attribution: "verified"})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.On("ResolveDispute", ctx, 3, "reviewer", &models.DisputeReview{Decision: models.DisputeAccepted, Note: "checked"}).
+        Return(&models.Dispute{ID: 3, Status: models.DisputeAccepted}, nil).Once()
+
+    d, err := svc.ResolveDispute(ctx, 3, &models.DisputeReview{Decision: "accept", Note: " checked "})
+    require.NoError(t, err)
+    require.Equal(t, models.DisputeAccepted, d.Status)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestDisputes_DefaultsToOpen(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockDisputeRepository)
+    svc := NewDisputeService(cfg, mockRepo)
+
+    mockRepo.On("Disputes", ctx, models.DisputeOpen, defaultDisputesLimit).Return(&[]models.Dispute{}, nil).Once()
+
+    _, err := svc.Disputes(ctx, "", 0)
+    require.NoError(t, err)
+    _, err = svc.Disputes(ctx, "closed", 0)
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+    "context"
+
+    "quotebook/config"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/identity"
+    "quotebook/internal/interfaces"
+    "quotebook/internal/models"
+)
+
+const (
+    minStars = 1
+    maxStars = 5
+)
+
+type EngagementService struct {
+    repo interfaces.IEngagementRepository
+    cfg *config.Config
+}
+
+func NewEngagementService(cfg *config.Config, repo interfaces.IEngagementRepository) EngagementService {
+    return EngagementService{
+        repo: repo,
+        cfg: cfg,
+    }
+}
+
+// principal пользователь из X-User; избранное и оценки анонимам не положены
+func principal(ctx context.Context) (string, error) {
+    p := identity.ActorFromCtx(ctx)
+    if p == identity.Anonymous {
+        return "", errdefs.Wrap(errdefs.ErrUnauthorized, "X-User header is required")
+    }
+    return p, nil
+}
+
+func (es EngagementService) Favorite(ctx context.Context, id int) (*models.Engagement, error) {
+    p, err := principal(ctx)
+    if err != nil {
+        return nil, err
+    }
+    return es.repo.AddFavorite(ctx, id, p)
+}
+
+func (es EngagementService) Unfavorite(ctx context.Context, id int) (*models.Engagement, error) {
+    p, err := principal(ctx)
+    if err != nil {
+        return nil, err
+    }
+    return es.repo.RemoveFavorite(ctx, id, p)
+}
+
+// Rate оценка от 1 до 5, повторная оценка заменяет прежнюю
+func (es EngagementService) Rate(ctx context.Context, id, stars int) (*models.Engagement, error) {
+    p, err := principal(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if stars < minStars || stars > maxStars {
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "stars must be from %d to %d", minStars, maxStars)
+    }
+    return es.repo.RateQuote(ctx, id, p, stars)
+}
+
+func (es EngagementService) Unrate(ctx context.Context, id int) (*models.Engagement, error) {
+    p, err := principal(ctx)
+    if err != nil {
+        return nil, err
+    }
+    return es.repo.RemoveRating(ctx, id, p)
+}
+
+// Favorites избранное текущего пользователя
+func (es EngagementService) Favorites(ctx context.Context) (*[]models.Quote, error) {
+    p, err := principal(ctx)
+    if err != nil {
+        return nil, err
+    }
+    return es.repo.FavoriteQuotes(ctx, p)
+}
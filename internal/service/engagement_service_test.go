@@ -0,0 +1,113 @@
+package service
+
+import (
+    "context"
+    "testing"
+
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/identity"
+    "quotebook/internal/models"
+)
+
+type MockEngagementRepository struct {
+    mock.Mock
+}
+
+func (m *MockEngagementRepository) AddFavorite(ctx context.Context, id int, principal string) (*models.Engagement, error) {
+    args := m.Called(ctx, id, principal)
+    return args.Get(0).(*models.Engagement), args.Error(1)
+}
+
+func (m *MockEngagementRepository) RemoveFavorite(ctx context.Context, id int, principal string) (*models.Engagement, error) {
+    args := m.Called(ctx, id, principal)
+    return args.Get(0).(*models.Engagement), args.Error(1)
+}
+
+func (m *MockEngagementRepository) RateQuote(ctx context.Context, id int, principal string, stars int) (*models.Engagement, error) {
+    args := m.Called(ctx, id, principal, stars)
+    return args.Get(0).(*models.Engagement), args.Error(1)
+}
+
+func (m *MockEngagementRepository) RemoveRating(ctx context.Context, id int, principal string) (*models.Engagement, error) {
+    args := m.Called(ctx, id, principal)
+    return args.Get(0).(*models.Engagement), args.Error(1)
+}
+
+func (m *MockEngagementRepository) FavoriteQuotes(ctx context.Context, principal string) (*[]models.Quote, error) {
+    args := m.Called(ctx, principal)
+    return args.Get(0).(*[]models.Quote), args.Error(1)
+}
+
+func TestFavorite_UsesPrincipal(t *testing.T) {
+    ctx := identity.CtxWithActor(context.Background(), "alice")
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockEngagementRepository)
+    svc := NewEngagementService(cfg, mockRepo)
+
+    mockRepo.On("AddFavorite", ctx, 7, "alice").
+        Return(&models.Engagement{QuoteID: 7, Favorites: 1, Favorited: true}, nil).Once()
+
+    e, err := svc.Favorite(ctx, 7)
+    require.NoError(t, err)
+    require.True(t, e.Favorited)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestEngagement_AnonymousUnauthorized(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockEngagementRepository)
+    svc := NewEngagementService(cfg, mockRepo)
+
+    _, err := svc.Favorite(ctx, 7)
+    require.ErrorIs(t, err, errdefs.ErrUnauthorized)
+    _, err = svc.Rate(ctx, 7, 5)
+    require.ErrorIs(t, err, errdefs.ErrUnauthorized)
+    _, err = svc.Favorites(ctx)
+    require.ErrorIs(t, err, errdefs.ErrUnauthorized)
+
+    mockRepo.AssertNotCalled(t, "AddFavorite", mock.Anything, mock.Anything, mock.Anything)
+    mockRepo.AssertNotCalled(t, "RateQuote", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRate_StarsRange(t *testing.T) {
+    ctx := identity.CtxWithActor(context.Background(), "alice")
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockEngagementRepository)
+    svc := NewEngagementService(cfg, mockRepo)
+
+    for _, stars := range []int{0, 6, -1} {
+        _, err := svc.Rate(ctx, 7, stars)
+        require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    }
+
+    mockRepo.On("RateQuote", ctx, 7, "alice", 4).
+        Return(&models.Engagement{QuoteID: 7, RatingAvg: 4, RatingCount: 1, MyRating: 4}, nil).Once()
+    e, err := svc.Rate(ctx, 7, 4)
+    require.NoError(t, err)
+    require.Equal(t, 4, e.MyRating)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestSortedQuotes_UnknownSort(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    _, err := svc.SortedQuotes(ctx, "likes")
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    quotes := &[]models.Quote{{ID: 2, Favorites: 3}, {ID: 1}}
+    mockRepo.On("SortedQuotes", ctx, models.SortPopular).Return(quotes, nil).Once()
+    got, err := svc.SortedQuotes(ctx, models.SortPopular)
+    require.NoError(t, err)
+    require.Equal(t, quotes, got)
+
+    mockRepo.AssertExpectations(t)
+}
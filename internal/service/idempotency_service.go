@@ -0,0 +1,78 @@
+package service
+
+import (
+    "context"
+    "time"
+
+    "quotebook/config"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/interfaces"
+    "quotebook/internal/models"
+)
+
+const maxIdempotencyKeyLen = 255
+
+type IdempotencyService struct {
+    repo interfaces.IIdempotencyRepository
+    cfg *config.Config
+}
+
+func NewIdempotencyService(cfg *config.Config, repo interfaces.IIdempotencyRepository) IdempotencyService {
+    return IdempotencyService{
+        repo: repo,
+        cfg: cfg,
+    }
+}
+
+// Enabled ключи игнорируются, если TTL не задан
+func (is IdempotencyService) Enabled() bool {
+    return is.cfg.Idempotency.TTL > 0
+}
+
+// Begin занимает ключ. Возвращает nil, если запрос надо выполнить,
+// или сохранённый результат, если ключ уже использован.
+func (is IdempotencyService) Begin(ctx context.Context, principal, key string) (*models.IdempotencyRecord, error) {
+    if len(key) > maxIdempotencyKeyLen {
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "Idempotency-Key longer than %d", maxIdempotencyKeyLen)
+    }
+
+    expiresAt := time.Now().Add(time.Duration(is.cfg.Idempotency.TTL))
+    rec, reserved, err := is.repo.ReserveKey(ctx, principal, key, expiresAt)
+    if err != nil {
+        return nil, err
+    }
+    if reserved {
+        return nil, nil
+    }
+    if !rec.Completed {
+        return nil, errdefs.Wrap(errdefs.ErrConflict, "request with this Idempotency-Key is in progress")
+    }
+    return rec, nil
+}
+
+// CheckReplay повтор допустим только с тем же методом, адресом и телом
+func (is IdempotencyService) CheckReplay(rec *models.IdempotencyRecord, fingerprint string) error {
+    if rec.Fingerprint != fingerprint {
+        return errdefs.ErrIdempotencyMismatch
+    }
+    return nil
+}
+
+// Complete сохраняет ответ 2xx или 4xx: повтор получил бы тот же результат.
+// Ошибки сервера (временные), прочие коды и слишком большие ответы не
+// сохраняются: ключ освобождается, и повтор выполнится заново.
+func (is IdempotencyService) Complete(ctx context.Context, rec *models.IdempotencyRecord) error {
+    class := rec.Status / 100
+    if (class != 2 && class != 4) || len(rec.Body) > is.cfg.Idempotency.MaxResponseSize {
+        return is.repo.ReleaseKey(ctx, rec.Principal, rec.Key)
+    }
+    return is.repo.CompleteKey(ctx, rec)
+}
+
+func (is IdempotencyService) Release(ctx context.Context, principal, key string) error {
+    return is.repo.ReleaseKey(ctx, principal, key)
+}
+
+func (is IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+    return is.repo.PurgeExpiredKeys(ctx, time.Now())
+}
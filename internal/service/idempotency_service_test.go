@@ -0,0 +1,130 @@
+package service
+
+import (
+    "context"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+type MockIdempotencyRepository struct {
+    mock.Mock
+}
+
+func (m *MockIdempotencyRepository) ReserveKey(ctx context.Context, principal, key string, expiresAt time.Time) (*models.IdempotencyRecord, bool, error) {
+    args := m.Called(ctx, principal, key, expiresAt)
+    return args.Get(0).(*models.IdempotencyRecord), args.Bool(1), args.Error(2)
+}
+
+func (m *MockIdempotencyRepository) CompleteKey(ctx context.Context, rec *models.IdempotencyRecord) error {
+    args := m.Called(ctx, rec)
+    return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) ReleaseKey(ctx context.Context, principal, key string) error {
+    args := m.Called(ctx, principal, key)
+    return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) PurgeExpiredKeys(ctx context.Context, now time.Time) (int64, error) {
+    args := m.Called(ctx, now)
+    return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotencyBegin_Reserved(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockIdempotencyRepository)
+    svc := NewIdempotencyService(cfg, mockRepo)
+
+    mockRepo.On("ReserveKey", ctx, "alice", "k1", mock.Anything).
+        Return((*models.IdempotencyRecord)(nil), true, nil).Once()
+
+    rec, err := svc.Begin(ctx, "alice", "k1")
+    require.NoError(t, err)
+    require.Nil(t, rec)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyBegin_InProgress(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockIdempotencyRepository)
+    svc := NewIdempotencyService(cfg, mockRepo)
+
+    mockRepo.On("ReserveKey", ctx, "alice", "k1", mock.Anything).
+        Return(&models.IdempotencyRecord{Completed: false}, false, nil).Once()
+
+    _, err := svc.Begin(ctx, "alice", "k1")
+    require.ErrorIs(t, err, errdefs.ErrConflict)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyBegin_Replay(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockIdempotencyRepository)
+    svc := NewIdempotencyService(cfg, mockRepo)
+
+    stored := &models.IdempotencyRecord{Completed: true, Fingerprint: "fp", Status: 201, Body: []byte("7\n")}
+    mockRepo.On("ReserveKey", ctx, "alice", "k1", mock.Anything).Return(stored, false, nil).Once()
+
+    rec, err := svc.Begin(ctx, "alice", "k1")
+    require.NoError(t, err)
+    require.Equal(t, stored, rec)
+
+    require.NoError(t, svc.CheckReplay(rec, "fp"))
+    require.ErrorIs(t, svc.CheckReplay(rec, "other"), errdefs.ErrIdempotencyMismatch)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyBegin_KeyTooLong(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockIdempotencyRepository)
+    svc := NewIdempotencyService(cfg, mockRepo)
+
+    _, err := svc.Begin(ctx, "alice", strings.Repeat("k", maxIdempotencyKeyLen+1))
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.AssertNotCalled(t, "ReserveKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyComplete(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Idempotency.MaxResponseSize = 4
+    mockRepo := new(MockIdempotencyRepository)
+    svc := NewIdempotencyService(cfg, mockRepo)
+
+    ok := &models.IdempotencyRecord{Principal: "alice", Key: "ok", Status: 201, Body: []byte("7\n")}
+    mockRepo.On("CompleteKey", ctx, ok).Return(nil).Once()
+    require.NoError(t, svc.Complete(ctx, ok))
+
+    // ошибка клиента повторится и при повторе, её запоминаем
+    rejected := &models.IdempotencyRecord{Principal: "alice", Key: "rejected", Status: 400, Body: []byte("{}")}
+    mockRepo.On("CompleteKey", ctx, rejected).Return(nil).Once()
+    require.NoError(t, svc.Complete(ctx, rejected))
+
+    // ошибку сервера и прочие коды не запоминаем, повтор должен выполниться заново
+    for _, status := range []int{500, 503, 302} {
+        failed := &models.IdempotencyRecord{Principal: "alice", Key: "failed", Status: status}
+        mockRepo.On("ReleaseKey", ctx, "alice", "failed").Return(nil).Once()
+        require.NoError(t, svc.Complete(ctx, failed))
+    }
+
+    large := &models.IdempotencyRecord{Principal: "alice", Key: "large", Status: 200, Body: []byte("12345")}
+    mockRepo.On("ReleaseKey", ctx, "alice", "large").Return(nil).Once()
+    require.NoError(t, svc.Complete(ctx, large))
+
+    mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+    "bufio"
+    "context"
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "time"
+    "unicode/utf8"
+
+    "quotebook/config"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/identity"
+    "quotebook/internal/interfaces"
+    "quotebook/internal/langdetect"
+    "quotebook/internal/logger"
+    "quotebook/internal/models"
+
+    "github.com/google/uuid"
+    "go.uber.org/zap"
+)
+
+const (
+    defaultImportBatch = 1000
+    maxAuthorLen       = 255
+    // самая длинная строка NDJSON
+    maxNDJSONLine = 1 << 20
+)
+
+// rowError ошибка одной строки: строка пропускается, импорт продолжается
+type rowError struct {
+    row int
+    msg string
+}
+
+func (e *rowError) Error() string {
+    return fmt.Sprintf("row %d: %s", e.row, e.msg)
+}
+
+// rowSource потоково отдаёт строки файла; в конце всегда io.EOF
+type rowSource interface {
+    next() (models.ImportRow, error)
+}
+
+type importRecord struct {
+    Author string `json:"author"`
+    Quote  string `json:"quote"`
+}
+
+// csvSource первая строка — заголовок с колонками author и quote в любом порядке
+type csvSource struct {
+    r                   *csv.Reader
+    authorCol, quoteCol int
+}
+
+func newCSVSource(body io.Reader) (*csvSource, error) {
+    r := csv.NewReader(body)
+    r.FieldsPerRecord = -1
+    r.ReuseRecord = true
+    header, err := r.Read()
+    if err != nil {
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "failed to read CSV header: %v", err)
+    }
+
+    src := &csvSource{r: r, authorCol: -1, quoteCol: -1}
+    for i, name := range header {
+        switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+        case "author":
+            src.authorCol = i
+        case "quote":
+            src.quoteCol = i
+        }
+    }
+    if src.authorCol < 0 || src.quoteCol < 0 {
+        return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "CSV header must contain author and quote columns")
+    }
+    return src, nil
+}
+
+func (s *csvSource) next() (models.ImportRow, error) {
+    record, err := s.r.Read()
+    if err == io.EOF {
+        return models.ImportRow{}, io.EOF
+    }
+    if err != nil {
+        var perr *csv.ParseError
+        if errors.As(err, &perr) {
+            return models.ImportRow{}, errdefs.Wrapf(errdefs.ErrInvalidInput, "malformed CSV at line %d: %v", perr.Line, perr.Err)
+        }
+        return models.ImportRow{}, errdefs.Wrapf(errdefs.ErrInvalidInput, "failed to read CSV: %v", err)
+    }
+
+    line, _ := s.r.FieldPos(0)
+    if len(record) <= max(s.authorCol, s.quoteCol) {
+        return models.ImportRow{}, &rowError{row: line, msg: "missing columns"}
+    }
+    return models.ImportRow{Row: line, Author: record[s.authorCol], Quote: record[s.quoteCol]}, nil
+}
+
+// jsonSource массив объектов, читается по одному элементу
+type jsonSource struct {
+    dec     *json.Decoder
+    started bool
+    idx     int
+}
+
+func (s *jsonSource) next() (models.ImportRow, error) {
+    if !s.started {
+        tok, err := s.dec.Token()
+        if delim, ok := tok.(json.Delim); err != nil || !ok || delim != '[' {
+            return models.ImportRow{}, errdefs.Wrap(errdefs.ErrInvalidInput, "JSON body must be an array")
+        }
+        s.started = true
+    }
+    if !s.dec.More() {
+        return models.ImportRow{}, io.EOF
+    }
+
+    s.idx++
+    var rec importRecord
+    if err := s.dec.Decode(&rec); err != nil {
+        // после битого элемента массив дальше не разобрать
+        return models.ImportRow{}, errdefs.Wrapf(errdefs.ErrInvalidInput, "malformed JSON at element %d: %v", s.idx, err)
+    }
+    return models.ImportRow{Row: s.idx, Author: rec.Author, Quote: rec.Quote}, nil
+}
+
+// ndjsonSource по объекту на строку, битая строка не мешает остальным
+type ndjsonSource struct {
+    sc   *bufio.Scanner
+    line int
+}
+
+func newNDJSONSource(body io.Reader) *ndjsonSource {
+    sc := bufio.NewScanner(body)
+    sc.Buffer(make([]byte, 64*1024), maxNDJSONLine)
+    return &ndjsonSource{sc: sc}
+}
+
+func (s *ndjsonSource) next() (models.ImportRow, error) {
+    for s.sc.Scan() {
+        s.line++
+        text := strings.TrimSpace(s.sc.Text())
+        if text == "" {
+            continue
+        }
+        var rec importRecord
+        if err := json.Unmarshal([]byte(text), &rec); err != nil {
+            return models.ImportRow{}, &rowError{row: s.line, msg: "malformed JSON"}
+        }
+        return models.ImportRow{Row: s.line, Author: rec.Author, Quote: rec.Quote}, nil
+    }
+    if err := s.sc.Err(); err != nil {
+        return models.ImportRow{}, errdefs.Wrapf(errdefs.ErrInvalidInput, "failed to read NDJSON at line %d: %v", s.line+1, err)
+    }
+    return models.ImportRow{}, io.EOF
+}
+
+func newRowSource(format string, body io.Reader) (rowSource, error) {
+    switch format {
+    case models.FormatCSV:
+        return newCSVSource(body)
+    case models.FormatJSON:
+        return &jsonSource{dec: json.NewDecoder(body)}, nil
+    case models.FormatNDJSON:
+        return newNDJSONSource(body), nil
+    }
+    return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "unsupported import format %q", format)
+}
+
+// validateImportRow те же правила, что и для POST /quotes, плюс лимиты колонок
+func validateImportRow(row *models.ImportRow) error {
+    row.Author = strings.TrimSpace(row.Author)
+    row.Quote = strings.TrimSpace(row.Quote)
+    switch {
+    case row.Author == "":
+        return errors.New("author required")
+    case row.Quote == "":
+        return errors.New("quote required")
+    case utf8.RuneCountInString(row.Author) > maxAuthorLen:
+        return fmt.Errorf("author longer than %d characters", maxAuthorLen)
+    case !utf8.ValidString(row.Author) || !utf8.ValidString(row.Quote):
+        return errors.New("invalid UTF-8")
+    }
+    return nil
+}
+
+func validateImportOptions(opts *models.ImportOptions) error {
+    switch opts.Format {
+    case models.FormatCSV, models.FormatJSON, models.FormatNDJSON:
+    default:
+        return errdefs.Wrapf(errdefs.ErrInvalidInput, "unsupported import format %q", opts.Format)
+    }
+    if opts.OnDuplicate == "" {
+        opts.OnDuplicate = models.DuplicateSkip
+    }
+    switch opts.OnDuplicate {
+    case models.DuplicateSkip, models.DuplicateFail, models.DuplicateUpdate:
+    default:
+        return errdefs.Wrapf(errdefs.ErrInvalidInput, "unknown duplicate policy %q", opts.OnDuplicate)
+    }
+    return nil
+}
+
+type ImportService struct {
+    repo interfaces.IImportRepository
+    cfg *config.Config
+}
+
+func NewImportService(cfg *config.Config, repo interfaces.IImportRepository) ImportService {
+    return ImportService{
+        repo: repo,
+        cfg: cfg,
+    }
+}
+
+// Import разбирает body потоком и отдаёт строки в базу пачками по Import.BatchSize.
+// Невалидные строки пропускаются и попадают в Errors. При политике fail и
+// найденных дублях возвращается отчёт вместе с ErrConflict.
+func (is ImportService) Import(ctx context.Context, opts *models.ImportOptions, body io.Reader) (*models.ImportResult, error) {
+    if err := validateImportOptions(opts); err != nil {
+        return nil, err
+    }
+    src, err := newRowSource(opts.Format, body)
+    if err != nil {
+        return nil, err
+    }
+
+    batchSize := is.cfg.Import.BatchSize
+    if batchSize <= 0 {
+        batchSize = defaultImportBatch
+    }
+    res := &models.ImportResult{DryRun: opts.DryRun, Errors: []models.ImportRowError{}}
+    addError := func(row int, msg string) {
+        if len(res.Errors) < is.cfg.Import.MaxErrors {
+            res.Errors = append(res.Errors, models.ImportRowError{Row: row, Error: msg})
+        }
+    }
+
+    next := func() ([]models.ImportRow, error) {
+        batch := make([]models.ImportRow, 0, batchSize)
+        for len(batch) < batchSize {
+            row, err := src.next()
+            if err == io.EOF {
+                break
+            }
+            var rerr *rowError
+            if errors.As(err, &rerr) {
+                res.Total++
+                res.Invalid++
+                addError(rerr.row, rerr.msg)
+                continue
+            }
+            if err != nil {
+                return nil, err
+            }
+
+            res.Total++
+            if err := validateImportRow(&row); err != nil {
+                res.Invalid++
+                addError(row.Row, err.Error())
+                continue
+            }
+            row.Lang = langdetect.Detect(row.Quote)
+            batch = append(batch, row)
+        }
+        return batch, nil
+    }
+
+    counts, err := is.repo.ImportQuotes(ctx, opts, next)
+    if counts != nil {
+        res.ImportCounts = *counts
+        res.Skipped = counts.Duplicates - counts.Updated
+    }
+    if err != nil {
+        if counts != nil && errdefs.Is(err, errdefs.ErrConflict) {
+            // при fail ничего не записано, дубли — это ошибки
+            res.Skipped = 0
+            for _, row := range counts.DuplicateRows {
+                addError(row, "duplicate quote")
+            }
+            return res, err
+        }
+        return nil, err
+    }
+    return res, nil
+}
+
+// StartImport запускает импорт уже сохранённого на диск файла в фоне.
+// Файл удаляется после завершения.
+func (is ImportService) StartImport(ctx context.Context, opts *models.ImportOptions, path string) (*models.ImportJob, error) {
+    if err := validateImportOptions(opts); err != nil {
+        os.Remove(path)
+        return nil, err
+    }
+
+    job := &models.ImportJob{
+        ID:        uuid.NewString(),
+        Status:    models.ImportPending,
+        CreatedBy: identity.ActorFromCtx(ctx),
+    }
+    if err := is.repo.CreateImportJob(ctx, job); err != nil {
+        os.Remove(path)
+        return nil, err
+    }
+    created := *job
+
+    // задача переживает запрос, но не его логгер и RequestID
+    bg := context.WithoutCancel(ctx)
+    go is.runImport(bg, job, opts, path)
+
+    return &created, nil
+}
+
+func (is ImportService) runImport(ctx context.Context, job *models.ImportJob, opts *models.ImportOptions, path string) {
+    lg := logger.GetLoggerFromCtx(ctx)
+    defer os.Remove(path)
+
+    job.Status = models.ImportRunning
+    if err := is.repo.UpdateImportJob(ctx, job); err != nil {
+        lg.Error(ctx, "failed to update import job", zap.String("job", job.ID), zap.Error(err))
+    }
+
+    res, err := func() (*models.ImportResult, error) {
+        f, err := os.Open(path)
+        if err != nil {
+            return nil, fmt.Errorf("open spooled file: %w", err)
+        }
+        defer f.Close()
+        return is.Import(ctx, opts, f)
+    }()
+
+    now := time.Now()
+    job.FinishedAt = &now
+    job.Result = res
+    job.Status = models.ImportDone
+    if err != nil {
+        job.Status = models.ImportFailed
+        job.Error = err.Error()
+    }
+    if err := is.repo.UpdateImportJob(ctx, job); err != nil {
+        lg.Error(ctx, "failed to finish import job", zap.String("job", job.ID), zap.Error(err))
+        return
+    }
+    lg.Info(ctx, "import job finished", zap.String("job", job.ID), zap.String("status", job.Status))
+}
+
+func (is ImportService) ImportJob(ctx context.Context, id string) (*models.ImportJob, error) {
+    if _, err := uuid.Parse(id); err != nil {
+        return nil, errdefs.ErrNotFound
+    }
+    return is.repo.GetImportJob(ctx, id)
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+    "context"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+// MockImportRepository вычитывает все пачки, как настоящий репозиторий,
+// и запоминает строки, дошедшие до базы
+type MockImportRepository struct {
+    mock.Mock
+    rows    []models.ImportRow
+    batches int
+}
+
+func (m *MockImportRepository) ImportQuotes(ctx context.Context, opts *models.ImportOptions, next func() ([]models.ImportRow, error)) (*models.ImportCounts, error) {
+    for {
+        batch, err := next()
+        if err != nil {
+            return nil, err
+        }
+        if len(batch) == 0 {
+            break
+        }
+        m.batches++
+        m.rows = append(m.rows, batch...)
+    }
+    args := m.Called(ctx, opts)
+    return args.Get(0).(*models.ImportCounts), args.Error(1)
+}
+
+func (m *MockImportRepository) CreateImportJob(ctx context.Context, job *models.ImportJob) error {
+    args := m.Called(ctx, job)
+    return args.Error(0)
+}
+
+func (m *MockImportRepository) UpdateImportJob(ctx context.Context, job *models.ImportJob) error {
+    args := m.Called(ctx, job)
+    return args.Error(0)
+}
+
+func (m *MockImportRepository) GetImportJob(ctx context.Context, id string) (*models.ImportJob, error) {
+    args := m.Called(ctx, id)
+    return args.Get(0).(*models.ImportJob), args.Error(1)
+}
+
+func TestImport_CSV(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockImportRepository)
+    svc := NewImportService(cfg, mockRepo)
+
+    body := "quote,Author\n" +
+        "\"Hello, world\",Alice\n" +
+        ",Bob\n" +
+        "Only one column\n" +
+        "  Spaces  ,  Carol  \n"
+
+    opts := &models.ImportOptions{Format: models.FormatCSV}
+    mockRepo.On("ImportQuotes", ctx, opts).Return(&models.ImportCounts{Inserted: 2}, nil).Once()
+
+    res, err := svc.Import(ctx, opts, strings.NewReader(body))
+    require.NoError(t, err)
+    require.Equal(t, models.DuplicateSkip, opts.OnDuplicate)
+    require.Equal(t, 4, res.Total)
+    require.Equal(t, 2, res.Inserted)
+    require.Equal(t, 2, res.Invalid)
+    require.Equal(t, []models.ImportRowError{
+        {Row: 3, Error: "quote required"},
+        {Row: 4, Error: "missing columns"},
+    }, res.Errors)
+    require.Equal(t, []models.ImportRow{
+        {Row: 2, Author: "Alice", Quote: "Hello, world", Lang: "en"},
+        {Row: 5, Author: "Carol", Quote: "Spaces", Lang: "und"},
+    }, mockRepo.rows)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestImport_CSVWithoutHeader(t *testing.T) {
+    cfg := loadTestConfig(t)
+    svc := NewImportService(cfg, new(MockImportRepository))
+
+    _, err := svc.Import(context.Background(), &models.ImportOptions{Format: models.FormatCSV},
+        strings.NewReader("Alice,Hello\n"))
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+}
+
+func TestImport_JSON(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockImportRepository)
+    svc := NewImportService(cfg, mockRepo)
+
+    body := `[{"author": "Alice", "quote": "One"}, {"author": "", "quote": "Two"}]`
+    opts := &models.ImportOptions{Format: models.FormatJSON}
+    mockRepo.On("ImportQuotes", ctx, opts).Return(&models.ImportCounts{Inserted: 1}, nil).Once()
+
+    res, err := svc.Import(ctx, opts, strings.NewReader(body))
+    require.NoError(t, err)
+    require.Equal(t, 2, res.Total)
+    require.Equal(t, []models.ImportRowError{{Row: 2, Error: "author required"}}, res.Errors)
+    require.Len(t, mockRepo.rows, 1)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestImport_JSONMalformed(t *testing.T) {
+    cfg := loadTestConfig(t)
+    svc := NewImportService(cfg, new(MockImportRepository))
+
+    for _, body := range []string{`{"author": "Alice"}`, `[{"author": "Alice", "quote": "One"}, {"author": `} {
+        _, err := svc.Import(context.Background(), &models.ImportOptions{Format: models.FormatJSON},
+            strings.NewReader(body))
+        require.ErrorIs(t, err, errdefs.ErrInvalidInput, body)
+    }
+}
+
+func TestImport_NDJSONBatches(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Import.BatchSize = 2
+    mockRepo := new(MockImportRepository)
+    svc := NewImportService(cfg, mockRepo)
+
+    body := `{"author": "A", "quote": "1"}` + "\n" +
+        "\n" +
+        `{"author": "B", "quote": "2"` + "\n" +
+        `{"author": "C", "quote": "3"}` + "\n" +
+        `{"author": "D", "quote": "4"}` + "\n" +
+        `{"author": "E", "quote": "5"}` + "\n"
+
+    opts := &models.ImportOptions{Format: models.FormatNDJSON}
+    mockRepo.On("ImportQuotes", ctx, opts).Return(&models.ImportCounts{Inserted: 4}, nil).Once()
+
+    res, err := svc.Import(ctx, opts, strings.NewReader(body))
+    require.NoError(t, err)
+    require.Equal(t, 5, res.Total)
+    require.Equal(t, []models.ImportRowError{{Row: 3, Error: "malformed JSON"}}, res.Errors)
+    require.Equal(t, 2, mockRepo.batches)
+    require.Equal(t, 6, mockRepo.rows[3].Row)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestImport_MaxErrors(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Import.MaxErrors = 2
+    mockRepo := new(MockImportRepository)
+    svc := NewImportService(cfg, mockRepo)
+
+    opts := &models.ImportOptions{Format: models.FormatNDJSON}
+    mockRepo.On("ImportQuotes", ctx, opts).Return(&models.ImportCounts{}, nil).Once()
+
+    res, err := svc.Import(ctx, opts, strings.NewReader(strings.Repeat("{}\n", 5)))
+    require.NoError(t, err)
+    require.Equal(t, 5, res.Invalid)
+    require.Len(t, res.Errors, 2)
+}
+
+func TestImport_DuplicatesSkipped(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockImportRepository)
+    svc := NewImportService(cfg, mockRepo)
+
+    opts := &models.ImportOptions{Format: models.FormatNDJSON, OnDuplicate: models.DuplicateUpdate, DryRun: true}
+    mockRepo.On("ImportQuotes", ctx, opts).
+        Return(&models.ImportCounts{Inserted: 1, Updated: 1, Duplicates: 3, DuplicateRows: []int{2, 3, 4}}, nil).Once()
+
+    res, err := svc.Import(ctx, opts, strings.NewReader(`{"author": "A", "quote": "1"}`))
+    require.NoError(t, err)
+    require.True(t, res.DryRun)
+    require.Equal(t, 2, res.Skipped)
+    require.Empty(t, res.Errors)
+}
+
+func TestImport_DuplicatesFail(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockImportRepository)
+    svc := NewImportService(cfg, mockRepo)
+
+    opts := &models.ImportOptions{Format: models.FormatNDJSON, OnDuplicate: models.DuplicateFail}
+    mockRepo.On("ImportQuotes", ctx, opts).
+        Return(&models.ImportCounts{Duplicates: 1, DuplicateRows: []int{2}}, errdefs.ErrConflict).Once()
+
+    res, err := svc.Import(ctx, opts, strings.NewReader(`{"author": "A", "quote": "1"}`))
+    require.ErrorIs(t, err, errdefs.ErrConflict)
+    require.NotNil(t, res)
+    require.Equal(t, 0, res.Skipped)
+    require.Equal(t, []models.ImportRowError{{Row: 2, Error: "duplicate quote"}}, res.Errors)
+}
+
+func TestImport_InvalidOptions(t *testing.T) {
+    cfg := loadTestConfig(t)
+    svc := NewImportService(cfg, new(MockImportRepository))
+
+    for _, opts := range []*models.ImportOptions{
+        {Format: "xml"},
+        {Format: models.FormatCSV, OnDuplicate: "merge"},
+    } {
+        _, err := svc.Import(context.Background(), opts, strings.NewReader(""))
+        require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    }
+}
+
+func TestImportJob_MalformedID(t *testing.T) {
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockImportRepository)
+    svc := NewImportService(cfg, mockRepo)
+
+    _, err := svc.ImportJob(context.Background(), "not-a-uuid")
+    require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+    mockRepo.AssertNotCalled(t, "GetImportJob", mock.Anything, mock.Anything)
+}
@@ -2,8 +2,14 @@ package service
 
 import (
     "context"
+    "strconv"
+    "strings"
+    "time"
+    "unicode/utf8"
 
+    "quotebook/internal/audit"
     "quotebook/internal/interfaces"
+    "quotebook/internal/langdetect"
     _ "quotebook/internal/logger"
     "quotebook/internal/models"
     "quotebook/internal/errdefs"
@@ -12,37 +18,309 @@ import (
     _ "go.uber.org/zap"
 )
 
+// ограничение на GET /quotes?ids=
+const maxBatchIDs = 100
+
+const defaultFeedSize = 50
+
+const (
+    maxTags   = 20
+    maxTagLen = 50
+)
+
+// допустимый вес цитаты для стратегии weight
+const maxWeight = 1000
+
 type QuoteService struct {
     repo interfaces.IQuoteRepository
     cfg *config.Config
+    samplers *samplerCache
 }
 
 func NewQuoteService(cfg *config.Config, repo interfaces.IQuoteRepository) QuoteService {
     return QuoteService{
         repo: repo, 
         cfg: cfg,
+        samplers: newSamplerCache(),
     }
 }
 
-func (qs QuoteService) CreateQuote(ctx context.Context, q *models.Quote) (int, error) {
+func validateQuote(q *models.Quote) error {
     if q.Author== "" {
-        return 0, errdefs.Wrap(errdefs.ErrInvalidInput, "Author reqiured")
+        return errdefs.Wrap(errdefs.ErrInvalidInput, "Author reqiured")
+    }
+    tags, err := normalizeTags(q.Tags)
+    if err != nil {
+        return err
+    }
+    q.Tags = tags
+    if err := validateSource(q.Source); err != nil {
+        return err
+    }
+    if q.Lang != "" {
+        lang, err := normalizeLang(q.Lang)
+        if err != nil {
+            return err
+        }
+        q.Lang = lang
+    }
+    return validateWeight(q.Weight)
+}
+
+// normalizeFilter тег в нижний регистр, язык как в normalizeKnownLang
+func normalizeFilter(f *models.QuoteFilter) error {
+    f.Tag = strings.ToLower(strings.TrimSpace(f.Tag))
+    if f.Lang == "" {
+        return nil
+    }
+    lang, err := normalizeKnownLang(f.Lang)
+    if err != nil {
+        return err
+    }
+    f.Lang = lang
+    return nil
+}
+
+// validateWeight nil — вес не задан: в POST и PUT это 1, в PATCH — не меняется
+func validateWeight(w *float64) error {
+    if w != nil && !(*w >= 0 && *w <= maxWeight) {
+        return errdefs.Wrapf(errdefs.ErrInvalidInput, "weight must be between 0 and %d", maxWeight)
     }
-    return qs.repo.CreateQuote(ctx, q)
+    return nil
+}
+
+// normalizeTags приводит теги к нижнему регистру и убирает повторы;
+// nil остаётся nil, чтобы PATCH без tags не стирал их
+func normalizeTags(tags []string) ([]string, error) {
+    if tags == nil {
+        return nil, nil
+    }
+    if len(tags) > maxTags {
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "at most %d tags allowed", maxTags)
+    }
+    out := make([]string, 0, len(tags))
+    seen := make(map[string]bool, len(tags))
+    for _, tag := range tags {
+        tag = strings.ToLower(strings.TrimSpace(tag))
+        if tag == "" || utf8.RuneCountInString(tag) > maxTagLen {
+            return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "tag must be 1 to %d characters", maxTagLen)
+        }
+        if !seen[tag] {
+            seen[tag] = true
+            out = append(out, tag)
+        }
+    }
+    return out, nil
+}
+
+func (qs QuoteService) CreateQuote(ctx context.Context, q *models.Quote) (int, error) {
+    if err := validateQuote(q); err != nil {
+        return 0, err
+    }
+    if q.Lang == "" {
+        q.Lang = langdetect.Detect(q.Quote)
+    }
+    id, err := qs.repo.CreateQuote(ctx, q)
+    if err != nil {
+        return 0, err
+    }
+    // id появляется только здесь, middleware аудита его не знает
+    audit.SetTarget(ctx, strconv.Itoa(id))
+    return id, nil
 }
 
 func (qs QuoteService) QuotesAll(ctx context.Context) (*[]models.Quote, error) {
     return qs.repo.QuotesAll(ctx)
 }
 
-func (qs QuoteService) QuoteByAuthor(ctx context.Context, author string) (*[]models.Quote, error) {
-    return qs.repo.QuoteByAuthor(ctx, author)
+// SortedQuotes цитаты по популярности (popular), среднему рейтингу (rating) или новизне (recent)
+func (qs QuoteService) SortedQuotes(ctx context.Context, sort string) (*[]models.Quote, error) {
+    switch sort {
+    case models.SortPopular, models.SortRating, models.SortRecent:
+        return qs.repo.SortedQuotes(ctx, sort)
+    }
+    return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "sort must be popular, rating or recent, got %q", sort)
+}
+
+// ExportQuotes отдаёт цитаты по одной в порядке id, для выгрузки целиком
+func (qs QuoteService) ExportQuotes(ctx context.Context, f *models.QuoteFilter, fn func(q *models.Quote) error) error {
+    if err := normalizeFilter(f); err != nil {
+        return err
+    }
+    return qs.repo.StreamQuotes(ctx, f, fn)
+}
+
+// FilterQuotes цитаты по фильтру в порядке id; с Lang — на этом языке
+// или с переводом на него
+func (qs QuoteService) FilterQuotes(ctx context.Context, f *models.QuoteFilter) (*[]models.Quote, error) {
+    quotes := []models.Quote{}
+    err := qs.ExportQuotes(ctx, f, func(q *models.Quote) error {
+        quotes = append(quotes, *q)
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &quotes, nil
+}
+
+// RecentQuotes свежие цитаты для лент, не больше Feed.Size
+func (qs QuoteService) RecentQuotes(ctx context.Context, f *models.QuoteFilter) (*[]models.Quote, error) {
+    limit := qs.cfg.Feed.Size
+    if limit <= 0 {
+        limit = defaultFeedSize
+    }
+    if err := normalizeFilter(f); err != nil {
+        return nil, err
+    }
+    return qs.repo.RecentQuotes(ctx, f, limit)
+}
+
+func (qs QuoteService) QuotesStamp(ctx context.Context) (*models.QuotesStamp, error) {
+    return qs.repo.QuotesStamp(ctx)
 }
 
 func (qs QuoteService) RandQuote(ctx context.Context) (*models.Quote, error) { 
     return qs.repo.RandQuote(ctx)
 }
 
-func (qs QuoteService) DeleteQuote(ctx context.Context, id int) error {
-    return qs.repo.DeleteQuote(ctx, id)
+// RandQuoteByLang случайная цитата на языке lang или с переводом на него
+func (qs QuoteService) RandQuoteByLang(ctx context.Context, lang string) (*models.Quote, error) {
+    lang, err := normalizeKnownLang(lang)
+    if err != nil {
+        return nil, err
+    }
+    return qs.repo.RandQuoteByLang(ctx, lang)
+}
+
+func (qs QuoteService) GetQuote(ctx context.Context, id int) (*models.Quote, error) {
+    return qs.repo.GetQuote(ctx, id)
+}
+
+// QuotesByIDs отдаёт цитаты в порядке запроса, повторы id схлопываются
+func (qs QuoteService) QuotesByIDs(ctx context.Context, ids []int) (*models.QuoteBatch, error) {
+    if len(ids) == 0 {
+        return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "ids required")
+    }
+    if len(ids) > maxBatchIDs {
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "at most %d ids allowed", maxBatchIDs)
+    }
+
+    unique := make([]int, 0, len(ids))
+    seen := make(map[int]bool, len(ids))
+    for _, id := range ids {
+        if !seen[id] {
+            seen[id] = true
+            unique = append(unique, id)
+        }
+    }
+
+    found, err := qs.repo.QuotesByIDs(ctx, unique)
+    if err != nil {
+        return nil, err
+    }
+    byID := make(map[int]models.Quote, len(*found))
+    for _, q := range *found {
+        byID[q.ID] = q
+    }
+
+    batch := &models.QuoteBatch{Quotes: []models.Quote{}, Missing: []int{}}
+    for _, id := range unique {
+        if q, ok := byID[id]; ok {
+            batch.Quotes = append(batch.Quotes, q)
+        } else {
+            batch.Missing = append(batch.Missing, id)
+        }
+    }
+    return batch, nil
+}
+
+// UpdateQuote PUT заменяет цитату целиком, как POST: чего нет в q, сбрасывается
+// к значению по умолчанию, язык определяется заново. Частичное изменение — PatchQuote.
+func (qs QuoteService) UpdateQuote(ctx context.Context, q *models.Quote) error {
+    if err := validateQuote(q); err != nil {
+        return err
+    }
+    if q.Lang == "" {
+        q.Lang = langdetect.Detect(q.Quote)
+    }
+    return qs.repo.UpdateQuote(ctx, q)
+}
+
+func (qs QuoteService) PatchQuote(ctx context.Context, id, version int, p *models.QuotePatch) (*models.Quote, error) {
+    if p.Author == nil && p.Quote == nil && p.Tags == nil && p.Weight == nil && !p.Source.Set && p.Lang == nil {
+        return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "nothing to update")
+    }
+    if p.Author != nil && *p.Author == "" {
+        return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "Author reqiured")
+    }
+    if err := validateWeight(p.Weight); err != nil {
+        return nil, err
+    }
+    if err := validateSource(p.Source.Value); err != nil {
+        return nil, err
+    }
+    if p.Lang != nil {
+        lang, err := normalizeLang(*p.Lang)
+        if err != nil {
+            return nil, err
+        }
+        p.Lang = &lang
+    }
+    if p.Tags != nil {
+        // "tags": [] снимает все теги
+        tags, err := normalizeTags(*p.Tags)
+        if err != nil {
+            return nil, err
+        }
+        p.Tags = &tags
+    }
+    return qs.repo.PatchQuote(ctx, id, version, p)
+}
+
+// DeleteQuote version 0 означает удаление без проверки версии
+func (qs QuoteService) DeleteQuote(ctx context.Context, id, version int) error {
+    return qs.repo.DeleteQuote(ctx, id, version)
+}
+
+func (qs QuoteService) TrashQuotes(ctx context.Context) (*[]models.Quote, error) {
+    return qs.repo.TrashQuotes(ctx)
+}
+
+func (qs QuoteService) RestoreQuote(ctx context.Context, id int) error {
+    return qs.repo.RestoreQuote(ctx, id)
+}
+
+// PurgeTrash удаляет из корзины всё, что лежит там дольше Trash.Retention
+func (qs QuoteService) PurgeTrash(ctx context.Context) (int64, error) {
+    retention := time.Duration(qs.cfg.Trash.Retention)
+    if retention <= 0 {
+        return 0, errdefs.Wrap(errdefs.ErrInvalidInput, "trash retention must be positive")
+    }
+    return qs.repo.PurgeQuotes(ctx, time.Now().Add(-retention))
+}
+
+func (qs QuoteService) QuoteRevisions(ctx context.Context, id int) (*[]models.QuoteRevision, error) {
+    return qs.repo.QuoteRevisions(ctx, id)
+}
+
+func (qs QuoteService) QuoteRevision(ctx context.Context, id, rev int) (*models.QuoteRevision, error) {
+    return qs.repo.QuoteRevision(ctx, id, rev)
+}
+
+// DiffQuoteRevisions сравнивает ревизии from и to одной цитаты
+func (qs QuoteService) DiffQuoteRevisions(ctx context.Context, id, from, to int) (string, error) {
+    fromRev, err := qs.repo.QuoteRevision(ctx, id, from)
+    if err != nil {
+        return "", err
+    }
+    toRev, err := qs.repo.QuoteRevision(ctx, id, to)
+    if err != nil {
+        return "", err
+    }
+    return DiffRevisions(fromRev, toRev), nil
+}
+
+func (qs QuoteService) RevertQuote(ctx context.Context, id, rev int) error {
+    return qs.repo.RevertQuote(ctx, id, rev)
 }
\ No newline at end of file
@@ -3,6 +3,8 @@ package service
 
 import (
     "context"
+    "encoding/json"
+    "errors"
     "log"
     "testing"
     "time"
@@ -29,18 +31,138 @@ func (m *MockQuoteRepository) QuotesAll(ctx context.Context) (*[]models.Quote, e
     return args.Get(0).(*[]models.Quote), args.Error(1)
 }
 
+func (m *MockQuoteRepository) SortedQuotes(ctx context.Context, sort string) (*[]models.Quote, error) {
+    args := m.Called(ctx, sort)
+    return args.Get(0).(*[]models.Quote), args.Error(1)
+}
+
+func (m *MockQuoteRepository) StreamQuotes(ctx context.Context, f *models.QuoteFilter, fn func(q *models.Quote) error) error {
+    args := m.Called(ctx, f)
+    if quotes, ok := args.Get(0).([]models.Quote); ok {
+        for i := range quotes {
+            if err := fn(&quotes[i]); err != nil {
+                return err
+            }
+        }
+    }
+    return args.Error(1)
+}
+
+func (m *MockQuoteRepository) RecentQuotes(ctx context.Context, f *models.QuoteFilter, limit int) (*[]models.Quote, error) {
+    args := m.Called(ctx, f, limit)
+    return args.Get(0).(*[]models.Quote), args.Error(1)
+}
+
 func (m *MockQuoteRepository) QuoteByAuthor(ctx context.Context, author string) (*[]models.Quote, error) {
     args := m.Called(ctx, author)
     return args.Get(0).(*[]models.Quote), args.Error(1)
 }
 
+func (m *MockQuoteRepository) QuotesStamp(ctx context.Context) (*models.QuotesStamp, error) {
+    args := m.Called(ctx)
+    return args.Get(0).(*models.QuotesStamp), args.Error(1)
+}
+
 func (m *MockQuoteRepository) RandQuote(ctx context.Context) (*models.Quote, error) {
     args := m.Called(ctx)
     return args.Get(0).(*models.Quote), args.Error(1)
 }
 
-func (m *MockQuoteRepository) DeleteQuote(ctx context.Context, id int) error {
+func (m *MockQuoteRepository) FuzzyQuotesByAuthor(ctx context.Context, author string, threshold float64) (*[]models.Quote, error) {
+    args := m.Called(ctx, author, threshold)
+    return args.Get(0).(*[]models.Quote), args.Error(1)
+}
+
+func (m *MockQuoteRepository) SimilarAuthors(ctx context.Context, name string, threshold float64, limit int) (*[]models.AuthorSuggestion, error) {
+    args := m.Called(ctx, name, threshold, limit)
+    return args.Get(0).(*[]models.AuthorSuggestion), args.Error(1)
+}
+
+func (m *MockQuoteRepository) SuggestAuthors(ctx context.Context, prefix string, limit int) (*[]models.AuthorSuggestion, error) {
+    args := m.Called(ctx, prefix, limit)
+    return args.Get(0).(*[]models.AuthorSuggestion), args.Error(1)
+}
+
+func (m *MockQuoteRepository) RandQuoteByLang(ctx context.Context, lang string) (*models.Quote, error) {
+    args := m.Called(ctx, lang)
+    if q, ok := args.Get(0).(*models.Quote); ok {
+        return q, args.Error(1)
+    }
+    return nil, args.Error(1)
+}
+
+func (m *MockQuoteRepository) QuoteWeights(ctx context.Context) (*[]models.QuoteWeight, error) {
+    args := m.Called(ctx)
+    return args.Get(0).(*[]models.QuoteWeight), args.Error(1)
+}
+
+func (m *MockQuoteRepository) GetQuote(ctx context.Context, id int) (*models.Quote, error) {
+    args := m.Called(ctx, id)
+    return args.Get(0).(*models.Quote), args.Error(1)
+}
+
+func (m *MockQuoteRepository) QuotesByIDs(ctx context.Context, ids []int) (*[]models.Quote, error) {
+    args := m.Called(ctx, ids)
+    return args.Get(0).(*[]models.Quote), args.Error(1)
+}
+
+func (m *MockQuoteRepository) DeleteQuote(ctx context.Context, id, version int) error {
+    args := m.Called(ctx, id, version)
+    return args.Error(0)
+}
+
+func (m *MockQuoteRepository) TrashQuotes(ctx context.Context) (*[]models.Quote, error) {
+    args := m.Called(ctx)
+    return args.Get(0).(*[]models.Quote), args.Error(1)
+}
+
+func (m *MockQuoteRepository) RestoreQuote(ctx context.Context, id int) error {
+    args := m.Called(ctx, id)
+    return args.Error(0)
+}
+
+func (m *MockQuoteRepository) PurgeQuotes(ctx context.Context, before time.Time) (int64, error) {
+    args := m.Called(ctx, before)
+    return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuoteRepository) QuotesChangedSince(ctx context.Context, since time.Time) (*[]models.Quote, error) {
+    args := m.Called(ctx, since)
+    return args.Get(0).(*[]models.Quote), args.Error(1)
+}
+
+func (m *MockQuoteRepository) GetAuthor(ctx context.Context, name string) (*models.Author, error) {
+    args := m.Called(ctx, name)
+    return args.Get(0).(*models.Author), args.Error(1)
+}
+
+func (m *MockQuoteRepository) PutAuthor(ctx context.Context, a *models.Author) (*models.Author, error) {
+    args := m.Called(ctx, a)
+    return args.Get(0).(*models.Author), args.Error(1)
+}
+
+func (m *MockQuoteRepository) UpdateQuote(ctx context.Context, q *models.Quote) error {
+    args := m.Called(ctx, q)
+    return args.Error(0)
+}
+
+func (m *MockQuoteRepository) PatchQuote(ctx context.Context, id, version int, p *models.QuotePatch) (*models.Quote, error) {
+    args := m.Called(ctx, id, version, p)
+    return args.Get(0).(*models.Quote), args.Error(1)
+}
+
+func (m *MockQuoteRepository) QuoteRevisions(ctx context.Context, id int) (*[]models.QuoteRevision, error) {
     args := m.Called(ctx, id)
+    return args.Get(0).(*[]models.QuoteRevision), args.Error(1)
+}
+
+func (m *MockQuoteRepository) QuoteRevision(ctx context.Context, id, rev int) (*models.QuoteRevision, error) {
+    args := m.Called(ctx, id, rev)
+    return args.Get(0).(*models.QuoteRevision), args.Error(1)
+}
+
+func (m *MockQuoteRepository) RevertQuote(ctx context.Context, id, rev int) error {
+    args := m.Called(ctx, id, rev)
     return args.Error(0)
 }
 
@@ -155,6 +277,7 @@ func TestQuoteByAuthor_EmptyResult(t *testing.T) {
 
     // та же самая ситуация
     mockRepo.On("QuoteByAuthor", ctx, "NoAuth").Return((*[]models.Quote)(nil), nil).Once()
+    mockRepo.On("FuzzyQuotesByAuthor", ctx, "NoAuth", cfg.Authors.Similarity).Return(&[]models.Quote{}, nil).Once()
 
     got, err := svc.QuoteByAuthor(ctx, "NoAuth")
     require.NoError(t, err)
@@ -200,9 +323,9 @@ func TestDeleteQuote_Success(t *testing.T) {
     mockRepo := new(MockQuoteRepository)
     svc := NewQuoteService(cfg, mockRepo)
 
-    mockRepo.On("DeleteQuote", ctx, 99).Return(nil).Once()
+    mockRepo.On("DeleteQuote", ctx, 99, 0).Return(nil).Once()
 
-    err := svc.DeleteQuote(ctx, 99)
+    err := svc.DeleteQuote(ctx, 99, 0)
     require.NoError(t, err)
 
     mockRepo.AssertExpectations(t)
@@ -214,10 +337,517 @@ func TestDeleteQuote_NotFound(t *testing.T) {
     mockRepo := new(MockQuoteRepository)
     svc := NewQuoteService(cfg, mockRepo)
 
-    mockRepo.On("DeleteQuote", ctx, 100).Return(errdefs.ErrNotFound).Once()
+    mockRepo.On("DeleteQuote", ctx, 100, 0).Return(errdefs.ErrNotFound).Once()
+
+    err := svc.DeleteQuote(ctx, 100, 0)
+    require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestRestoreQuote_NotFound(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    mockRepo.On("RestoreQuote", ctx, 7).Return(errdefs.ErrNotFound).Once()
 
-    err := svc.DeleteQuote(ctx, 100)
+    err := svc.RestoreQuote(ctx, 7)
     require.ErrorIs(t, err, errdefs.ErrNotFound)
 
     mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func TestPurgeTrash_UsesRetention(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Trash.Retention = config.Duration(24 * time.Hour)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    // граница должна быть примерно сутки назад
+    mockRepo.On("PurgeQuotes", ctx, mock.MatchedBy(func(before time.Time) bool {
+        age := time.Since(before)
+        return age >= 24*time.Hour && age < 24*time.Hour+time.Minute
+    })).Return(int64(3), nil).Once()
+
+    purged, err := svc.PurgeTrash(ctx)
+    require.NoError(t, err)
+    require.Equal(t, int64(3), purged)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestPurgeTrash_InvalidRetention(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Trash.Retention = 0
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    _, err := svc.PurgeTrash(ctx)
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.AssertNotCalled(t, "PurgeQuotes", mock.Anything, mock.Anything)
+}
+
+func TestUpdateQuote_InvalidInput(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    err := svc.UpdateQuote(ctx, &models.Quote{ID: 1, Quote: "No author"})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.AssertNotCalled(t, "UpdateQuote", mock.Anything, mock.Anything)
+}
+
+func TestUpdateQuote_FullReplacement(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    // PUT без lang определяет язык заново, как POST; остальное сбрасывает репозиторий
+    mockRepo.On("UpdateQuote", ctx, mock.MatchedBy(func(q *models.Quote) bool {
+        return q.Lang == "ru" && q.Tags == nil && q.Weight == nil && q.Source == nil
+    })).Return(nil).Once()
+
+    err := svc.UpdateQuote(ctx, &models.Quote{ID: 1, Author: "Пушкин", Quote: "Я помню чудное мгновенье: передо мной явилась ты"})
+    require.NoError(t, err)
+    mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteQuote_VersionMismatch(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    mockRepo.On("DeleteQuote", ctx, 5, 2).Return(errdefs.ErrPreconditionFailed).Once()
+
+    err := svc.DeleteQuote(ctx, 5, 2)
+    require.ErrorIs(t, err, errdefs.ErrPreconditionFailed)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestPatchQuote_Success(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    text := "Patched"
+    patch := &models.QuotePatch{Quote: &text}
+    expected := &models.Quote{ID: 5, Author: "A", Quote: text, Version: 3}
+    mockRepo.On("PatchQuote", ctx, 5, 2, patch).Return(expected, nil).Once()
+
+    got, err := svc.PatchQuote(ctx, 5, 2, patch)
+    require.NoError(t, err)
+    require.Equal(t, expected, got)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestPatchQuote_ClearSource(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    // "source": null — это изменение, а не пустой PATCH
+    var patch models.QuotePatch
+    require.NoError(t, json.Unmarshal([]byte(`{"source":null}`), &patch))
+    require.True(t, patch.Source.Set)
+    require.Nil(t, patch.Source.Value)
+
+    expected := &models.Quote{ID: 5, Author: "A", Quote: "q", Version: 3}
+    mockRepo.On("PatchQuote", ctx, 5, 0, &patch).Return(expected, nil).Once()
+
+    got, err := svc.PatchQuote(ctx, 5, 0, &patch)
+    require.NoError(t, err)
+    require.Equal(t, expected, got)
+
+    // без source поле не задано
+    patch = models.QuotePatch{}
+    require.NoError(t, json.Unmarshal([]byte(`{"quote":"q"}`), &patch))
+    require.False(t, patch.Source.Set)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestPatchQuote_InvalidInput(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    empty := ""
+    _, err := svc.PatchQuote(ctx, 5, 0, &models.QuotePatch{Author: &empty})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    _, err = svc.PatchQuote(ctx, 5, 0, &models.QuotePatch{})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.AssertNotCalled(t, "PatchQuote", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDiffQuoteRevisions(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    from := &models.QuoteRevision{QuoteID: 5, Rev: 1, Action: models.ActionCreate, Actor: "alice",
+        Author: "Confucius", Quote: "Life is simple,\nbut we make it hard."}
+    to := &models.QuoteRevision{QuoteID: 5, Rev: 2, Action: models.ActionUpdate, Actor: "bob",
+        Author: "Confucius", Quote: "Life is simple,\nbut we insist on making it complicated."}
+    mockRepo.On("QuoteRevision", ctx, 5, 1).Return(from, nil).Once()
+    mockRepo.On("QuoteRevision", ctx, 5, 2).Return(to, nil).Once()
+
+    diff, err := svc.DiffQuoteRevisions(ctx, 5, 1, 2)
+    require.NoError(t, err)
+    require.Equal(t, "--- quote 5 rev 1 (create by alice)\n"+
+        "+++ quote 5 rev 2 (update by bob)\n"+
+        " author: Confucius\n"+
+        " Life is simple,\n"+
+        "-but we make it hard.\n"+
+        "+but we insist on making it complicated.\n", diff)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestDiffQuoteRevisions_SourceWeightLang(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    one, two := 1.0, 2.5
+    from := &models.QuoteRevision{QuoteID: 5, Rev: 1, Action: models.ActionCreate, Actor: "alice",
+        Author: "Confucius", Quote: "Know thyself.", Weight: &one, Lang: "und"}
+    to := &models.QuoteRevision{QuoteID: 5, Rev: 2, Action: models.ActionUpdate, Actor: "bob",
+        Author: "Confucius", Quote: "Know thyself.", Weight: &two, Lang: "en",
+        Source: &models.Source{Type: models.SourceBook, Title: "Analects", Year: 1861, Page: "12"}}
+    mockRepo.On("QuoteRevision", ctx, 5, 1).Return(from, nil).Once()
+    mockRepo.On("QuoteRevision", ctx, 5, 2).Return(to, nil).Once()
+
+    // изменились только источник, вес и язык — diff их показывает
+    diff, err := svc.DiffQuoteRevisions(ctx, 5, 1, 2)
+    require.NoError(t, err)
+    require.Equal(t, "--- quote 5 rev 1 (create by alice)\n"+
+        "+++ quote 5 rev 2 (update by bob)\n"+
+        " author: Confucius\n"+
+        "+source: book, Analects, 1861, 12\n"+
+        "+weight: 2.5\n"+
+        "+lang: en\n"+
+        " Know thyself.\n", diff)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestDiffQuoteRevisions_NotFound(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    mockRepo.On("QuoteRevision", ctx, 5, 1).Return((*models.QuoteRevision)(nil), errdefs.ErrNotFound).Once()
+
+    _, err := svc.DiffQuoteRevisions(ctx, 5, 1, 2)
+    require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestGetQuote_NotFound(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    mockRepo.On("GetQuote", ctx, 404).Return((*models.Quote)(nil), errdefs.ErrNotFound).Once()
+
+    got, err := svc.GetQuote(ctx, 404)
+    require.ErrorIs(t, err, errdefs.ErrNotFound)
+    require.Nil(t, got)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestQuotesByIDs_PreservesOrder(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    // репозиторий отдаёт в своём порядке
+    found := &[]models.Quote{
+        {ID: 1, Author: "A1", Quote: "T1"},
+        {ID: 3, Author: "A3", Quote: "T3"},
+    }
+    mockRepo.On("QuotesByIDs", ctx, []int{3, 2, 1}).Return(found, nil).Once()
+
+    got, err := svc.QuotesByIDs(ctx, []int{3, 2, 1, 3})
+    require.NoError(t, err)
+    require.Len(t, got.Quotes, 2)
+    require.Equal(t, 3, got.Quotes[0].ID)
+    require.Equal(t, 1, got.Quotes[1].ID)
+    require.Equal(t, []int{2}, got.Missing)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestQuotesByIDs_InvalidInput(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    _, err := svc.QuotesByIDs(ctx, nil)
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    _, err = svc.QuotesByIDs(ctx, make([]int, maxBatchIDs+1))
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.AssertNotCalled(t, "QuotesByIDs", mock.Anything, mock.Anything)
+}
+
+func TestExportQuotes_StopsOnError(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    filter := &models.QuoteFilter{Author: "A"}
+    mockRepo.On("StreamQuotes", ctx, filter).
+        Return([]models.Quote{{ID: 1}, {ID: 2}, {ID: 3}}, nil).Once()
+
+    var ids []int
+    stop := errors.New("client gone")
+    err := svc.ExportQuotes(ctx, filter, func(q *models.Quote) error {
+        ids = append(ids, q.ID)
+        if q.ID == 2 {
+            return stop
+        }
+        return nil
+    })
+    require.ErrorIs(t, err, stop)
+    require.Equal(t, []int{1, 2}, ids)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestCreateQuote_NormalizesTags(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    q := &models.Quote{Author: "A", Quote: "Q", Tags: []string{" Life ", "wisdom", "LIFE"}}
+    mockRepo.On("CreateQuote", ctx, q).Return(1, nil).Once()
+
+    _, err := svc.CreateQuote(ctx, q)
+    require.NoError(t, err)
+    require.Equal(t, []string{"life", "wisdom"}, q.Tags)
+
+    _, err = svc.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "Q", Tags: []string{"  "}})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    _, err = svc.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "Q", Tags: make([]string, maxTags+1)})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestPatchQuote_TagsOnly(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    tags := []string{"Stoic"}
+    patch := &models.QuotePatch{Tags: &tags}
+    mockRepo.On("PatchQuote", ctx, 5, 0, patch).Return(&models.Quote{ID: 5}, nil).Once()
+
+    _, err := svc.PatchQuote(ctx, 5, 0, patch)
+    require.NoError(t, err)
+    require.Equal(t, []string{"stoic"}, *patch.Tags)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestRecentQuotes_UsesFeedSize(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Feed.Size = 7
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    filter := &models.QuoteFilter{Tag: " Life "}
+    mockRepo.On("RecentQuotes", ctx, filter, 7).Return(&[]models.Quote{}, nil).Once()
+
+    _, err := svc.RecentQuotes(ctx, filter)
+    require.NoError(t, err)
+    require.Equal(t, "life", filter.Tag)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestCreateQuote_DetectsLanguage(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    mockRepo.On("CreateQuote", ctx, mock.MatchedBy(func(q *models.Quote) bool {
+        return q.Lang == "ru"
+    })).Return(1, nil).Once()
+    _, err := svc.CreateQuote(ctx, &models.Quote{Author: "Пушкин", Quote: "Я помню чудное мгновенье"})
+    require.NoError(t, err)
+
+    // заданный язык не перепроверяется, только нормализуется
+    mockRepo.On("CreateQuote", ctx, mock.MatchedBy(func(q *models.Quote) bool {
+        return q.Lang == "la"
+    })).Return(2, nil).Once()
+    _, err = svc.CreateQuote(ctx, &models.Quote{Author: "Descartes", Quote: "Cogito, ergo sum", Lang: "LA"})
+    require.NoError(t, err)
+
+    _, err = svc.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "B", Lang: "latin"})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestRandQuoteByLang_InvalidLang(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    _, err := svc.RandQuoteByLang(ctx, "und")
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.On("RandQuoteByLang", ctx, "en").Return(&models.Quote{ID: 1, Lang: "en"}, nil).Once()
+    q, err := svc.RandQuoteByLang(ctx, "en-GB")
+    require.NoError(t, err)
+    require.Equal(t, 1, q.ID)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestQuoteByAuthor_FuzzyFallback(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    fuzzy := &[]models.Quote{{ID: 3, Author: "Confucius"}}
+    mockRepo.On("QuoteByAuthor", ctx, "Konfucius").Return(&[]models.Quote{}, nil).Once()
+    mockRepo.On("FuzzyQuotesByAuthor", ctx, "Konfucius", 0.5).Return(fuzzy, nil).Once()
+
+    got, err := svc.QuoteByAuthor(ctx, " Konfucius ")
+    require.NoError(t, err)
+    require.Equal(t, fuzzy, got)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestFilterQuotesByAuthor_FuzzyThenFilter(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    // опечатка прощается и с ?lang=: фильтр применяется к найденному имени
+    mockRepo.On("QuoteByAuthor", ctx, "Konfucius").Return(&[]models.Quote{}, nil).Once()
+    mockRepo.On("FuzzyQuotesByAuthor", ctx, "Konfucius", 0.5).
+        Return(&[]models.Quote{{ID: 3, Author: "Confucius"}, {ID: 4, Author: "confucius"}}, nil).Once()
+    mockRepo.On("StreamQuotes", ctx, &models.QuoteFilter{Author: "Confucius", Tag: "wisdom", Lang: "en"}).
+        Return([]models.Quote{{ID: 4, Author: "confucius"}}, nil).Once()
+
+    got, err := svc.FilterQuotesByAuthor(ctx, &models.QuoteFilter{Author: "Konfucius", Tag: "Wisdom", Lang: "en"})
+    require.NoError(t, err)
+    require.Equal(t, &[]models.Quote{{ID: 4, Author: "confucius"}}, got)
+
+    // без фильтров второй запрос не нужен
+    mockRepo.On("QuoteByAuthor", ctx, "Confucius").Return(&[]models.Quote{{ID: 3, Author: "Confucius"}}, nil).Once()
+    got, err = svc.FilterQuotesByAuthor(ctx, &models.QuoteFilter{Author: "Confucius"})
+    require.NoError(t, err)
+    require.Len(t, *got, 1)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestDidYouMean(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    mockRepo.On("SimilarAuthors", ctx, "Aynstein", 0.3, 10).Return(&[]models.AuthorSuggestion{
+        {Author: "Albert Einstein", Quotes: 4, Similarity: 0.35},
+    }, nil).Once()
+
+    names, err := svc.DidYouMean(ctx, "Aynstein")
+    require.NoError(t, err)
+    require.Equal(t, []string{"Albert Einstein"}, names)
+
+    names, err = svc.DidYouMean(ctx, "  ")
+    require.NoError(t, err)
+    require.Empty(t, names)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestSuggestAuthors_Validation(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    _, err := svc.SuggestAuthors(ctx, " ", 0)
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    _, err = svc.SuggestAuthors(ctx, "ein", 51)
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    expected := &[]models.AuthorSuggestion{{Author: "Albert Einstein", Quotes: 4}}
+    mockRepo.On("SuggestAuthors", ctx, "ein", 10).Return(expected, nil).Once()
+
+    got, err := svc.SuggestAuthors(ctx, "ein", 0)
+    require.NoError(t, err)
+    require.Equal(t, expected, got)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestSetAuthorDates_Validation(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    for _, dates := range []models.Author{
+        {Born: "09.09.1828"},
+        {Born: "1828-09-09", Died: "1828-09-08"},
+        {Died: time.Now().AddDate(1, 0, 0).Format(time.DateOnly)},
+    } {
+        _, err := svc.SetAuthorDates(ctx, "Tolstoy", &dates)
+        require.ErrorIs(t, err, errdefs.ErrInvalidInput, dates)
+    }
+    _, err := svc.SetAuthorDates(ctx, "  ", &models.Author{})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    mockRepo.AssertNotCalled(t, "PutAuthor", mock.Anything, mock.Anything)
+
+    saved := &models.Author{Name: "Leo Tolstoy", Born: "1828-09-09", Died: "1910-11-20", Quotes: 2}
+    mockRepo.On("PutAuthor", ctx, &models.Author{Name: "Leo Tolstoy", Born: "1828-09-09", Died: "1910-11-20"}).
+        Return(saved, nil).Once()
+    got, err := svc.SetAuthorDates(ctx, " Leo Tolstoy ", &models.Author{Name: "ignored", Born: "1828-09-09", Died: " 1910-11-20"})
+    require.NoError(t, err)
+    require.Same(t, saved, got)
+
+    mockRepo.AssertExpectations(t)
+}
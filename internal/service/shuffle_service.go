@@ -0,0 +1,136 @@
+package service
+
+import (
+    "context"
+    "math/rand/v2"
+    "time"
+
+    "quotebook/config"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/interfaces"
+    "quotebook/internal/models"
+    "quotebook/internal/shuffle"
+
+    "github.com/google/uuid"
+)
+
+const (
+    // сколько позиций перестановки проверяется одним запросом: цитаты,
+    // удалённые после начала круга, пропускаются
+    shuffleBatch = 64
+    // столько раз обход пытается сдвинуться, если его продвигают параллельно
+    shuffleRetries = 3
+)
+
+type ShuffleService struct {
+    repo interfaces.IShuffleRepository
+    cfg *config.Config
+}
+
+func NewShuffleService(cfg *config.Config, repo interfaces.IShuffleRepository) ShuffleService {
+    return ShuffleService{
+        repo: repo,
+        cfg: cfg,
+    }
+}
+
+// NextQuote следующая цитата обхода token и token, который нужно прислать
+// в следующий раз. Пустой или забытый token начинает новый обход.
+func (ss ShuffleService) NextQuote(ctx context.Context, token string) (*models.Quote, string, error) {
+    var sess *models.ShuffleSession
+    if token != "" {
+        if _, err := uuid.Parse(token); err != nil {
+            return nil, "", errdefs.Wrap(errdefs.ErrInvalidInput, "shuffle token must be a UUID")
+        }
+        s, err := ss.repo.GetShuffle(ctx, token)
+        if err != nil && !errdefs.Is(err, errdefs.ErrNotFound) {
+            return nil, "", err
+        }
+        sess = s
+    }
+
+    if sess == nil {
+        q, next, err := ss.advance(ctx, &models.ShuffleSession{Token: uuid.NewString()})
+        if err != nil {
+            return nil, "", err
+        }
+        if err := ss.repo.CreateShuffle(ctx, next); err != nil {
+            return nil, "", err
+        }
+        return q, next.Token, nil
+    }
+
+    for attempt := 0; attempt < shuffleRetries; attempt++ {
+        q, next, err := ss.advance(ctx, sess)
+        if err != nil {
+            return nil, "", err
+        }
+        err = ss.repo.UpdateShuffle(ctx, next, sess.Position, sess.Round)
+        if err == nil {
+            return q, next.Token, nil
+        }
+        if !errdefs.Is(err, errdefs.ErrPreconditionFailed) {
+            return nil, "", err
+        }
+        // тот же клиент прислал запрос параллельно, продолжаем с его позиции
+        if sess, err = ss.repo.GetShuffle(ctx, sess.Token); err != nil {
+            return nil, "", err
+        }
+    }
+    return nil, "", errdefs.Wrap(errdefs.ErrConflict, "shuffle session is busy, retry")
+}
+
+// advance ищет от позиции sess первую живую цитату; sess не меняется
+func (ss ShuffleService) advance(ctx context.Context, sess *models.ShuffleSession) (*models.Quote, *models.ShuffleSession, error) {
+    next := *sess
+    restarted := false
+    for {
+        // снимка нет у обходов, начатых по id, а не по рангам
+        if next.Position >= next.Size || next.SnapshotID == 0 {
+            // весь круг без единой живой цитаты
+            if restarted {
+                return nil, nil, errdefs.ErrNotFound
+            }
+            round, err := ss.repo.ShuffleRound(ctx)
+            if err != nil {
+                return nil, nil, err
+            }
+            if round.Size == 0 {
+                return nil, nil, errdefs.ErrNotFound
+            }
+            next.Seed = rand.Int64()
+            next.Size, next.SnapshotID = round.Size, round.SnapshotID
+            next.Position = 0
+            next.Round++
+            restarted = true
+        }
+
+        perm := shuffle.New(uint64(next.Size), uint64(next.Seed))
+        end := min(next.Position+shuffleBatch, next.Size)
+        ranks := make([]int, 0, end-next.Position)
+        for i := next.Position; i < end; i++ {
+            ranks = append(ranks, int(perm.At(uint64(i))))
+        }
+
+        found, err := ss.repo.ShuffleQuotes(ctx, &next, ranks)
+        if err != nil {
+            return nil, nil, err
+        }
+        for k, rank := range ranks {
+            if q, ok := found[rank]; ok {
+                next.Position += k + 1
+                return &q, &next, nil
+            }
+        }
+        next.Position = end
+    }
+}
+
+// PurgeExpired удаляет обходы старше shuffle.ttl
+func (ss ShuffleService) PurgeExpired(ctx context.Context) (int64, error) {
+    ttl := time.Duration(ss.cfg.Shuffle.TTL)
+    if ttl <= 0 {
+        return 0, errdefs.Wrap(errdefs.ErrInvalidInput, "shuffle ttl must be positive")
+    }
+    return ss.repo.PurgeShuffles(ctx, time.Now().Add(-ttl))
+}
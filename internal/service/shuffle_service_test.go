@@ -0,0 +1,286 @@
+package service
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+// memShuffleRepository хранит обходы в памяти; conflicts раз подряд
+// UpdateShuffle ведёт себя так, будто обход продвинул параллельный запрос.
+// ids — id цитат по возрастанию, alive — какие из них ещё не удалены,
+// snapshots — id цитат каждого снимка по рангу.
+type memShuffleRepository struct {
+    sessions  map[string]models.ShuffleSession
+    ids       []int
+    alive     map[int]bool
+    snapshots map[int64][]int
+    conflicts int
+    // сколько раз запрашивались цитаты по рангам
+    lookups int
+}
+
+func (m *memShuffleRepository) CreateShuffle(ctx context.Context, s *models.ShuffleSession) error {
+    m.sessions[s.Token] = *s
+    return nil
+}
+
+func (m *memShuffleRepository) GetShuffle(ctx context.Context, token string) (*models.ShuffleSession, error) {
+    s, ok := m.sessions[token]
+    if !ok {
+        return nil, errdefs.ErrNotFound
+    }
+    return &s, nil
+}
+
+func (m *memShuffleRepository) UpdateShuffle(ctx context.Context, s *models.ShuffleSession, fromPosition, fromRound int) error {
+    cur := m.sessions[s.Token]
+    if m.conflicts > 0 {
+        m.conflicts--
+        cur.Position++
+        m.sessions[s.Token] = cur
+    }
+    if cur.Position != fromPosition || cur.Round != fromRound {
+        return errdefs.ErrPreconditionFailed
+    }
+    m.sessions[s.Token] = *s
+    return nil
+}
+
+// ShuffleRound снимает живые на начало круга; что бы ни случилось с
+// цитатами потом, снимок не меняется
+func (m *memShuffleRepository) ShuffleRound(ctx context.Context) (*models.ShuffleSession, error) {
+    var live []int
+    for _, id := range m.ids {
+        if m.alive[id] {
+            live = append(live, id)
+        }
+    }
+    if len(live) == 0 {
+        return &models.ShuffleSession{}, nil
+    }
+    id := int64(len(m.snapshots) + 1)
+    m.snapshots[id] = live
+    return &models.ShuffleSession{Size: len(live), SnapshotID: id}, nil
+}
+
+func (m *memShuffleRepository) ShuffleQuotes(ctx context.Context, s *models.ShuffleSession, ranks []int) (map[int]models.Quote, error) {
+    m.lookups++
+    quotes := make(map[int]models.Quote)
+    for _, rank := range ranks {
+        if id := m.snapshots[s.SnapshotID][rank]; m.alive[id] {
+            quotes[rank] = models.Quote{ID: id}
+        }
+    }
+    return quotes, nil
+}
+
+func (m *memShuffleRepository) PurgeShuffles(ctx context.Context, before time.Time) (int64, error) {
+    return 0, nil
+}
+
+// newShuffleFixture цитаты с id 1..maxID, кроме deleted
+func newShuffleFixture(maxID int, deleted ...int) *memShuffleRepository {
+    ids := make([]int, 0, maxID)
+    for id := 1; id <= maxID; id++ {
+        ids = append(ids, id)
+    }
+    return newSparseShuffleFixture(ids, deleted...)
+}
+
+func newSparseShuffleFixture(ids []int, deleted ...int) *memShuffleRepository {
+    alive := make(map[int]bool)
+    for _, id := range ids {
+        alive[id] = true
+    }
+    for _, id := range deleted {
+        delete(alive, id)
+    }
+    return &memShuffleRepository{sessions: make(map[string]models.ShuffleSession), ids: ids, alive: alive,
+        snapshots: make(map[int64][]int)}
+}
+
+func TestNextQuote_NoRepeatsUntilExhausted(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    repo := newShuffleFixture(100, 4, 7, 50)
+    svc := NewShuffleService(cfg, repo)
+
+    q, token, err := svc.NextQuote(ctx, "")
+    require.NoError(t, err)
+    seen := map[int]bool{q.ID: true}
+    for i := 1; i < 97; i++ {
+        q, token, err = svc.NextQuote(ctx, token)
+        require.NoError(t, err)
+        require.False(t, seen[q.ID], "quote %d repeated", q.ID)
+        require.True(t, repo.alive[q.ID])
+        seen[q.ID] = true
+    }
+    require.Len(t, seen, 97)
+
+    // все показаны, начинается второй круг с новым порядком
+    _, token, err = svc.NextQuote(ctx, token)
+    require.NoError(t, err)
+    require.Equal(t, 2, repo.sessions[token].Round)
+}
+
+func TestNextQuote_RetriesWhenAdvancedConcurrently(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    repo := newShuffleFixture(10)
+    svc := NewShuffleService(cfg, repo)
+
+    _, token, err := svc.NextQuote(ctx, "")
+    require.NoError(t, err)
+
+    repo.conflicts = 1
+    _, _, err = svc.NextQuote(ctx, token)
+    require.NoError(t, err)
+    // одна позиция занята параллельным запросом, вторая — нашим
+    require.Equal(t, 3, repo.sessions[token].Position)
+
+    repo.conflicts = shuffleRetries
+    _, _, err = svc.NextQuote(ctx, token)
+    require.ErrorIs(t, err, errdefs.ErrConflict)
+}
+
+func TestNextQuote_UnknownTokenStartsOver(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    repo := newShuffleFixture(3)
+    svc := NewShuffleService(cfg, repo)
+
+    _, token, err := svc.NextQuote(ctx, "0b5a4c7e-2d1f-4f7a-9a51-0d3a1c4b2e11")
+    require.NoError(t, err)
+    require.NotEqual(t, "0b5a4c7e-2d1f-4f7a-9a51-0d3a1c4b2e11", token)
+    require.Equal(t, 1, repo.sessions[token].Position)
+
+    _, _, err = svc.NextQuote(ctx, "not-a-token")
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+}
+
+func TestNextQuote_NoQuotes(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+
+    repo := newShuffleFixture(0)
+    _, _, err := NewShuffleService(cfg, repo).NextQuote(ctx, "")
+    require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+    // id есть, но все цитаты удалены
+    repo = newShuffleFixture(3, 1, 2, 3)
+    _, _, err = NewShuffleService(cfg, repo).NextQuote(ctx, "")
+    require.ErrorIs(t, err, errdefs.ErrNotFound)
+}
+
+func TestNextQuote_SparseIDs(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    ids := make([]int, 0, 50)
+    for i := 1; i <= 50; i++ {
+        ids = append(ids, i*1000)
+    }
+    repo := newSparseShuffleFixture(ids)
+    svc := NewShuffleService(cfg, repo)
+
+    // дыры в id не дают пустых позиций: один запрос рангов на цитату
+    seen := map[int]bool{}
+    token := ""
+    for i := 0; i < 50; i++ {
+        q, next, err := svc.NextQuote(ctx, token)
+        require.NoError(t, err)
+        require.False(t, seen[q.ID], "quote %d repeated", q.ID)
+        seen[q.ID] = true
+        token = next
+    }
+    require.Equal(t, 50, repo.lookups)
+}
+
+func TestNextQuote_DeletedMidRound(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    repo := newShuffleFixture(20)
+    svc := NewShuffleService(cfg, repo)
+
+    q, token, err := svc.NextQuote(ctx, "")
+    require.NoError(t, err)
+    seen := map[int]bool{q.ID: true}
+
+    // удалённые посреди круга пропускаются, остальные не повторяются
+    deleted := 0
+    for id := 1; id <= 20 && deleted < 5; id++ {
+        if !seen[id] {
+            delete(repo.alive, id)
+            deleted++
+        }
+    }
+    for i := 1; i < 15; i++ {
+        q, token, err = svc.NextQuote(ctx, token)
+        require.NoError(t, err)
+        require.False(t, seen[q.ID], "quote %d repeated", q.ID)
+        require.True(t, repo.alive[q.ID])
+        seen[q.ID] = true
+    }
+    require.Equal(t, 1, repo.sessions[token].Round)
+}
+
+func TestNextQuote_LegacySessionRestarts(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    repo := newShuffleFixture(5)
+    svc := NewShuffleService(cfg, repo)
+
+    // обход, начатый по id до перехода на ранги
+    token := "0b5a4c7e-2d1f-4f7a-9a51-0d3a1c4b2e11"
+    repo.sessions[token] = models.ShuffleSession{Token: token, Seed: 1, Size: 5, Position: 2, Round: 3}
+
+    _, _, err := svc.NextQuote(ctx, token)
+    require.NoError(t, err)
+    s := repo.sessions[token]
+    require.Equal(t, 4, s.Round)
+    require.Equal(t, 5, s.Size)
+    require.NotZero(t, s.SnapshotID)
+    require.Equal(t, 1, s.Position)
+}
+
+func TestNextQuote_RestoredAndPurgedMidRound(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    // 3 и 8 в корзине на начало круга
+    repo := newShuffleFixture(20, 3, 8)
+    svc := NewShuffleService(cfg, repo)
+
+    q, token, err := svc.NextQuote(ctx, "")
+    require.NoError(t, err)
+    seen := map[int]bool{q.ID: true}
+
+    // восстановленные ждут следующего круга, стёртые пропускаются; ранги
+    // остальных не сдвигаются, поэтому нет ни повторов, ни пропусков
+    repo.alive[3], repo.alive[8] = true, true
+    purged := 0
+    for id := 1; id <= 20 && purged < 2; id++ {
+        if !seen[id] && id != 3 && id != 8 {
+            delete(repo.alive, id)
+            purged++
+        }
+    }
+    for i := 1; i < 16; i++ {
+        q, token, err = svc.NextQuote(ctx, token)
+        require.NoError(t, err)
+        require.False(t, seen[q.ID], "quote %d repeated", q.ID)
+        require.NotContains(t, []int{3, 8}, q.ID)
+        seen[q.ID] = true
+    }
+    require.Equal(t, 1, repo.sessions[token].Round)
+
+    // круг исчерпан, в новом снимке восстановленные уже есть
+    _, token, err = svc.NextQuote(ctx, token)
+    require.NoError(t, err)
+    require.Equal(t, 2, repo.sessions[token].Round)
+    require.Contains(t, repo.snapshots[repo.sessions[token].SnapshotID], 3)
+}
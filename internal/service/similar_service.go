@@ -0,0 +1,152 @@
+package service
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "quotebook/config"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/interfaces"
+    "quotebook/internal/models"
+    "quotebook/internal/similar"
+)
+
+const (
+    defaultSimilarRefresh  = 10 * time.Second
+    defaultSimilarLimit    = 10
+    maxSimilarLimit        = 50
+    defaultSimilarMinScore = 0.05
+
+    // транзакция, начатая раньше, может записать updated_at меньше уже
+    // прочитанного; такие строки подбираются повторным чтением с запасом
+    similarOverlap = time.Minute
+)
+
+// similarIndex индекс похожих цитат и до какого updated_at он догнал базу.
+// Первое обращение строит индекс целиком, дальше в него вносятся только
+// созданные, изменённые, удалённые и восстановленные цитаты.
+type similarIndex struct {
+    mu        sync.Mutex
+    idx       *similar.Index
+    syncedAt  time.Time
+    watermark time.Time
+}
+
+type SimilarService struct {
+    repo interfaces.IQuoteRepository
+    cfg *config.Config
+    index *similarIndex
+}
+
+func NewSimilarService(cfg *config.Config, repo interfaces.IQuoteRepository) SimilarService {
+    return SimilarService{
+        repo: repo,
+        cfg: cfg,
+        index: &similarIndex{idx: similar.New()},
+    }
+}
+
+// quoteTerms термы цитаты: слова текста и теги, тег не совпадает со словом
+func quoteTerms(q *models.Quote) []string {
+    terms := similar.Terms(q.Quote)
+    for _, tag := range q.Tags {
+        terms = append(terms, "#"+tag)
+    }
+    return terms
+}
+
+// sync вносит в индекс цитаты, изменённые с прошлого раза; без force — не
+// чаще similar.refresh
+func (ss SimilarService) sync(ctx context.Context, force bool) error {
+    s := ss.index
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    refresh := time.Duration(ss.cfg.Similar.Refresh)
+    if refresh <= 0 {
+        refresh = defaultSimilarRefresh
+    }
+    if !force && !s.syncedAt.IsZero() && time.Since(s.syncedAt) < refresh {
+        return nil
+    }
+
+    var since time.Time
+    if !s.watermark.IsZero() {
+        since = s.watermark.Add(-similarOverlap)
+    }
+    changed, err := ss.repo.QuotesChangedSince(ctx, since)
+    if err != nil {
+        return err
+    }
+    for i := range *changed {
+        q := &(*changed)[i]
+        if q.DeletedAt != nil {
+            s.idx.Remove(q.ID)
+        } else {
+            s.idx.Put(q.ID, quoteTerms(q))
+        }
+        if q.UpdatedAt.After(s.watermark) {
+            s.watermark = q.UpdatedAt
+        }
+    }
+    s.syncedAt = time.Now()
+    return nil
+}
+
+// Similar до limit живых цитат, близких к id по словам и тегам, самые
+// близкие первыми; limit 0 — similar.limit
+func (ss SimilarService) Similar(ctx context.Context, id, limit int) (*[]models.SimilarQuote, error) {
+    switch {
+    case limit == 0:
+        limit = ss.cfg.Similar.Limit
+        if limit <= 0 {
+            limit = defaultSimilarLimit
+        }
+    case limit < 0 || limit > maxSimilarLimit:
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "limit must be between 1 and %d", maxSimilarLimit)
+    }
+    minScore := ss.cfg.Similar.MinScore
+    if minScore <= 0 {
+        minScore = defaultSimilarMinScore
+    }
+
+    if err := ss.sync(ctx, false); err != nil {
+        return nil, err
+    }
+    // цитату могли создать после последней синхронизации
+    if !ss.index.idx.Has(id) {
+        if err := ss.sync(ctx, true); err != nil {
+            return nil, err
+        }
+        if !ss.index.idx.Has(id) {
+            return nil, errdefs.ErrNotFound
+        }
+    }
+
+    matches := ss.index.idx.Similar(id, limit, minScore)
+    result := make([]models.SimilarQuote, 0, len(matches))
+    if len(matches) == 0 {
+        return &result, nil
+    }
+
+    ids := make([]int, len(matches))
+    for i, m := range matches {
+        ids[i] = m.ID
+    }
+    found, err := ss.repo.QuotesByIDs(ctx, ids)
+    if err != nil {
+        return nil, err
+    }
+    byID := make(map[int]models.Quote, len(*found))
+    for _, q := range *found {
+        byID[q.ID] = q
+    }
+    // удалённые после синхронизации пропускаются
+    for _, m := range matches {
+        if q, ok := byID[m.ID]; ok {
+            result = append(result, models.SimilarQuote{Score: m.Score, Quote: q})
+        }
+    }
+    return &result, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+func similarCorpus(at time.Time) *[]models.Quote {
+    return &[]models.Quote{
+        {ID: 1, Quote: "Life is what happens while you are busy making other plans", Tags: []string{"life"}, UpdatedAt: at},
+        {ID: 2, Quote: "Life is too short to make plans", Tags: []string{"life"}, UpdatedAt: at},
+        {ID: 3, Quote: "Knowledge is power", Tags: []string{"knowledge"}, UpdatedAt: at},
+        {ID: 4, Quote: "Power tends to corrupt", UpdatedAt: at},
+        {ID: 5, Quote: "Plans are worthless, but planning is everything", UpdatedAt: at},
+    }
+}
+
+func similarIDs(quotes *[]models.SimilarQuote) []int {
+    ids := []int{}
+    for _, q := range *quotes {
+        ids = append(ids, q.Quote.ID)
+    }
+    return ids
+}
+
+func TestSimilar_RanksByContent(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Similar.MinScore = 0.05
+    mockRepo := new(MockQuoteRepository)
+    svc := NewSimilarService(cfg, mockRepo)
+
+    at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+    mockRepo.On("QuotesChangedSince", ctx, time.Time{}).Return(similarCorpus(at), nil).Once()
+    // удалённую после синхронизации цитату 5 репозиторий уже не вернёт
+    mockRepo.On("QuotesByIDs", ctx, []int{2, 5}).Return(&[]models.Quote{{ID: 2}}, nil).Once()
+
+    got, err := svc.Similar(ctx, 1, 0)
+    require.NoError(t, err)
+    require.Equal(t, []int{2}, similarIDs(got))
+    require.Greater(t, (*got)[0].Score, 0.05)
+    require.LessOrEqual(t, (*got)[0].Score, 1.0)
+
+    // у цитаты 4 общее слово только с 3
+    mockRepo.On("QuotesByIDs", ctx, []int{3}).Return(&[]models.Quote{{ID: 3}}, nil).Once()
+    got, err = svc.Similar(ctx, 4, 5)
+    require.NoError(t, err)
+    require.Equal(t, []int{3}, similarIDs(got))
+
+    _, err = svc.Similar(ctx, 1, maxSimilarLimit+1)
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestSimilar_IncrementalSync(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Similar.Refresh = 1
+    cfg.Similar.MinScore = 0.05
+    mockRepo := new(MockQuoteRepository)
+    svc := NewSimilarService(cfg, mockRepo)
+
+    at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+    mockRepo.On("QuotesChangedSince", ctx, time.Time{}).Return(similarCorpus(at), nil).Once()
+    mockRepo.On("QuotesByIDs", ctx, []int{2, 5}).Return(&[]models.Quote{{ID: 2}, {ID: 5}}, nil).Once()
+    got, err := svc.Similar(ctx, 1, 0)
+    require.NoError(t, err)
+    require.Equal(t, []int{2, 5}, similarIDs(got))
+
+    // 2 ушла в корзину, 5 переписали, появилась 6; читается только изменённое
+    later := at.Add(time.Hour)
+    deleted := later
+    mockRepo.On("QuotesChangedSince", ctx, at.Add(-similarOverlap)).Return(&[]models.Quote{
+        {ID: 2, Quote: "Life is too short to make plans", UpdatedAt: later, DeletedAt: &deleted},
+        {ID: 5, Quote: "Knowledge is power", UpdatedAt: later},
+        {ID: 6, Quote: "Busy making plans for life", UpdatedAt: later},
+    }, nil).Once()
+    mockRepo.On("QuotesByIDs", ctx, []int{6}).Return(&[]models.Quote{{ID: 6}}, nil).Once()
+    got, err = svc.Similar(ctx, 1, 0)
+    require.NoError(t, err)
+    require.Equal(t, []int{6}, similarIDs(got))
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestSimilar_NotFound(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Similar.Refresh = 0
+    mockRepo := new(MockQuoteRepository)
+    svc := NewSimilarService(cfg, mockRepo)
+
+    at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+    mockRepo.On("QuotesChangedSince", ctx, time.Time{}).Return(similarCorpus(at), nil).Once()
+    // неизвестный id — повторная синхронизация без ожидания refresh
+    mockRepo.On("QuotesChangedSince", ctx, at.Add(-similarOverlap)).Return(&[]models.Quote{}, nil).Once()
+
+    _, err := svc.Similar(ctx, 42, 0)
+    require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+    mockRepo.AssertNotCalled(t, "QuotesByIDs", mock.Anything, mock.Anything)
+    mockRepo.AssertExpectations(t)
+}
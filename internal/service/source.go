@@ -0,0 +1,132 @@
+package service
+
+import (
+    "context"
+    "net/url"
+    "regexp"
+    "slices"
+    "strings"
+    "time"
+    "unicode/utf8"
+
+    "quotebook/internal/citation"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+const (
+    maxSourceTitle = 300
+    maxPublisher   = 200
+    maxSourceURL   = 2048
+    // самые ранние датированные тексты — около 3000 г. до н. э.
+    minSourceYear = -3000
+)
+
+var (
+    sourceTypes = map[string]bool{
+        models.SourceBook: true, models.SourceSpeech: true, models.SourceInterview: true,
+        models.SourceArticle: true, models.SourceOther: true,
+    }
+    // "12", "12-14", "xiv"
+    pagePattern = regexp.MustCompile(`^(?i:[0-9]+|[ivxlcdm]+)(?:[-–](?i:[0-9]+|[ivxlcdm]+))?$`)
+)
+
+// validateSource проверяет и нормализует источник; nil — источник не задан
+func validateSource(s *models.Source) error {
+    if s == nil {
+        return nil
+    }
+    s.Type = strings.ToLower(strings.TrimSpace(s.Type))
+    if !sourceTypes[s.Type] {
+        return errdefs.Wrapf(errdefs.ErrInvalidInput,
+            "source type must be book, speech, interview, article or other, got %q", s.Type)
+    }
+    s.Title = strings.TrimSpace(s.Title)
+    if s.Title == "" || utf8.RuneCountInString(s.Title) > maxSourceTitle {
+        return errdefs.Wrapf(errdefs.ErrInvalidInput, "source title must be 1 to %d characters", maxSourceTitle)
+    }
+    s.Publisher = strings.TrimSpace(s.Publisher)
+    if utf8.RuneCountInString(s.Publisher) > maxPublisher {
+        return errdefs.Wrapf(errdefs.ErrInvalidInput, "publisher is longer than %d characters", maxPublisher)
+    }
+    if s.Year != 0 && (s.Year < minSourceYear || s.Year > time.Now().Year()) {
+        return errdefs.Wrapf(errdefs.ErrInvalidInput, "source year must be between %d and the current year", minSourceYear)
+    }
+    s.Page = strings.TrimSpace(s.Page)
+    if s.Page != "" && !pagePattern.MatchString(s.Page) {
+        return errdefs.Wrapf(errdefs.ErrInvalidInput, "page must be a number or a range like 12-14, got %q", s.Page)
+    }
+    s.URL = strings.TrimSpace(s.URL)
+    if s.URL != "" {
+        u, err := url.Parse(s.URL)
+        if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(s.URL) > maxSourceURL {
+            return errdefs.Wrapf(errdefs.ErrInvalidInput, "source url must be an absolute http(s) URL, got %q", s.URL)
+        }
+    }
+    if s.ISBN != "" {
+        isbn, ok := normalizeISBN(s.ISBN)
+        if !ok {
+            return errdefs.Wrapf(errdefs.ErrInvalidInput, "invalid ISBN %q", s.ISBN)
+        }
+        s.ISBN = isbn
+    }
+    return nil
+}
+
+// normalizeISBN убирает дефисы и пробелы и проверяет контрольную цифру ISBN-10 или ISBN-13
+func normalizeISBN(raw string) (string, bool) {
+    isbn := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(raw))
+    switch len(isbn) {
+    case 10:
+        sum := 0
+        for i, r := range isbn {
+            d := int(r - '0')
+            if r == 'X' && i == 9 {
+                d = 10
+            } else if r < '0' || r > '9' {
+                return "", false
+            }
+            sum += d * (10 - i)
+        }
+        return isbn, sum%11 == 0
+    case 13:
+        sum := 0
+        for i, r := range isbn {
+            if r < '0' || r > '9' {
+                return "", false
+            }
+            d := int(r - '0')
+            if i%2 == 1 {
+                d *= 3
+            }
+            sum += d
+        }
+        return isbn, sum%10 == 0
+    }
+    return "", false
+}
+
+// Citation ссылка на источник цитаты id в стиле style (по умолчанию apa).
+// У цитаты без источника ссылки нет — ErrNotFound.
+func (qs QuoteService) Citation(ctx context.Context, id int, style string) (*models.Citation, error) {
+    if style == "" {
+        style = models.CitationAPA
+    }
+    style = strings.ToLower(style)
+    if !slices.Contains(citation.Styles, style) {
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput,
+            "unknown citation style %q: %s", style, strings.Join(citation.Styles, ", "))
+    }
+    quote, err := qs.repo.GetQuote(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    if quote.Source == nil {
+        return nil, errdefs.Wrapf(errdefs.ErrNotFound, "quote %d has no source", id)
+    }
+    text, html, err := citation.Format(style, quote.Author, quote.Source)
+    if err != nil {
+        return nil, errdefs.Wrap(errdefs.ErrInvalidInput, err.Error())
+    }
+    return &models.Citation{QuoteID: id, Style: style, Text: text, HTML: html}, nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+    "context"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+func TestValidateSource(t *testing.T) {
+    src := &models.Source{Type: " Book ", Title: " Analects ", Page: "12-14", ISBN: "978-0-14-044348-6"}
+    require.NoError(t, validateSource(src))
+    require.Equal(t, models.SourceBook, src.Type)
+    require.Equal(t, "Analects", src.Title)
+    require.Equal(t, "9780140443486", src.ISBN)
+
+    require.NoError(t, validateSource(&models.Source{Type: "book", Title: "x", ISBN: "0-306-40615-2"}))
+    require.NoError(t, validateSource(nil))
+
+    for name, bad := range map[string]*models.Source{
+        "type":  {Type: "tweet", Title: "x"},
+        "title": {Type: "book"},
+        "year":  {Type: "book", Title: "x", Year: 3000},
+        "page":  {Type: "book", Title: "x", Page: "twelve"},
+        "url":   {Type: "article", Title: "x", URL: "ftp://example.com"},
+        "isbn":  {Type: "book", Title: "x", ISBN: "978-0-14-044348-7"},
+    } {
+        require.ErrorIs(t, validateSource(bad), errdefs.ErrInvalidInput, name)
+    }
+}
+
+func TestCitation_Styles(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    src := &models.Source{Type: models.SourceBook, Title: "The World as I See It", Year: 1949, Publisher: "Philosophical Library"}
+    mockRepo.On("GetQuote", ctx, 1).Return(&models.Quote{ID: 1, Author: "Albert Einstein", Source: src}, nil)
+    mockRepo.On("GetQuote", ctx, 2).Return(&models.Quote{ID: 2, Author: "Anonymous"}, nil)
+
+    c, err := svc.Citation(ctx, 1, "")
+    require.NoError(t, err)
+    require.Equal(t, "Einstein, A. (1949). The World as I See It. Philosophical Library.", c.Text)
+    require.Equal(t, "Einstein, A. (1949). <i>The World as I See It</i>. Philosophical Library.", c.HTML)
+
+    c, err = svc.Citation(ctx, 1, "MLA")
+    require.NoError(t, err)
+    require.Equal(t, "Einstein, Albert. The World as I See It. Philosophical Library, 1949.", c.Text)
+
+    c, err = svc.Citation(ctx, 1, models.CitationChicago)
+    require.NoError(t, err)
+    require.Equal(t, "Einstein, Albert. The World as I See It. Philosophical Library, 1949.", c.Text)
+
+    _, err = svc.Citation(ctx, 1, "ieee")
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    _, err = svc.Citation(ctx, 2, "")
+    require.ErrorIs(t, err, errdefs.ErrNotFound)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+
+    "quotebook/config"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/interfaces"
+    "quotebook/internal/models"
+)
+
+const (
+    defaultStatsTTL     = 5 * time.Minute
+    defaultStatsTop     = 10
+    maxStatsTop         = 100
+    defaultStatsBuckets = 30
+)
+
+// statsCache посчитанные сводки по параметрам запроса, живут stats.ttl
+type statsCache struct {
+    mu      sync.Mutex
+    entries map[string]statsEntry
+}
+
+type statsEntry struct {
+    stats     *models.Stats
+    expiresAt time.Time
+}
+
+type StatsService struct {
+    repo interfaces.IStatsRepository
+    cfg *config.Config
+    cache *statsCache
+}
+
+func NewStatsService(cfg *config.Config, repo interfaces.IStatsRepository) StatsService {
+    return StatsService{
+        repo: repo,
+        cfg: cfg,
+        cache: &statsCache{entries: make(map[string]statsEntry)},
+    }
+}
+
+// Stats сводка по цитатам: top строк в топах (0 — stats.top), гистограмма
+// создания по interval (day, week или month, пустой — month). Сводка
+// пересчитывается не чаще stats.ttl.
+func (ss StatsService) Stats(ctx context.Context, top int, interval string) (*models.Stats, error) {
+    switch {
+    case top == 0:
+        top = ss.cfg.Stats.Top
+        if top <= 0 {
+            top = defaultStatsTop
+        }
+    case top < 0 || top > maxStatsTop:
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "top must be between 1 and %d", maxStatsTop)
+    }
+    switch interval {
+    case "":
+        interval = models.IntervalMonth
+    case models.IntervalDay, models.IntervalWeek, models.IntervalMonth:
+    default:
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "interval must be day, week or month, got %q", interval)
+    }
+    buckets := ss.cfg.Stats.Buckets
+    if buckets <= 0 {
+        buckets = defaultStatsBuckets
+    }
+    ttl := time.Duration(ss.cfg.Stats.TTL)
+    if ttl <= 0 {
+        ttl = defaultStatsTTL
+    }
+
+    key := fmt.Sprintf("%d/%s", top, interval)
+    now := time.Now()
+    c := ss.cache
+    c.mu.Lock()
+    e, ok := c.entries[key]
+    c.mu.Unlock()
+    if ok && now.Before(e.expiresAt) {
+        return e.stats, nil
+    }
+
+    // параллельные промахи посчитают сводку каждый сам: это дешевле, чем
+    // держать блокировку на время запросов
+    stats, err := ss.repo.Stats(ctx, top, interval, buckets)
+    if err != nil {
+        return nil, err
+    }
+    c.mu.Lock()
+    for k, old := range c.entries {
+        if !now.Before(old.expiresAt) {
+            delete(c.entries, k)
+        }
+    }
+    c.entries[key] = statsEntry{stats: stats, expiresAt: now.Add(ttl)}
+    c.mu.Unlock()
+    return stats, nil
+}
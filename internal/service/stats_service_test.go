@@ -0,0 +1,80 @@
+package service
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+
+    "quotebook/config"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+type MockStatsRepository struct {
+    mock.Mock
+}
+
+func (m *MockStatsRepository) Stats(ctx context.Context, top int, interval string, buckets int) (*models.Stats, error) {
+    args := m.Called(ctx, top, interval, buckets)
+    return args.Get(0).(*models.Stats), args.Error(1)
+}
+
+func TestStats_Validation(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockStatsRepository)
+    svc := NewStatsService(cfg, mockRepo)
+
+    _, err := svc.Stats(ctx, 101, "")
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    _, err = svc.Stats(ctx, 5, "year")
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.AssertNotCalled(t, "Stats", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStats_CachedForTTL(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Stats = config.StatsConfig{TTL: config.Duration(time.Hour), Top: 3, Buckets: 12}
+    mockRepo := new(MockStatsRepository)
+    svc := NewStatsService(cfg, mockRepo)
+
+    monthly := &models.Stats{Quotes: 7, Interval: models.IntervalMonth}
+    daily := &models.Stats{Quotes: 7, Interval: models.IntervalDay}
+    mockRepo.On("Stats", ctx, 3, models.IntervalMonth, 12).Return(monthly, nil).Once()
+    mockRepo.On("Stats", ctx, 3, models.IntervalDay, 12).Return(daily, nil).Once()
+
+    for i := 0; i < 3; i++ {
+        got, err := svc.Stats(ctx, 0, "")
+        require.NoError(t, err)
+        require.Same(t, monthly, got)
+    }
+    // другие параметры — другая сводка
+    got, err := svc.Stats(ctx, 3, models.IntervalDay)
+    require.NoError(t, err)
+    require.Same(t, daily, got)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestStats_Expires(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Stats = config.StatsConfig{TTL: config.Duration(time.Nanosecond), Top: 3, Buckets: 12}
+    mockRepo := new(MockStatsRepository)
+    svc := NewStatsService(cfg, mockRepo)
+
+    mockRepo.On("Stats", ctx, 3, models.IntervalMonth, 12).Return(&models.Stats{}, nil).Twice()
+
+    _, err := svc.Stats(ctx, 0, "")
+    require.NoError(t, err)
+    time.Sleep(time.Millisecond)
+    _, err = svc.Stats(ctx, 0, "")
+    require.NoError(t, err)
+
+    mockRepo.AssertExpectations(t)
+}
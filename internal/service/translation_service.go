@@ -0,0 +1,156 @@
+package service
+
+import (
+    "context"
+    "regexp"
+    "strings"
+    "unicode/utf8"
+
+    "quotebook/config"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/interfaces"
+    "quotebook/internal/langdetect"
+    "quotebook/internal/models"
+)
+
+// код языка ISO 639-1 или 639-3, без региона: "en", "ru", "grc"
+var langPattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// normalizeLang приводит код к нижнему регистру и отрезает регион: "en-US" -> "en"
+func normalizeLang(lang string) (string, error) {
+    lang = strings.ToLower(strings.TrimSpace(lang))
+    lang, _, _ = strings.Cut(lang, "-")
+    if !langPattern.MatchString(lang) {
+        return "", errdefs.Wrapf(errdefs.ErrInvalidInput, "lang must be an ISO 639 code like en or ru, got %q", lang)
+    }
+    return lang, nil
+}
+
+// normalizeKnownLang как normalizeLang, но und не принимается:
+// переводить и искать можно только на определённый язык
+func normalizeKnownLang(lang string) (string, error) {
+    lang, err := normalizeLang(lang)
+    if err == nil && lang == langdetect.Undetermined {
+        return "", errdefs.Wrap(errdefs.ErrInvalidInput, "lang must not be und")
+    }
+    return lang, err
+}
+
+type TranslationService struct {
+    repo interfaces.ITranslationRepository
+    quotes interfaces.IQuoteRepository
+    cfg *config.Config
+}
+
+func NewTranslationService(cfg *config.Config, repo interfaces.ITranslationRepository, quotes interfaces.IQuoteRepository) TranslationService {
+    return TranslationService{
+        repo: repo,
+        quotes: quotes,
+        cfg: cfg,
+    }
+}
+
+// PutTranslation создаёт или заменяет перевод цитаты quoteID на lang;
+// второе значение — перевод создан
+func (ts TranslationService) PutTranslation(ctx context.Context, quoteID int, lang string, t *models.Translation) (*models.Translation, bool, error) {
+    lang, err := normalizeKnownLang(lang)
+    if err != nil {
+        return nil, false, err
+    }
+    t.QuoteID, t.Lang = quoteID, lang
+    t.Quote = strings.TrimSpace(t.Quote)
+    t.Translator = strings.TrimSpace(t.Translator)
+    switch {
+    case t.Quote == "":
+        return nil, false, errdefs.Wrap(errdefs.ErrInvalidInput, "quote required")
+    case utf8.RuneCountInString(t.Translator) > maxAuthorLen:
+        return nil, false, errdefs.Wrapf(errdefs.ErrInvalidInput, "translator longer than %d characters", maxAuthorLen)
+    }
+    created, err := ts.repo.PutTranslation(ctx, t)
+    if err != nil {
+        return nil, false, err
+    }
+    return t, created, nil
+}
+
+// Translation перевод живой цитаты на lang
+func (ts TranslationService) Translation(ctx context.Context, quoteID int, lang string) (*models.Translation, error) {
+    lang, err := normalizeKnownLang(lang)
+    if err != nil {
+        return nil, err
+    }
+    if _, err := ts.quotes.GetQuote(ctx, quoteID); err != nil {
+        return nil, err
+    }
+    return ts.repo.GetTranslation(ctx, quoteID, lang)
+}
+
+// Translations все переводы живой цитаты
+func (ts TranslationService) Translations(ctx context.Context, quoteID int) (*[]models.Translation, error) {
+    if _, err := ts.quotes.GetQuote(ctx, quoteID); err != nil {
+        return nil, err
+    }
+    return ts.repo.Translations(ctx, quoteID)
+}
+
+func (ts TranslationService) DeleteTranslation(ctx context.Context, quoteID int, lang string) error {
+    lang, err := normalizeKnownLang(lang)
+    if err != nil {
+        return err
+    }
+    return ts.repo.DeleteTranslation(ctx, quoteID, lang)
+}
+
+// Localize заменяет текст цитат переводом на первый подходящий язык из prefs
+// (в порядке предпочтения). Если оригинал стоит в prefs раньше любого перевода,
+// цитата не меняется. У переведённой цитаты Lang — язык перевода,
+// OriginalLang — язык оригинала.
+func (ts TranslationService) Localize(ctx context.Context, quotes []models.Quote, prefs []string) error {
+    langs := make([]string, 0, len(prefs))
+    for _, p := range prefs {
+        if lang, err := normalizeKnownLang(p); err == nil {
+            langs = append(langs, lang)
+        }
+    }
+    if len(langs) == 0 || len(quotes) == 0 {
+        return nil
+    }
+
+    // переводы нужны только тем цитатам, чей язык не первый в списке
+    ids := make([]int, 0, len(quotes))
+    for i := range quotes {
+        if quotes[i].Lang != langs[0] {
+            ids = append(ids, quotes[i].ID)
+        }
+    }
+    if len(ids) == 0 {
+        return nil
+    }
+    found, err := ts.repo.TranslationsOf(ctx, ids, langs)
+    if err != nil {
+        return err
+    }
+    type key struct {
+        id   int
+        lang string
+    }
+    byKey := make(map[key]*models.Translation, len(*found))
+    for i := range *found {
+        t := &(*found)[i]
+        byKey[key{t.QuoteID, t.Lang}] = t
+    }
+
+    for i := range quotes {
+        q := &quotes[i]
+        for _, lang := range langs {
+            if lang == q.Lang {
+                break
+            }
+            if t, ok := byKey[key{q.ID, lang}]; ok {
+                q.OriginalLang, q.Lang, q.Quote = q.Lang, t.Lang, t.Quote
+                break
+            }
+        }
+    }
+    return nil
+}
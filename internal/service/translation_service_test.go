@@ -0,0 +1,133 @@
+package service
+
+import (
+    "context"
+    "testing"
+
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+type MockTranslationRepository struct {
+    mock.Mock
+}
+
+func (m *MockTranslationRepository) PutTranslation(ctx context.Context, t *models.Translation) (bool, error) {
+    args := m.Called(ctx, t)
+    return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTranslationRepository) GetTranslation(ctx context.Context, quoteID int, lang string) (*models.Translation, error) {
+    args := m.Called(ctx, quoteID, lang)
+    return args.Get(0).(*models.Translation), args.Error(1)
+}
+
+func (m *MockTranslationRepository) Translations(ctx context.Context, quoteID int) (*[]models.Translation, error) {
+    args := m.Called(ctx, quoteID)
+    return args.Get(0).(*[]models.Translation), args.Error(1)
+}
+
+func (m *MockTranslationRepository) TranslationsOf(ctx context.Context, ids []int, langs []string) (*[]models.Translation, error) {
+    args := m.Called(ctx, ids, langs)
+    return args.Get(0).(*[]models.Translation), args.Error(1)
+}
+
+func (m *MockTranslationRepository) DeleteTranslation(ctx context.Context, quoteID int, lang string) error {
+    args := m.Called(ctx, quoteID, lang)
+    return args.Error(0)
+}
+
+func TestPutTranslation_Validation(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockTranslationRepository)
+    svc := NewTranslationService(cfg, mockRepo, new(MockQuoteRepository))
+
+    _, _, err := svc.PutTranslation(ctx, 1, "und", &models.Translation{Quote: "x"})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    _, _, err = svc.PutTranslation(ctx, 1, "english", &models.Translation{Quote: "x"})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    _, _, err = svc.PutTranslation(ctx, 1, "en", &models.Translation{Quote: "   "})
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.On("PutTranslation", ctx, &models.Translation{QuoteID: 1, Lang: "en", Quote: "To be or not to be"}).
+        Return(true, nil).Once()
+
+    tr, created, err := svc.PutTranslation(ctx, 1, "EN-us", &models.Translation{QuoteID: 7, Quote: " To be or not to be "})
+    require.NoError(t, err)
+    require.True(t, created)
+    require.Equal(t, "en", tr.Lang)
+    require.Equal(t, 1, tr.QuoteID)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestTranslations_QuoteNotFound(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockTranslationRepository)
+    mockQuotes := new(MockQuoteRepository)
+    svc := NewTranslationService(cfg, mockRepo, mockQuotes)
+
+    mockQuotes.On("GetQuote", ctx, 9).Return((*models.Quote)(nil), errdefs.ErrNotFound).Once()
+
+    _, err := svc.Translations(ctx, 9)
+    require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+    mockRepo.AssertNotCalled(t, "Translations", mock.Anything, mock.Anything)
+    mockQuotes.AssertExpectations(t)
+}
+
+func TestLocalize_PicksPreferredLanguage(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockTranslationRepository)
+    svc := NewTranslationService(cfg, mockRepo, new(MockQuoteRepository))
+
+    quotes := []models.Quote{
+        {ID: 1, Lang: "en", Quote: "To be or not to be"},
+        {ID: 2, Lang: "de", Quote: "Ich denke"},
+        {ID: 3, Lang: "ru", Quote: "Быть или не быть"},
+        {ID: 4, Lang: "fr", Quote: "Je pense"},
+    }
+    // id 3 уже на первом языке, переводы ему не нужны
+    mockRepo.On("TranslationsOf", ctx, []int{1, 2, 4}, []string{"ru", "en"}).Return(&[]models.Translation{
+        {QuoteID: 1, Lang: "ru", Quote: "Быть или не быть"},
+        {QuoteID: 2, Lang: "en", Quote: "I think"},
+        {QuoteID: 2, Lang: "ru", Quote: "Я думаю"},
+    }, nil).Once()
+
+    err := svc.Localize(ctx, quotes, []string{"ru", "*", "en"})
+    require.NoError(t, err)
+    require.Equal(t, models.Quote{ID: 1, Lang: "ru", OriginalLang: "en", Quote: "Быть или не быть"}, quotes[0])
+    require.Equal(t, models.Quote{ID: 2, Lang: "ru", OriginalLang: "de", Quote: "Я думаю"}, quotes[1])
+    require.Equal(t, models.Quote{ID: 3, Lang: "ru", Quote: "Быть или не быть"}, quotes[2])
+    // перевода нет ни на один язык — остаётся оригинал
+    require.Equal(t, models.Quote{ID: 4, Lang: "fr", Quote: "Je pense"}, quotes[3])
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestLocalize_OriginalBeforeTranslation(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockTranslationRepository)
+    svc := NewTranslationService(cfg, mockRepo, new(MockQuoteRepository))
+
+    quotes := []models.Quote{{ID: 1, Lang: "en", Quote: "To be or not to be"}}
+    mockRepo.On("TranslationsOf", ctx, []int{1}, []string{"de", "en", "ru"}).Return(&[]models.Translation{
+        {QuoteID: 1, Lang: "ru", Quote: "Быть или не быть"},
+    }, nil).Once()
+
+    require.NoError(t, svc.Localize(ctx, quotes, []string{"de", "en", "ru"}))
+    require.Equal(t, "To be or not to be", quotes[0].Quote)
+    require.Empty(t, quotes[0].OriginalLang)
+
+    // без предпочтений база не трогается
+    require.NoError(t, svc.Localize(ctx, quotes, nil))
+
+    mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+    "context"
+    "sort"
+    "sync"
+    "time"
+
+    "quotebook/config"
+    "quotebook/internal/errdefs"
+    "quotebook/internal/interfaces"
+    "quotebook/internal/models"
+)
+
+const (
+    defaultTrendingLimit = 20
+    maxTrendingLimit     = 100
+    defaultViewRetention = 8 * 24 * time.Hour
+)
+
+// trendingWindows окна /quotes/trending; просмотр весит вдвое меньше за
+// каждую четверть окна своего возраста
+var trendingWindows = map[string]time.Duration{
+    models.TrendingDay:  24 * time.Hour,
+    models.TrendingWeek: 7 * 24 * time.Hour,
+}
+
+type viewKey struct {
+    id   int
+    hour time.Time
+}
+
+// viewCounter просмотры, ещё не записанные в базу. Запрос только
+// увеличивает счётчик в памяти, в базу пишет Flush.
+type viewCounter struct {
+    mu      sync.Mutex
+    pending map[viewKey]*models.ViewCount
+}
+
+func (c *viewCounter) add(id int, served int64) {
+    hour := time.Now().Truncate(time.Hour)
+    key := viewKey{id: id, hour: hour}
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    vc, ok := c.pending[key]
+    if !ok {
+        vc = &models.ViewCount{QuoteID: id, Hour: hour}
+        c.pending[key] = vc
+    }
+    vc.Views++
+    vc.Served += served
+}
+
+// take забирает накопленное, счётчик начинается заново
+func (c *viewCounter) take() map[viewKey]*models.ViewCount {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    pending := c.pending
+    c.pending = make(map[viewKey]*models.ViewCount)
+    return pending
+}
+
+// putBack возвращает то, что не удалось записать, к накопленному после take
+func (c *viewCounter) putBack(pending map[viewKey]*models.ViewCount) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    for key, vc := range pending {
+        if cur, ok := c.pending[key]; ok {
+            cur.Views += vc.Views
+            cur.Served += vc.Served
+        } else {
+            c.pending[key] = vc
+        }
+    }
+}
+
+type ViewService struct {
+    repo interfaces.IViewRepository
+    cfg *config.Config
+    counter *viewCounter
+}
+
+func NewViewService(cfg *config.Config, repo interfaces.IViewRepository) ViewService {
+    return ViewService{
+        repo: repo,
+        cfg: cfg,
+        counter: &viewCounter{pending: make(map[viewKey]*models.ViewCount)},
+    }
+}
+
+// View засчитывает просмотр цитаты id; в базу он попадёт при следующем Flush
+func (vs ViewService) View(id int) {
+    vs.counter.add(id, 0)
+}
+
+// Serve засчитывает цитату id, отданную /quotes/random: это и просмотр,
+// и выпадение для most_served в /stats
+func (vs ViewService) Serve(id int) {
+    vs.counter.add(id, 1)
+}
+
+// Flush записывает накопленные просмотры одной транзакцией и возвращает,
+// сколько пар (цитата, час) записано. При ошибке просмотры остаются в памяти
+// до следующей попытки.
+func (vs ViewService) Flush(ctx context.Context) (int, error) {
+    pending := vs.counter.take()
+    if len(pending) == 0 {
+        return 0, nil
+    }
+
+    counts := make([]models.ViewCount, 0, len(pending))
+    for _, vc := range pending {
+        counts = append(counts, *vc)
+    }
+    // одинаковый порядок строк у параллельных сбросов, чтобы не ловить deadlock
+    sort.Slice(counts, func(i, j int) bool {
+        if counts[i].QuoteID != counts[j].QuoteID {
+            return counts[i].QuoteID < counts[j].QuoteID
+        }
+        return counts[i].Hour.Before(counts[j].Hour)
+    })
+
+    if err := vs.repo.AddViews(ctx, counts); err != nil {
+        vs.counter.putBack(pending)
+        return 0, err
+    }
+    return len(counts), nil
+}
+
+// Trending самые просматриваемые за window (24h или 7d, пустое — 24h) цитаты,
+// свежие просмотры весят больше; limit 0 — views.trendingLimit
+func (vs ViewService) Trending(ctx context.Context, window string, limit int) (*[]models.TrendingQuote, error) {
+    if window == "" {
+        window = models.TrendingDay
+    }
+    span, ok := trendingWindows[window]
+    if !ok {
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "window must be %s or %s, got %q",
+            models.TrendingDay, models.TrendingWeek, window)
+    }
+    switch {
+    case limit == 0:
+        limit = vs.cfg.Views.TrendingLimit
+        if limit <= 0 {
+            limit = defaultTrendingLimit
+        }
+    case limit < 0 || limit > maxTrendingLimit:
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "limit must be between 1 and %d", maxTrendingLimit)
+    }
+
+    return vs.repo.Trending(ctx, time.Now().Add(-span), span/4, limit)
+}
+
+// PurgeExpired удаляет почасовые просмотры старше views.retention, но не
+// моложе самого длинного окна /quotes/trending
+func (vs ViewService) PurgeExpired(ctx context.Context) (int64, error) {
+    retention := time.Duration(vs.cfg.Views.Retention)
+    if retention <= 0 {
+        retention = defaultViewRetention
+    }
+    retention = max(retention, trendingWindows[models.TrendingWeek]+time.Hour)
+    return vs.repo.PurgeViews(ctx, time.Now().Add(-retention))
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+type MockViewRepository struct {
+    mock.Mock
+}
+
+func (m *MockViewRepository) AddViews(ctx context.Context, counts []models.ViewCount) error {
+    args := m.Called(ctx, counts)
+    return args.Error(0)
+}
+
+func (m *MockViewRepository) Trending(ctx context.Context, since time.Time, halfLife time.Duration, limit int) (*[]models.TrendingQuote, error) {
+    args := m.Called(ctx, since, halfLife, limit)
+    return args.Get(0).(*[]models.TrendingQuote), args.Error(1)
+}
+
+func (m *MockViewRepository) PurgeViews(ctx context.Context, before time.Time) (int64, error) {
+    args := m.Called(ctx, before)
+    return args.Get(0).(int64), args.Error(1)
+}
+
+// viewTotals просмотры и выпадения по цитатам без учёта часа
+func viewTotals(counts []models.ViewCount) map[int][2]int64 {
+    totals := make(map[int][2]int64)
+    for _, c := range counts {
+        t := totals[c.QuoteID]
+        totals[c.QuoteID] = [2]int64{t[0] + c.Views, t[1] + c.Served}
+    }
+    return totals
+}
+
+func TestViewFlush_Batches(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockViewRepository)
+    svc := NewViewService(cfg, mockRepo)
+
+    svc.View(2)
+    svc.View(1)
+    svc.Serve(2)
+    svc.View(2)
+
+    mockRepo.On("AddViews", ctx, mock.MatchedBy(func(counts []models.ViewCount) bool {
+        return counts[0].QuoteID <= counts[len(counts)-1].QuoteID &&
+            viewTotals(counts)[1] == [2]int64{1, 0} && viewTotals(counts)[2] == [2]int64{3, 1}
+    })).Return(nil).Once()
+
+    _, err := svc.Flush(ctx)
+    require.NoError(t, err)
+
+    // всё записано, повторный сброс базу не трогает
+    n, err := svc.Flush(ctx)
+    require.NoError(t, err)
+    require.Zero(t, n)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestViewFlush_KeepsOnError(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockViewRepository)
+    svc := NewViewService(cfg, mockRepo)
+
+    svc.Serve(5)
+    mockRepo.On("AddViews", ctx, mock.Anything).Return(errdefs.Wrap(errdefs.ErrDB, "down")).Once()
+    _, err := svc.Flush(ctx)
+    require.ErrorIs(t, err, errdefs.ErrDB)
+
+    // непрошедшие просмотры складываются с новыми
+    svc.View(5)
+    mockRepo.On("AddViews", ctx, mock.MatchedBy(func(counts []models.ViewCount) bool {
+        return viewTotals(counts)[5] == [2]int64{2, 1}
+    })).Return(nil).Once()
+    _, err = svc.Flush(ctx)
+    require.NoError(t, err)
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestTrending_Validation(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    cfg.Views.TrendingLimit = 7
+    mockRepo := new(MockViewRepository)
+    svc := NewViewService(cfg, mockRepo)
+
+    _, err := svc.Trending(ctx, "1h", 0)
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    _, err = svc.Trending(ctx, models.TrendingWeek, 101)
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    mockRepo.AssertNotCalled(t, "Trending", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+    trending := &[]models.TrendingQuote{{Views: 3, Quote: models.Quote{ID: 1}}}
+    mockRepo.On("Trending", ctx, mock.MatchedBy(func(since time.Time) bool {
+        return time.Since(since).Round(time.Hour) == 24*time.Hour
+    }), 6*time.Hour, 7).Return(trending, nil).Once()
+    mockRepo.On("Trending", ctx, mock.Anything, 42*time.Hour, 3).Return(trending, nil).Once()
+
+    got, err := svc.Trending(ctx, "", 0)
+    require.NoError(t, err)
+    require.Same(t, trending, got)
+    _, err = svc.Trending(ctx, models.TrendingWeek, 3)
+    require.NoError(t, err)
+
+    mockRepo.AssertExpectations(t)
+}
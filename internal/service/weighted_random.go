@@ -0,0 +1,229 @@
+package service
+
+import (
+    "context"
+    "math"
+    "math/rand/v2"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+const (
+    defaultSamplerRefresh  = time.Minute
+    defaultRecencyHalfLife = 30 * 24 * time.Hour
+
+    // сглаживание рейтинга: у цитаты без оценок как будто ratingPrior оценок ratingMean
+    ratingPrior = 5
+    ratingMean  = 3.0
+
+    // сколько раз перевыбирать, если выпавшую цитату успели удалить
+    sampleRetries = 3
+)
+
+// aliasTable выборка по весам за O(1) (метод Уолкера, вариант Воуза)
+type aliasTable struct {
+    ids   []int
+    prob  []float64
+    alias []int
+}
+
+// newAliasTable nil, если все веса нулевые
+func newAliasTable(ids []int, weights []float64) *aliasTable {
+    n := len(ids)
+    total := 0.0
+    for _, w := range weights {
+        total += w
+    }
+    if n == 0 || total <= 0 {
+        return nil
+    }
+
+    t := &aliasTable{ids: ids, prob: make([]float64, n), alias: make([]int, n)}
+    scaled := make([]float64, n)
+    var small, large []int
+    for i, w := range weights {
+        scaled[i] = w * float64(n) / total
+        if scaled[i] < 1 {
+            small = append(small, i)
+        } else {
+            large = append(large, i)
+        }
+    }
+    for len(small) > 0 && len(large) > 0 {
+        s, l := small[len(small)-1], large[len(large)-1]
+        small = small[:len(small)-1]
+        t.prob[s], t.alias[s] = scaled[s], l
+        scaled[l] -= 1 - scaled[s]
+        if scaled[l] < 1 {
+            large = large[:len(large)-1]
+            small = append(small, l)
+        }
+    }
+    // остатки — погрешность округления, их вероятность 1
+    for _, i := range append(small, large...) {
+        t.prob[i] = 1
+    }
+    return t
+}
+
+func (t *aliasTable) pick() int {
+    i := rand.IntN(len(t.ids))
+    if rand.Float64() < t.prob[i] {
+        return t.ids[i]
+    }
+    return t.ids[t.alias[i]]
+}
+
+// samplerSnapshot снимок весов и таблицы по стратегиям, построенные по нему.
+// Веса снимка не меняются: удалённая после снимка цитата заменяет его копией
+// без неё, таблицы строятся лениво.
+type samplerSnapshot struct {
+    builtAt time.Time
+    weights []models.QuoteWeight
+
+    mu     sync.Mutex
+    tables map[string]*aliasTable
+}
+
+func newSamplerSnapshot(weights []models.QuoteWeight, builtAt time.Time) *samplerSnapshot {
+    return &samplerSnapshot{builtAt: builtAt, weights: weights, tables: make(map[string]*aliasTable)}
+}
+
+// without копия снимка без цитаты id
+func (s *samplerSnapshot) without(id int) *samplerSnapshot {
+    weights := make([]models.QuoteWeight, 0, len(s.weights))
+    for _, w := range s.weights {
+        if w.ID != id {
+            weights = append(weights, w)
+        }
+    }
+    return newSamplerSnapshot(weights, s.builtAt)
+}
+
+// table таблица стратегии; строится без блокировки, так что два
+// одновременных запроса в худшем случае построят её дважды. Пока оценок или
+// просмотров нет, веса rating и views равны, и выбор равномерный.
+func (s *samplerSnapshot) table(strategy string, halfLife time.Duration) *aliasTable {
+    s.mu.Lock()
+    t, ok := s.tables[strategy]
+    s.mu.Unlock()
+    if ok {
+        return t
+    }
+
+    ids := make([]int, len(s.weights))
+    ws := make([]float64, len(s.weights))
+    for i := range s.weights {
+        ids[i] = s.weights[i].ID
+        ws[i] = strategyWeight(strategy, &s.weights[i], s.builtAt, halfLife)
+    }
+    t = newAliasTable(ids, ws)
+
+    s.mu.Lock()
+    s.tables[strategy] = t
+    s.mu.Unlock()
+    return t
+}
+
+// samplerCache текущий снимок весов. Снимок читается из базы не чаще
+// random.refresh и подменяется целиком, сама выборка базу не трогает.
+type samplerCache struct {
+    current atomic.Pointer[samplerSnapshot]
+    // снимок перечитывает один запрос, остальные пока выбирают по старому
+    loading sync.Mutex
+}
+
+func newSamplerCache() *samplerCache {
+    return &samplerCache{}
+}
+
+// strategyWeight вес цитаты по стратегии на момент now
+func strategyWeight(strategy string, w *models.QuoteWeight, now time.Time, halfLife time.Duration) float64 {
+    switch strategy {
+    case models.RandomRating:
+        // байесовское среднее: одна пятёрка не перевешивает сотню четвёрок
+        return (float64(w.RatingSum) + ratingPrior*ratingMean) / float64(w.RatingCount+ratingPrior)
+    case models.RandomRecency:
+        age := max(now.Sub(w.CreatedAt), 0)
+        // старые цитаты выпадают реже, но не исчезают совсем
+        return math.Exp2(-float64(age)/float64(halfLife)) + 0.01
+    case models.RandomViews:
+        // логарифм, чтобы одна популярная цитата не забирала все выпадения
+        return 1 + math.Log1p(float64(w.Views))
+    case models.RandomWeight:
+        return w.Weight
+    }
+    return 1
+}
+
+// snapshot текущий снимок весов, перечитывается, если устарел
+func (qs QuoteService) snapshot(ctx context.Context) (*samplerSnapshot, error) {
+    c := qs.samplers
+    refresh := time.Duration(qs.cfg.Random.Refresh)
+    if refresh <= 0 {
+        refresh = defaultSamplerRefresh
+    }
+
+    snap := c.current.Load()
+    if snap != nil && time.Since(snap.builtAt) <= refresh {
+        return snap, nil
+    }
+    if snap == nil {
+        c.loading.Lock()
+    } else if !c.loading.TryLock() {
+        return snap, nil
+    }
+    defer c.loading.Unlock()
+    // пока ждали, снимок мог перечитать другой запрос
+    if cur := c.current.Load(); cur != snap {
+        return cur, nil
+    }
+
+    weights, err := qs.repo.QuoteWeights(ctx)
+    if err != nil {
+        return nil, err
+    }
+    snap = newSamplerSnapshot(*weights, time.Now())
+    c.current.Store(snap)
+    return snap, nil
+}
+
+// WeightedQuote случайная цитата по стратегии: uniform, rating, recency, views, weight
+func (qs QuoteService) WeightedQuote(ctx context.Context, strategy string) (*models.Quote, error) {
+    switch strategy {
+    case "", models.RandomUniform:
+        return qs.repo.RandQuote(ctx)
+    case models.RandomRating, models.RandomRecency, models.RandomViews, models.RandomWeight:
+    default:
+        return nil, errdefs.Wrapf(errdefs.ErrInvalidInput,
+            "unknown strategy %q: uniform, rating, recency, views or weight", strategy)
+    }
+
+    halfLife := time.Duration(qs.cfg.Random.RecencyHalfLife)
+    if halfLife <= 0 {
+        halfLife = defaultRecencyHalfLife
+    }
+    for attempt := 0; attempt < sampleRetries; attempt++ {
+        snap, err := qs.snapshot(ctx)
+        if err != nil {
+            return nil, err
+        }
+        t := snap.table(strategy, halfLife)
+        if t == nil {
+            return nil, errdefs.ErrNotFound
+        }
+        id := t.pick()
+        quote, err := qs.repo.GetQuote(ctx, id)
+        if !errdefs.Is(err, errdefs.ErrNotFound) {
+            return quote, err
+        }
+        // цитату удалили после снимка: она убирается из снимка без
+        // перечитывания; если снимок уже подменили, достаточно нового
+        qs.samplers.current.CompareAndSwap(snap, snap.without(id))
+    }
+    return nil, errdefs.ErrNotFound
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+
+    "quotebook/internal/errdefs"
+    "quotebook/internal/models"
+)
+
+func TestAliasTable_Distribution(t *testing.T) {
+    table := newAliasTable([]int{1, 2, 3, 4}, []float64{1, 2, 7, 0})
+
+    counts := map[int]int{}
+    const n = 100000
+    for i := 0; i < n; i++ {
+        counts[table.pick()]++
+    }
+    require.Zero(t, counts[4])
+    require.InDelta(t, 0.1, float64(counts[1])/n, 0.01)
+    require.InDelta(t, 0.2, float64(counts[2])/n, 0.01)
+    require.InDelta(t, 0.7, float64(counts[3])/n, 0.01)
+
+    require.Nil(t, newAliasTable([]int{1}, []float64{0}))
+}
+
+func TestStrategyWeight(t *testing.T) {
+    now := time.Now()
+    halfLife := 24 * time.Hour
+
+    unrated := &models.QuoteWeight{}
+    loved := &models.QuoteWeight{RatingSum: 50, RatingCount: 10}
+    require.Equal(t, ratingMean, strategyWeight(models.RandomRating, unrated, now, halfLife))
+    require.Greater(t, strategyWeight(models.RandomRating, loved, now, halfLife), ratingMean)
+
+    fresh := &models.QuoteWeight{CreatedAt: now}
+    old := &models.QuoteWeight{CreatedAt: now.Add(-halfLife)}
+    require.InDelta(t, 2*strategyWeight(models.RandomRecency, old, now, halfLife)-0.01,
+        strategyWeight(models.RandomRecency, fresh, now, halfLife), 0.02)
+
+    require.Greater(t, strategyWeight(models.RandomViews, &models.QuoteWeight{Views: 100}, now, halfLife),
+        strategyWeight(models.RandomViews, &models.QuoteWeight{}, now, halfLife))
+}
+
+func TestWeightedQuote_UsesSnapshot(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    // снимок читается один раз на все вызовы
+    mockRepo.On("QuoteWeights", ctx).
+        Return(&[]models.QuoteWeight{{ID: 1, Weight: 0}, {ID: 2, Weight: 5}}, nil).Once()
+    mockRepo.On("GetQuote", ctx, 2).Return(&models.Quote{ID: 2}, nil).Times(20)
+
+    for i := 0; i < 20; i++ {
+        q, err := svc.WeightedQuote(ctx, models.RandomWeight)
+        require.NoError(t, err)
+        require.Equal(t, 2, q.ID)
+    }
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestWeightedQuote_DeletedQuoteSkipped(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    // удалённая цитата убирается из снимка, снимок не перечитывается
+    mockRepo.On("QuoteWeights", ctx).
+        Return(&[]models.QuoteWeight{{ID: 1, Weight: maxWeight}, {ID: 3, Weight: 1}}, nil).Once()
+    mockRepo.On("GetQuote", ctx, 1).Return((*models.Quote)(nil), errdefs.ErrNotFound).Once()
+    mockRepo.On("GetQuote", ctx, 3).Return(&models.Quote{ID: 3}, nil).Times(20)
+
+    for i := 0; i < 20; i++ {
+        q, err := svc.WeightedQuote(ctx, models.RandomWeight)
+        require.NoError(t, err)
+        require.Equal(t, 3, q.ID)
+    }
+
+    mockRepo.AssertExpectations(t)
+}
+
+func TestWeightedQuote_Strategies(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    mockRepo.On("RandQuote", ctx).Return(&models.Quote{ID: 1}, nil).Twice()
+    _, err := svc.WeightedQuote(ctx, "")
+    require.NoError(t, err)
+    _, err = svc.WeightedQuote(ctx, models.RandomUniform)
+    require.NoError(t, err)
+
+    _, err = svc.WeightedQuote(ctx, "loudest")
+    require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+
+    mockRepo.On("QuoteWeights", ctx).Return(&[]models.QuoteWeight{}, nil).Once()
+    _, err = svc.WeightedQuote(ctx, models.RandomRating)
+    require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+    mockRepo.AssertExpectations(t)
+    mockRepo.AssertNotCalled(t, "GetQuote", mock.Anything, mock.Anything)
+}
+
+func TestWeightedQuote_NoData(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    // на свежей базе без оценок и просмотров rating и views выбирают равномерно
+    mockRepo.On("QuoteWeights", ctx).
+        Return(&[]models.QuoteWeight{{ID: 1, Weight: 1}, {ID: 2, Weight: 1}}, nil).Once()
+    mockRepo.On("GetQuote", ctx, mock.Anything).Return(&models.Quote{ID: 1}, nil)
+
+    for _, strategy := range []string{models.RandomRating, models.RandomViews} {
+        q, err := svc.WeightedQuote(ctx, strategy)
+        require.NoError(t, err, strategy)
+        require.NotNil(t, q)
+    }
+    mockRepo.AssertExpectations(t)
+}
+
+func TestCreateQuote_InvalidWeight(t *testing.T) {
+    ctx := context.Background()
+    cfg := loadTestConfig(t)
+    mockRepo := new(MockQuoteRepository)
+    svc := NewQuoteService(cfg, mockRepo)
+
+    for _, w := range []float64{-1, maxWeight + 1} {
+        _, err := svc.CreateQuote(ctx, &models.Quote{Author: "A", Quote: "Q", Weight: &w})
+        require.ErrorIs(t, err, errdefs.ErrInvalidInput)
+    }
+
+    mockRepo.AssertNotCalled(t, "CreateQuote", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,61 @@
+// Package shuffle задаёт случайную перестановку чисел 0..size-1 одним seed:
+// i-й элемент считается за O(1) без хранения самой перестановки.
+// Основа — сеть Фейстеля на ближайшей чётной степени двойки; значения
+// за пределами size пропускаются (cycle walking), поэтому отображение
+// остаётся биекцией на [0, size).
+package shuffle
+
+import "math/bits"
+
+const rounds = 4
+
+// Permutation перестановка [0, Size)
+type Permutation struct {
+	Size uint64
+	Seed uint64
+
+	half uint
+	mask uint64
+}
+
+// New перестановка size элементов; одинаковый seed даёт одинаковый порядок
+func New(size, seed uint64) Permutation {
+	width := uint(bits.Len64(size - 1))
+	if size <= 1 {
+		width = 0
+	}
+	// обе половины одной ширины, поэтому ширина чётная
+	width += width & 1
+	if width < 2 {
+		width = 2
+	}
+	half := width / 2
+	return Permutation{Size: size, Seed: seed, half: half, mask: 1<<half - 1}
+}
+
+// At элемент перестановки на позиции i, i < Size
+func (p Permutation) At(i uint64) uint64 {
+	x := p.feistel(i)
+	for x >= p.Size {
+		x = p.feistel(x)
+	}
+	return x
+}
+
+func (p Permutation) feistel(x uint64) uint64 {
+	left, right := x>>p.half, x&p.mask
+	for r := uint64(0); r < rounds; r++ {
+		left, right = right, left^(mix(p.Seed^(r<<56)^right)&p.mask)
+	}
+	return left<<p.half | right
+}
+
+// mix финализатор splitmix64
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
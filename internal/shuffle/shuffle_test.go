@@ -0,0 +1,35 @@
+package shuffle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermutation_Bijection(t *testing.T) {
+	for _, size := range []uint64{1, 2, 3, 4, 5, 15, 16, 17, 100, 1000, 4097} {
+		for _, seed := range []uint64{0, 1, 42, 1 << 63} {
+			p := New(size, seed)
+			seen := make([]bool, size)
+			for i := uint64(0); i < size; i++ {
+				x := p.At(i)
+				require.Less(t, x, size, "size %d seed %d", size, seed)
+				require.False(t, seen[x], "size %d seed %d: %d repeated", size, seed, x)
+				seen[x] = true
+			}
+		}
+	}
+}
+
+func TestPermutation_Seed(t *testing.T) {
+	a, b := New(1000, 1), New(1000, 1)
+	c := New(1000, 2)
+
+	same, differs := true, false
+	for i := uint64(0); i < 1000; i++ {
+		same = same && a.At(i) == b.At(i)
+		differs = differs || a.At(i) != c.At(i)
+	}
+	require.True(t, same, "same seed must give the same order")
+	require.True(t, differs, "another seed must give another order")
+}
@@ -0,0 +1,166 @@
+// Package similar ищет близкие по содержанию тексты: документ — мешок термов
+// с весами TF-IDF, близость — косинус угла между векторами. Индекс меняется
+// по одному документу; idf считается в момент запроса, поэтому добавление
+// и удаление не требуют пересчёта остальных векторов.
+package similar
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// короче — предлоги, союзы и связки вроде "is", "to", "не", которые только шумят
+const minTermLen = 3
+
+// Match документ и его близость к запрошенному, от 0 до 1
+type Match struct {
+	ID    int
+	Score float64
+}
+
+// Index обратный индекс термов; безопасен для конкурентного использования
+type Index struct {
+	mu sync.RWMutex
+	// tf термов документа
+	docs map[int]map[string]float64
+	// в каких документах встречается терм, с его tf
+	postings map[string]map[int]float64
+}
+
+func New() *Index {
+	return &Index{
+		docs:     make(map[int]map[string]float64),
+		postings: make(map[string]map[int]float64),
+	}
+}
+
+// Terms слова текста в нижнем регистре, без знаков препинания
+func Terms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := words[:0]
+	for _, w := range words {
+		if utf8.RuneCountInString(w) >= minTermLen {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// Put добавляет документ id или заменяет прежний
+func (ix *Index) Put(id int, terms []string) {
+	counts := make(map[string]float64)
+	for _, t := range terms {
+		counts[t]++
+	}
+	// логарифм, чтобы повтор слова не перевешивал весь текст
+	for t, n := range counts {
+		counts[t] = 1 + math.Log(n)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+	ix.docs[id] = counts
+	for t, tf := range counts {
+		p, ok := ix.postings[t]
+		if !ok {
+			p = make(map[int]float64)
+			ix.postings[t] = p
+		}
+		p[id] = tf
+	}
+}
+
+// Remove убирает документ id, если он есть
+func (ix *Index) Remove(id int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+}
+
+func (ix *Index) remove(id int) {
+	for t := range ix.docs[id] {
+		p := ix.postings[t]
+		delete(p, id)
+		if len(p) == 0 {
+			delete(ix.postings, t)
+		}
+	}
+	delete(ix.docs, id)
+}
+
+// Has есть ли документ id в индексе
+func (ix *Index) Has(id int) bool {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	_, ok := ix.docs[id]
+	return ok
+}
+
+// idf редкость терма: 0 у терма, который есть во всех документах
+func (ix *Index) idf(t string) float64 {
+	return math.Log(float64(1+len(ix.docs)) / float64(1+len(ix.postings[t])))
+}
+
+func (ix *Index) norm(doc map[string]float64) float64 {
+	sum := 0.0
+	for t, tf := range doc {
+		w := tf * ix.idf(t)
+		sum += w * w
+	}
+	return math.Sqrt(sum)
+}
+
+// Similar до limit документов, самых близких к id, с близостью не меньше
+// minScore; сам id в ответ не входит. nil, если id нет в индексе.
+func (ix *Index) Similar(id int, limit int, minScore float64) []Match {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	doc, ok := ix.docs[id]
+	if !ok {
+		return nil
+	}
+	norm := ix.norm(doc)
+	if norm == 0 {
+		return []Match{}
+	}
+
+	// скалярные произведения только с документами, у которых есть общие термы
+	dots := make(map[int]float64)
+	for t, tf := range doc {
+		idf := ix.idf(t)
+		if idf == 0 {
+			continue
+		}
+		for other, otherTF := range ix.postings[t] {
+			if other != id {
+				dots[other] += tf * otherTF * idf * idf
+			}
+		}
+	}
+
+	matches := make([]Match, 0, len(dots))
+	for other, dot := range dots {
+		score := dot / (norm * ix.norm(ix.docs[other]))
+		if score >= minScore {
+			matches = append(matches, Match{ID: other, Score: score})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// parseAuditFilter читает фильтр из query: principal, route, target_id,
+// outcome, from, to (RFC3339), after_id, limit
+func parseAuditFilter(q url.Values) (*models.AuditFilter, error) {
+	f := &models.AuditFilter{
+		Principal: q.Get("principal"),
+		Route:     q.Get("route"),
+		TargetID:  q.Get("target_id"),
+		Outcome:   q.Get("outcome"),
+	}
+
+	for name, dst := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "%s must be RFC3339", name)
+			}
+			*dst = &t
+		}
+	}
+	if v := q.Get("after_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "after_id must be an integer")
+		}
+		f.AfterID = id
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errdefs.Wrap(errdefs.ErrInvalidInput, "limit must be an integer")
+		}
+		f.Limit = limit
+	}
+	return f, nil
+}
+
+// HandleGetAudit обрабатывает GET /admin/audit
+func (h *Handler) HandleGetAudit() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		filter, err := parseAuditFilter(r.URL.Query())
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		entries, err := h.audit.Entries(ctx, filter)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "listed audit entries",
+			zap.Int("returned", len(*entries)),
+		)
+		encode(w, r, http.StatusOK, entries)
+	})
+}
+
+// HandleExportAudit обрабатывает GET /admin/audit/export, отдаёт NDJSON потоком
+func (h *Handler) HandleExportAudit() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		filter, err := parseAuditFilter(r.URL.Query())
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		// выгрузка целиком, limit игнорируется
+		filter.Limit = 0
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		exported := 0
+		err = h.audit.Export(ctx, filter, func(e *models.AuditEntry) error {
+			exported++
+			return enc.Encode(e)
+		})
+		if err != nil {
+			// если заголовки уже ушли, сменить статус нельзя
+			if exported == 0 {
+				handleServiceError(ctx, w, err)
+				return
+			}
+			h.logger.Error(ctx, "audit export interrupted", zap.Error(err))
+			return
+		}
+
+		h.logger.Info(ctx, "exported audit entries",
+			zap.Int("exported", exported),
+		)
+	})
+}
+
+// HandleVerifyAudit обрабатывает GET /admin/audit/verify
+func (h *Handler) HandleVerifyAudit() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		res, err := h.audit.Verify(ctx)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "audit chain verified",
+			zap.Int("checked", res.Checked),
+			zap.Bool("valid", res.Valid),
+		)
+		encode(w, r, http.StatusOK, res)
+	})
+}
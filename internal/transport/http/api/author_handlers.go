@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// setDidYouMean к пустому ответу по автору добавляет заголовок X-Did-You-Mean:
+// похожие имена через запятую, каждое percent-encoded ("Lao%20Tzu, Confucius")
+func (h *Handler) setDidYouMean(ctx context.Context, w http.ResponseWriter, author string) error {
+	names, err := h.qbs.DidYouMean(ctx, author)
+	if err != nil || len(names) == 0 {
+		return err
+	}
+	for i, name := range names {
+		names[i] = url.PathEscape(name)
+	}
+	w.Header().Set("X-Did-You-Mean", strings.Join(names, ", "))
+	return nil
+}
+
+// HandleSuggestAuthors обрабатывает GET /authors/suggest?prefix=ein&limit=10
+func (h *Handler) HandleSuggestAuthors() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		limit := 0
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				handleServiceError(ctx, w, errdefs.Wrap(errdefs.ErrInvalidInput, "limit must be a positive integer"))
+				return
+			}
+			limit = n
+		}
+
+		prefix := r.URL.Query().Get("prefix")
+		authors, err := h.qbs.SuggestAuthors(ctx, prefix, limit)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "suggested authors",
+			zap.String("prefix", prefix),
+			zap.Int("returned", len(*authors)),
+		)
+		encode(w, r, http.StatusOK, authors)
+	})
+}
+
+// HandleGetAuthor обрабатывает GET /authors/{name}
+func (h *Handler) HandleGetAuthor() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		author, err := h.qbs.Author(ctx, mux.Vars(r)["name"])
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "return author",
+			zap.String("name", author.Name),
+		)
+		encode(w, r, http.StatusOK, author)
+	})
+}
+
+// HandlePutAuthor обрабатывает PUT /admin/authors/{name}, тело {"born": "1907-01-01", "died": ""}
+func (h *Handler) HandlePutAuthor() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		payload, err := decode[models.Author](r)
+		if err != nil {
+			handleServiceError(ctx, w, errdefs.Wrapf(errdefs.ErrInvalidInput, "invalid JSON payload: %v", err))
+			return
+		}
+		author, err := h.qbs.SetAuthorDates(ctx, mux.Vars(r)["name"], &payload)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "author dates set",
+			zap.String("name", author.Name),
+			zap.String("born", author.Born),
+			zap.String("died", author.Died),
+		)
+		encode(w, r, http.StatusOK, author)
+	})
+}
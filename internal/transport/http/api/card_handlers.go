@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"quotebook/internal/models"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// HandleGetQuoteCard обрабатывает GET /quotes/{id}/card.png и card.svg,
+// параметры: size, theme, font
+func (h *Handler) HandleGetQuoteCard() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		q := r.URL.Query()
+		opts := &models.CardOptions{
+			Format: mux.Vars(r)["format"],
+			Size:   q.Get("size"),
+			Theme:  q.Get("theme"),
+			Font:   q.Get("font"),
+		}
+
+		card, err := h.cards.QuoteCard(ctx, id, opts)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		if notModified(w, r, card.ETag, card.LastModified) {
+			return
+		}
+
+		h.logger.Info(ctx, "quote card rendered",
+			zap.Int("id", id),
+			zap.String("format", opts.Format),
+			zap.Int("bytes", len(card.Body)),
+		)
+		w.Header().Set("Content-Type", card.ContentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(card.Body)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(card.Body)
+	})
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// HandleGetCitation обрабатывает GET /quotes/{id}/citation?style=apa|mla|chicago
+func (h *Handler) HandleGetCitation() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		c, err := h.qbs.Citation(ctx, id, r.URL.Query().Get("style"))
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "citation",
+			zap.Int("id", id),
+			zap.String("style", c.Style),
+		)
+		encode(w, r, http.StatusOK, c)
+	})
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// addToCollectionRequest тело POST /collections/{id}/quotes; position с 1, 0 — в конец
+type addToCollectionRequest struct {
+	QuoteID  int `json:"quote_id"`
+	Position int `json:"position"`
+}
+
+// reorderRequest тело PUT /collections/{id}/order
+type reorderRequest struct {
+	QuoteIDs []int `json:"quote_ids"`
+}
+
+// HandlePostCollection обрабатывает POST /collections
+func (h *Handler) HandlePostCollection() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		payload, err := decode[models.Collection](r)
+		if err != nil {
+			handleServiceError(ctx, w, errdefs.Wrapf(errdefs.ErrInvalidInput, "invalid JSON payload: %v", err))
+			return
+		}
+		c, err := h.collections.CreateCollection(ctx, &payload)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "collection created",
+			zap.Int("id", c.ID),
+		)
+		w.Header().Set("Location", "/collections/"+strconv.Itoa(c.ID))
+		encode(w, r, http.StatusCreated, c)
+	})
+}
+
+// HandleGetCollections обрабатывает GET /collections?owner=alice
+func (h *Handler) HandleGetCollections() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		collections, err := h.collections.Collections(ctx, r.URL.Query().Get("owner"))
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "listed collections",
+			zap.Int("returned", len(*collections)),
+		)
+		// в список входят приватные подборки самого X-User
+		w.Header().Add("Vary", "X-User")
+		encode(w, r, http.StatusOK, collections)
+	})
+}
+
+// HandleGetCollection обрабатывает GET /collections/{id}
+func (h *Handler) HandleGetCollection() http.Handler {
+	return h.handleCollection("collection", func(r *http.Request, id int) (*models.Collection, error) {
+		return h.collections.GetCollection(r.Context(), id)
+	})
+}
+
+// HandlePatchCollection обрабатывает PATCH /collections/{id}
+func (h *Handler) HandlePatchCollection() http.Handler {
+	return h.handleCollection("collection updated", func(r *http.Request, id int) (*models.Collection, error) {
+		payload, err := decode[models.CollectionPatch](r)
+		if err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "invalid JSON payload: %v", err)
+		}
+		return h.collections.UpdateCollection(r.Context(), id, &payload)
+	})
+}
+
+// HandleAddToCollection обрабатывает POST /collections/{id}/quotes, тело {"quote_id": 1, "position": 2}
+func (h *Handler) HandleAddToCollection() http.Handler {
+	return h.handleCollection("quote added to collection", func(r *http.Request, id int) (*models.Collection, error) {
+		payload, err := decode[addToCollectionRequest](r)
+		if err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "invalid JSON payload: %v", err)
+		}
+		return h.collections.AddQuote(r.Context(), id, payload.QuoteID, payload.Position)
+	})
+}
+
+// HandleRemoveFromCollection обрабатывает DELETE /collections/{id}/quotes/{quote_id}
+func (h *Handler) HandleRemoveFromCollection() http.Handler {
+	return h.handleCollection("quote removed from collection", func(r *http.Request, id int) (*models.Collection, error) {
+		quoteID, err := pathInt(r, "quote_id")
+		if err != nil {
+			return nil, err
+		}
+		return h.collections.RemoveQuote(r.Context(), id, quoteID)
+	})
+}
+
+// HandleReorderCollection обрабатывает PUT /collections/{id}/order, тело {"quote_ids": [3, 1, 2]}
+func (h *Handler) HandleReorderCollection() http.Handler {
+	return h.handleCollection("collection reordered", func(r *http.Request, id int) (*models.Collection, error) {
+		payload, err := decode[reorderRequest](r)
+		if err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "invalid JSON payload: %v", err)
+		}
+		return h.collections.ReorderCollection(r.Context(), id, payload.QuoteIDs)
+	})
+}
+
+// handleCollection общий обработчик ручек, отвечающих подборкой целиком
+func (h *Handler) handleCollection(action string, fn func(r *http.Request, id int) (*models.Collection, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		c, err := fn(r.WithContext(ctx), id)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, action,
+			zap.Int("id", id),
+			zap.Int("size", c.Size),
+		)
+		w.Header().Add("Vary", "X-User")
+		encode(w, r, http.StatusOK, c)
+	})
+}
+
+// HandleDeleteCollection обрабатывает DELETE /collections/{id}
+func (h *Handler) HandleDeleteCollection() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		if err := h.collections.DeleteCollection(ctx, id); err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "collection deleted",
+			zap.Int("id", id),
+		)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// HandleGetCollectionRandom обрабатывает GET /collections/{id}/random
+func (h *Handler) HandleGetCollectionRandom() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		quote, err := h.collections.RandomQuote(ctx, id)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "return random collection quote",
+			zap.Int("collection", id),
+			zap.Int("id", quote.ID),
+		)
+		encode(w, r, http.StatusOK, quote)
+	})
+}
+
+// HandleExportCollection обрабатывает GET /collections/{id}/export?format=...,
+// форматы те же, что у /quotes/export
+func (h *Handler) HandleExportCollection() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		h.writeExport(ctx, w, r, "collection-"+strconv.Itoa(id), func(fn func(q *models.Quote) error) error {
+			return h.collections.ExportCollection(ctx, id, fn)
+		})
+	})
+}
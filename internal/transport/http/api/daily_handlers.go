@@ -0,0 +1,149 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// pinRequest тело PUT /admin/daily/{date}
+type pinRequest struct {
+	QuoteID int `json:"quote_id"`
+}
+
+// HandleGetDailyQuote обрабатывает GET /quotes/daily?tz=Europe/Moscow&mode=rotation|on-this-day,
+// текст — на языке из Accept-Language
+func (h *Handler) HandleGetDailyQuote() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		prefs := langPrefs(w, r)
+		dq, err := h.daily.DailyQuote(ctx, r.URL.Query().Get("tz"), r.URL.Query().Get("mode"))
+		if err == nil {
+			err = h.localizeQuote(ctx, &dq.Quote, prefs)
+		}
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		// Last-Modified не отдаётся: завтрашняя цитата может быть старше сегодняшней
+		etag := fmt.Sprintf(`"%s-%s"`, dq.Date, strings.Trim(quoteETag(&dq.Quote), `"`))
+		if notModified(w, r, etag, time.Time{}) {
+			return
+		}
+
+		h.logger.Info(ctx, "daily quote",
+			zap.String("date", dq.Date),
+			zap.Int("id", dq.Quote.ID),
+		)
+		encode(w, r, http.StatusOK, dq)
+	})
+}
+
+// HandleGetOnThisDay обрабатывает GET /quotes/on-this-day?tz=Europe/Moscow
+func (h *Handler) HandleGetOnThisDay() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		prefs := langPrefs(w, r)
+		otd, err := h.daily.OnThisDay(ctx, r.URL.Query().Get("tz"))
+		if err == nil {
+			quotes := make([]*models.Quote, len(otd.Quotes))
+			for i := range otd.Quotes {
+				quotes[i] = &otd.Quotes[i].Quote
+			}
+			err = h.localizeQuotes(ctx, quotes, prefs)
+		}
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "on this day",
+			zap.String("date", otd.Date),
+			zap.Int("returned", len(otd.Quotes)),
+		)
+		encode(w, r, http.StatusOK, otd)
+	})
+}
+
+// HandleGetDailyHistory обрабатывает GET /quotes/daily/history?tz=...&mode=...&limit=...
+func (h *Handler) HandleGetDailyHistory() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		limit := 0
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				handleServiceError(ctx, w, errdefs.Wrap(errdefs.ErrInvalidInput, "limit must be a positive integer"))
+				return
+			}
+			limit = n
+		}
+
+		history, err := h.daily.DailyHistory(ctx, r.URL.Query().Get("tz"), r.URL.Query().Get("mode"), limit)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "daily history",
+			zap.Int("returned", len(*history)),
+		)
+		encode(w, r, http.StatusOK, history)
+	})
+}
+
+// HandlePinDailyQuote обрабатывает PUT /admin/daily/{date}, тело {"quote_id": 1}
+func (h *Handler) HandlePinDailyQuote() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		payload, err := decode[pinRequest](r)
+		if err != nil {
+			handleServiceError(ctx, w, errdefs.Wrapf(errdefs.ErrInvalidInput, "invalid JSON payload: %v", err))
+			return
+		}
+		date := mux.Vars(r)["date"]
+		dq, err := h.daily.PinDailyQuote(ctx, date, payload.QuoteID)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "daily quote pinned",
+			zap.String("date", date),
+			zap.Int("id", payload.QuoteID),
+		)
+		encode(w, r, http.StatusOK, dq)
+	})
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// HandlePostDispute обрабатывает POST /quotes/{id}/disputes,
+// тело {"proposed": "misattributed", "reason": "...", "evidence": "..."}
+func (h *Handler) HandlePostDispute() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		payload, err := decode[models.Dispute](r)
+		if err != nil {
+			handleServiceError(ctx, w, errdefs.Wrapf(errdefs.ErrInvalidInput, "invalid JSON payload: %v", err))
+			return
+		}
+		d, err := h.disputes.FileDispute(ctx, id, &payload)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "dispute filed",
+			zap.Int("id", d.ID),
+			zap.Int("quote", id),
+			zap.String("proposed", d.Proposed),
+		)
+		encode(w, r, http.StatusCreated, d)
+	})
+}
+
+// HandleGetQuoteDisputes обрабатывает GET /quotes/{id}/disputes
+func (h *Handler) HandleGetQuoteDisputes() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		disputes, err := h.disputes.QuoteDisputes(ctx, id)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "listed quote disputes",
+			zap.Int("quote", id),
+			zap.Int("returned", len(*disputes)),
+		)
+		encode(w, r, http.StatusOK, disputes)
+	})
+}
+
+// HandleGetDisputes обрабатывает GET /admin/disputes?status=open&limit=50
+func (h *Handler) HandleGetDisputes() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		limit := 0
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				handleServiceError(ctx, w, errdefs.Wrap(errdefs.ErrInvalidInput, "limit must be a positive integer"))
+				return
+			}
+			limit = n
+		}
+		disputes, err := h.disputes.Disputes(ctx, r.URL.Query().Get("status"), limit)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "listed disputes",
+			zap.Int("returned", len(*disputes)),
+		)
+		encode(w, r, http.StatusOK, disputes)
+	})
+}
+
+// HandleResolveDispute обрабатывает POST /admin/disputes/{id}/resolve,
+// тело {"decision": "accept", "attribution": "misattributed", "note": "..."}
+func (h *Handler) HandleResolveDispute() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		payload, err := decode[models.DisputeReview](r)
+		if err != nil {
+			handleServiceError(ctx, w, errdefs.Wrapf(errdefs.ErrInvalidInput, "invalid JSON payload: %v", err))
+			return
+		}
+		d, err := h.disputes.ResolveDispute(ctx, id, &payload)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "dispute resolved",
+			zap.Int("id", id),
+			zap.String("status", d.Status),
+		)
+		encode(w, r, http.StatusOK, d)
+	})
+}
+
+// HandleSetAttribution обрабатывает PUT /admin/quotes/{id}/attribution,
+// тело {"attribution": "verified", "note": "..."}
+func (h *Handler) HandleSetAttribution() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		payload, err := decode[models.AttributionUpdate](r)
+		if err != nil {
+			handleServiceError(ctx, w, errdefs.Wrapf(errdefs.ErrInvalidInput, "invalid JSON payload: %v", err))
+			return
+		}
+		quote, err := h.disputes.SetAttribution(ctx, id, &payload)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "attribution set",
+			zap.Int("id", id),
+			zap.String("attribution", quote.Attribution),
+		)
+		w.Header().Set("ETag", quoteETag(quote))
+		encode(w, r, http.StatusOK, quote)
+	})
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"net/http"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// rateRequest тело PUT /quotes/{id}/rating
+type rateRequest struct {
+	Stars int `json:"stars"`
+}
+
+// handleEngagement общий обработчик /like и /rating: id из пути, ответ — счётчики цитаты
+func (h *Handler) handleEngagement(action string, fn func(r *http.Request, id int) (*models.Engagement, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		e, err := fn(r.WithContext(ctx), id)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "quote "+action,
+			zap.Int("id", id),
+			zap.Int("favorites", e.Favorites),
+			zap.Int("rating_count", e.RatingCount),
+		)
+		encode(w, r, http.StatusOK, e)
+	})
+}
+
+// HandleLikeQuote обрабатывает POST /quotes/{id}/like
+func (h *Handler) HandleLikeQuote() http.Handler {
+	return h.handleEngagement("liked", func(r *http.Request, id int) (*models.Engagement, error) {
+		return h.engagement.Favorite(r.Context(), id)
+	})
+}
+
+// HandleUnlikeQuote обрабатывает DELETE /quotes/{id}/like
+func (h *Handler) HandleUnlikeQuote() http.Handler {
+	return h.handleEngagement("unliked", func(r *http.Request, id int) (*models.Engagement, error) {
+		return h.engagement.Unfavorite(r.Context(), id)
+	})
+}
+
+// HandleRateQuote обрабатывает PUT /quotes/{id}/rating, тело {"stars": 5}
+func (h *Handler) HandleRateQuote() http.Handler {
+	return h.handleEngagement("rated", func(r *http.Request, id int) (*models.Engagement, error) {
+		payload, err := decode[rateRequest](r)
+		if err != nil {
+			return nil, errdefs.Wrapf(errdefs.ErrInvalidInput, "invalid JSON payload: %v", err)
+		}
+		return h.engagement.Rate(r.Context(), id, payload.Stars)
+	})
+}
+
+// HandleUnrateQuote обрабатывает DELETE /quotes/{id}/rating
+func (h *Handler) HandleUnrateQuote() http.Handler {
+	return h.handleEngagement("unrated", func(r *http.Request, id int) (*models.Engagement, error) {
+		return h.engagement.Unrate(r.Context(), id)
+	})
+}
+
+// HandleGetFavorites обрабатывает GET /me/favorites
+func (h *Handler) HandleGetFavorites() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		quotes, err := h.engagement.Favorites(ctx)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "listed favorites",
+			zap.Int("returned", len(*quotes)),
+		)
+		// избранное своё у каждого X-User
+		w.Header().Add("Vary", "X-User")
+		encode(w, r, http.StatusOK, quotes)
+	})
+}
+
+// HandleGetSortedQuotes обрабатывает GET /quotes?sort=popular|rating|recent
+func (h *Handler) HandleGetSortedQuotes() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		stamp, err := h.qbs.QuotesStamp(ctx)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		if notModified(w, r, listETag(stamp), stamp.LastModified) {
+			return
+		}
+
+		sort := r.URL.Query().Get("sort")
+		quotes, err := h.qbs.SortedQuotes(ctx, sort)
+		if err == nil {
+			quotes, err = withoutMisattributed(r, quotes)
+		}
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "listed sorted quotes",
+			zap.String("sort", sort),
+			zap.Int("returned", len(*quotes)),
+		)
+		encode(w, r, http.StatusOK, quotes)
+	})
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+)
+
+// quoteETag сильный ETag одной цитаты: id и версия.
+// id нужен, чтобы у /quotes/random разные цитаты не совпадали по ETag.
+// Счётчики избранного и оценок версию не меняют, поэтому, если они есть,
+// добавляются после точки; If-Match смотрит только на id и версию.
+// У перевода в конце ещё язык: тексты на разных языках — разные представления.
+func quoteETag(q *models.Quote) string {
+	if q.Favorites == 0 && q.RatingCount == 0 && q.OriginalLang == "" {
+		return fmt.Sprintf(`"%d-%d"`, q.ID, q.Version)
+	}
+	etag := fmt.Sprintf(`%d-%d.%d.%d.%d`, q.ID, q.Version,
+		q.Favorites, q.RatingCount, int(math.Round(q.RatingAvg*100)))
+	if q.OriginalLang != "" {
+		etag += "." + q.Lang
+	}
+	return `"` + etag + `"`
+}
+
+// ifMatchVersion возвращает версию цитаты id из If-Match; 0 — проверять не нужно.
+// Без заголовка в строгом режиме запрос отклоняется с 428.
+func (h *Handler) ifMatchVersion(r *http.Request, id int) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		if h.cfg.Concurrency.RequireIfMatch {
+			return 0, errdefs.Wrap(errdefs.ErrPreconditionRequired, "If-Match header required")
+		}
+		return 0, nil
+	}
+	if header == "*" {
+		return 0, nil
+	}
+
+	tags := strings.Split(header, ",")
+	if len(tags) > 1 {
+		return 0, errdefs.Wrap(errdefs.ErrInvalidInput, "If-Match must contain a single ETag")
+	}
+	tag := strings.TrimSpace(tags[0])
+	// слабые ETag в If-Match не совпадают никогда (RFC 9110, 13.1.1)
+	if strings.HasPrefix(tag, "W/") {
+		return 0, errdefs.Wrap(errdefs.ErrPreconditionFailed, "weak ETag in If-Match")
+	}
+	tagID, version, ok := strings.Cut(strings.Trim(tag, `"`), "-")
+	if !ok || tagID != strconv.Itoa(id) {
+		return 0, errdefs.Wrapf(errdefs.ErrPreconditionFailed, "ETag %s does not belong to quote %d", tag, id)
+	}
+	version, _, _ = strings.Cut(version, ".")
+	v, err := strconv.Atoi(version)
+	if err != nil || v <= 0 {
+		return 0, errdefs.Wrapf(errdefs.ErrPreconditionFailed, "unknown ETag %s", tag)
+	}
+	return v, nil
+}
+
+// listETag слабый ETag выборки: меняется при любом изменении таблицы
+func listETag(stamp *models.QuotesStamp) string {
+	return fmt.Sprintf(`W/"%d-%x"`, stamp.Count, stamp.LastModified.UnixMicro())
+}
+
+// batchETag ETag выборки ?ids=: к listETag добавляется список id без повторов
+// в порядке запроса, иначе ?ids=1,2 и ?ids=3 делили бы один валидатор
+func batchETag(stamp *models.QuotesStamp, ids []int) string {
+	seen := make(map[int]bool, len(ids))
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			parts = append(parts, strconv.Itoa(id))
+		}
+	}
+	return strings.TrimSuffix(listETag(stamp), `"`) + ":" + strings.Join(parts, ",") + `"`
+}
+
+// etagMatches слабое сравнение для If-None-Match: W/"1" и "1" совпадают
+func etagMatches(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, tag := range splitETags(header) {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
+
+// splitETags делит список ETag по запятым вне кавычек: внутри тега запятая
+// допустима (языки в localizedListETag, id в batchETag)
+func splitETags(header string) []string {
+	var tags []string
+	quoted, start := false, 0
+	for i := 0; i < len(header); i++ {
+		switch header[i] {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				tags = append(tags, header[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(tags, header[start:])
+}
+
+// notModified выставляет валидаторы и, если клиентская копия актуальна,
+// отвечает 304. If-None-Match важнее If-Modified-Since (RFC 9110, 13.2.2).
+func notModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	fresh := false
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		fresh = etagMatches(inm, etag)
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		// Last-Modified передаётся с точностью до секунды
+		fresh = err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+
+	if fresh {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return fresh
+}
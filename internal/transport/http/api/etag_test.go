@@ -0,0 +1,140 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+)
+
+func TestGetQuote_NotModified(t *testing.T) {
+	ts := newTestServer(t)
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	ts.quotes.On("GetQuote", mock.Anything, 1).
+		Return(&models.Quote{ID: 1, Author: "A", Quote: "Q", Version: 2, UpdatedAt: updated}, nil)
+
+	w := ts.do(http.MethodGet, "/quotes/1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.Equal(t, `"1-2"`, etag)
+	lastModified := w.Header().Get("Last-Modified")
+
+	for _, headers := range [][]string{
+		{"If-None-Match", etag},
+		{"If-None-Match", `"0-1", W/` + etag},
+		{"If-Modified-Since", lastModified},
+	} {
+		w = ts.do(http.MethodGet, "/quotes/1", "", headers...)
+		require.Equal(t, http.StatusNotModified, w.Code, headers)
+		require.Empty(t, w.Body.String())
+	}
+
+	// If-None-Match важнее If-Modified-Since
+	w = ts.do(http.MethodGet, "/quotes/1", "", "If-None-Match", `"1-1"`, "If-Modified-Since", lastModified)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// 304 просмотром не считается
+	require.Equal(t, []int{1, 1}, ts.views.viewed)
+}
+
+func TestGetRandQuote_NoStore(t *testing.T) {
+	ts := newTestServer(t)
+	ts.quotes.On("WeightedQuote", mock.Anything, "").
+		Return(&models.Quote{ID: 1, Author: "A", Quote: "Q", Version: 2}, nil)
+
+	// ETag прошлой случайной цитаты не даёт 304: клиенту нужна новая
+	w := ts.do(http.MethodGet, "/quotes/random", "", "If-None-Match", `"1-2"`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	require.Equal(t, `"1-2"`, w.Header().Get("ETag"))
+	require.Contains(t, w.Body.String(), `"id":1`)
+	require.Equal(t, []int{1}, ts.views.served)
+}
+
+func TestGetQuotes_NotModified(t *testing.T) {
+	ts := newTestServer(t)
+	stamp := &models.QuotesStamp{Count: 2, LastModified: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	ts.quotes.On("QuotesStamp", mock.Anything).Return(stamp, nil)
+	ts.quotes.On("FilterQuotes", mock.Anything, mock.Anything).
+		Return(&[]models.Quote{{ID: 1}, {ID: 2}}, nil).Once()
+
+	w := ts.do(http.MethodGet, "/quotes", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.Equal(t, listETag(stamp), etag)
+
+	// выборка не читается, если список не менялся
+	w = ts.do(http.MethodGet, "/quotes", "", "If-None-Match", etag)
+	require.Equal(t, http.StatusNotModified, w.Code)
+	ts.quotes.AssertExpectations(t)
+}
+
+func TestGetQuotesByIDs_NotModified(t *testing.T) {
+	ts := newTestServer(t)
+	stamp := &models.QuotesStamp{Count: 3, LastModified: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	ts.quotes.On("QuotesStamp", mock.Anything).Return(stamp, nil)
+	ts.quotes.On("QuotesByIDs", mock.Anything, []int{1, 2}).
+		Return(&models.QuoteBatch{Quotes: []models.Quote{{ID: 1}, {ID: 2}}, Missing: []int{}}, nil).Once()
+	ts.quotes.On("QuotesByIDs", mock.Anything, []int{3}).
+		Return(&models.QuoteBatch{Quotes: []models.Quote{{ID: 3}}, Missing: []int{}}, nil).Once()
+
+	w := ts.do(http.MethodGet, "/quotes?ids=1,2", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.Equal(t, strings.TrimSuffix(listETag(stamp), `"`)+`:1,2"`, etag)
+
+	// тот же набор, записанный иначе, — тот же валидатор
+	w = ts.do(http.MethodGet, "/quotes?ids=1,%202,1", "", "If-None-Match", `"0-1", `+etag)
+	require.Equal(t, http.StatusNotModified, w.Code)
+
+	// ETag другого набора id не подходит
+	w = ts.do(http.MethodGet, "/quotes?ids=3", "", "If-None-Match", etag)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEqual(t, etag, w.Header().Get("ETag"))
+	ts.quotes.AssertExpectations(t)
+}
+
+func TestPutQuote_IfMatch(t *testing.T) {
+	ts := newTestServer(t)
+	body := `{"author":"A","quote":"Q"}`
+
+	// чужой, слабый или несколько ETag отклоняются до обращения к сервису
+	for _, tag := range []string{`"2-1"`, `W/"1-1"`, `"1-x"`} {
+		w := ts.do(http.MethodPut, "/quotes/1", body, "If-Match", tag)
+		require.Equal(t, http.StatusPreconditionFailed, w.Code, tag)
+	}
+	w := ts.do(http.MethodPut, "/quotes/1", body, "If-Match", `"1-1", "1-2"`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	ts.quotes.AssertNotCalled(t, "UpdateQuote", mock.Anything, mock.Anything)
+
+	// версия из If-Match уходит в сервис; устаревшая — 412
+	ts.quotes.On("UpdateQuote", mock.Anything, mock.MatchedBy(func(q *models.Quote) bool { return q.Version == 1 })).
+		Return(errdefs.ErrPreconditionFailed).Once()
+	w = ts.do(http.MethodPut, "/quotes/1", body, "If-Match", `"1-1"`)
+	require.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	ts.quotes.On("UpdateQuote", mock.Anything, mock.MatchedBy(func(q *models.Quote) bool { return q.Version == 3 })).
+		Run(func(args mock.Arguments) { args.Get(1).(*models.Quote).Version = 4 }).
+		Return(nil).Once()
+	// у ETag с избранным и рейтингом версия — до первой точки
+	w = ts.do(http.MethodPut, "/quotes/1", body, "If-Match", `"1-3.5.2.450"`)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, `"1-4"`, w.Header().Get("ETag"))
+
+	ts.quotes.AssertExpectations(t)
+}
+
+func TestPutQuote_IfMatchRequired(t *testing.T) {
+	ts := newTestServer(t)
+	ts.cfg.Concurrency.RequireIfMatch = true
+
+	w := ts.do(http.MethodPut, "/quotes/1", `{"author":"A","quote":"Q"}`)
+	require.Equal(t, http.StatusPreconditionRequired, w.Code)
+	ts.quotes.AssertNotCalled(t, "UpdateQuote", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,302 @@
+package api
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+)
+
+// quoteEncoder пишет выгрузку по одной цитате, не накапливая её в памяти
+type quoteEncoder interface {
+	begin() error
+	encode(q *models.Quote) error
+	end() error
+}
+
+type exportFormat struct {
+	contentType string
+	newEncoder  func(w io.Writer) quoteEncoder
+}
+
+// exportFormats форматы GET /quotes/export, ключ — значение ?format= и расширение файла
+var exportFormats = map[string]exportFormat{
+	"json":     {"application/json", func(w io.Writer) quoteEncoder { return &jsonArrayEncoder{w: w} }},
+	"ndjson":   {"application/x-ndjson", func(w io.Writer) quoteEncoder { return &ndjsonEncoder{enc: json.NewEncoder(w)} }},
+	"csv":      {"text/csv; charset=utf-8", func(w io.Writer) quoteEncoder { return &csvEncoder{w: csv.NewWriter(w)} }},
+	"yaml":     {"application/yaml", func(w io.Writer) quoteEncoder { return &yamlEncoder{w: w} }},
+	"markdown": {"text/markdown; charset=utf-8", func(w io.Writer) quoteEncoder { return &markdownEncoder{w: w} }},
+}
+
+// jsonArrayEncoder один JSON-массив, элементы по строке
+type jsonArrayEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonArrayEncoder) begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonArrayEncoder) encode(q *models.Quote) error {
+	b, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	sep := "\n"
+	if e.count > 0 {
+		sep = ",\n"
+	}
+	e.count++
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *jsonArrayEncoder) end() error {
+	_, err := io.WriteString(e.w, "\n]\n")
+	return err
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) begin() error                 { return nil }
+func (e *ndjsonEncoder) encode(q *models.Quote) error { return e.enc.Encode(q) }
+func (e *ndjsonEncoder) end() error                   { return nil }
+
+// csvEncoder с заголовком; такой файл принимает POST /quotes/import
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func csvHeader() []string {
+	return []string{"id", "author", "quote", "tags", "created_at", "updated_at", "version"}
+}
+
+func csvRow(q *models.Quote) []string {
+	return []string{
+		strconv.Itoa(q.ID),
+		q.Author,
+		q.Quote,
+		strings.Join(q.Tags, ";"),
+		q.CreatedAt.UTC().Format(time.RFC3339),
+		q.UpdatedAt.UTC().Format(time.RFC3339),
+		strconv.Itoa(q.Version),
+	}
+}
+
+func (e *csvEncoder) begin() error {
+	return e.w.Write(csvHeader())
+}
+
+func (e *csvEncoder) encode(q *models.Quote) error {
+	return e.w.Write(csvRow(q))
+}
+
+func (e *csvEncoder) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// yamlEncoder последовательность документов-элементов одного списка
+type yamlEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *yamlEncoder) begin() error { return nil }
+
+func (e *yamlEncoder) encode(q *models.Quote) error {
+	b, err := yaml.Marshal([]yaml.MapSlice{{
+		{Key: "id", Value: q.ID},
+		{Key: "author", Value: q.Author},
+		{Key: "quote", Value: q.Quote},
+		{Key: "tags", Value: tagList(q.Tags)},
+		{Key: "created_at", Value: q.CreatedAt.UTC().Format(time.RFC3339)},
+		{Key: "updated_at", Value: q.UpdatedAt.UTC().Format(time.RFC3339)},
+		{Key: "version", Value: q.Version},
+	}})
+	if err != nil {
+		return err
+	}
+	e.count++
+	_, err = e.w.Write(b)
+	return err
+}
+
+// tagList чтобы пустые теги выгружались как [], а не null
+func tagList(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func (e *yamlEncoder) end() error {
+	if e.count == 0 {
+		_, err := io.WriteString(e.w, "[]\n")
+		return err
+	}
+	return nil
+}
+
+// markdownEncoder таблица; | и переводы строк в ячейках экранируются
+type markdownEncoder struct {
+	w io.Writer
+}
+
+var markdownCell = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// markdownHeader шапка таблицы; extra — колонки после стандартных
+func markdownHeader(extra []string) string {
+	var b strings.Builder
+	b.WriteString("| id | author | quote | tags | created_at |")
+	for _, name := range extra {
+		b.WriteString(" " + markdownCell.Replace(name) + " |")
+	}
+	b.WriteString("\n|---:|---|---|---|---|" + strings.Repeat("---|", len(extra)) + "\n")
+	return b.String()
+}
+
+func markdownRow(q *models.Quote, extra []string) string {
+	var b strings.Builder
+	b.WriteString("| " + strconv.Itoa(q.ID) +
+		" | " + markdownCell.Replace(q.Author) +
+		" | " + markdownCell.Replace(q.Quote) +
+		" | " + markdownCell.Replace(strings.Join(q.Tags, ", ")) +
+		" | " + q.CreatedAt.UTC().Format(time.RFC3339) + " |")
+	for _, v := range extra {
+		b.WriteString(" " + markdownCell.Replace(v) + " |")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (e *markdownEncoder) begin() error {
+	_, err := io.WriteString(e.w, markdownHeader(nil))
+	return err
+}
+
+func (e *markdownEncoder) encode(q *models.Quote) error {
+	_, err := io.WriteString(e.w, markdownRow(q, nil))
+	return err
+}
+
+func (e *markdownEncoder) end() error { return nil }
+
+// acceptsGzip проверяет Accept-Encoding без учёта q=0
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			return strings.ReplaceAll(params, " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+// HandleExportQuotes обрабатывает GET /quotes/export?format=...
+// Фильтры те же, что у GET /quotes. При Accept-Encoding: gzip ответ сжимается.
+func (h *Handler) HandleExportQuotes() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		filter, err := quoteFilter(r)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		h.writeExport(ctx, w, r, "quotes", func(fn func(q *models.Quote) error) error {
+			return h.qbs.ExportQuotes(ctx, filter, fn)
+		})
+	})
+}
+
+// writeExport отдаёт цитаты из each в формате ?format= (по умолчанию json)
+// файлом filename.<format>. Заголовки уходят с первой цитатой: до неё
+// ошибку ещё можно вернуть статусом.
+func (h *Handler) writeExport(ctx context.Context, w http.ResponseWriter, r *http.Request,
+	filename string, each func(fn func(q *models.Quote) error) error) {
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "json"
+	}
+	format, ok := exportFormats[name]
+	if !ok {
+		handleServiceError(ctx, w, errdefs.Wrapf(errdefs.ErrInvalidInput,
+			"unknown format %q: json, ndjson, csv, yaml or markdown", name))
+		return
+	}
+
+	var out io.Writer = w
+	var gz *gzip.Writer
+	var enc quoteEncoder
+	start := func() error {
+		w.Header().Set("Content-Type", format.contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.`+name+`"`)
+		w.Header().Add("Vary", "Accept-Encoding")
+		if acceptsGzip(r) {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz = gzip.NewWriter(w)
+			out = gz
+		}
+		enc = format.newEncoder(out)
+		return enc.begin()
+	}
+
+	exported := 0
+	err := each(func(q *models.Quote) error {
+		if enc == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		exported++
+		return enc.encode(q)
+	})
+	if err == nil && enc == nil {
+		err = start()
+	}
+	if err == nil {
+		err = enc.end()
+	}
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if err != nil {
+		// если заголовки уже ушли, сменить статус нельзя
+		if enc == nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		h.logger.Error(ctx, "quotes export interrupted", zap.Error(err))
+		return
+	}
+
+	h.logger.Info(ctx, "exported quotes",
+		zap.String("format", name),
+		zap.Int("exported", exported),
+		zap.Bool("gzip", gz != nil),
+	)
+}
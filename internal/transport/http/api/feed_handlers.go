@@ -0,0 +1,258 @@
+package api
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"quotebook/internal/models"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// длина заголовка записи ленты, полный текст — в описании
+const feedTitleLen = 80
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Self          atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	Creator     string   `xml:"dc:creator"`
+	Categories  []string `xml:"category"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Link       atomLink       `xml:"link"`
+	Author     atomPerson     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// baseURL адрес сервиса для ссылок в ленте: из конфига или из запроса
+func (h *Handler) baseURL(r *http.Request) string {
+	if base := strings.TrimRight(h.cfg.Feed.BaseURL, "/"); base != "" {
+		return base
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+// feedEntryTitle начало цитаты; текст целиком уходит в описание
+func feedEntryTitle(q *models.Quote) string {
+	text := strings.Join(strings.Fields(q.Quote), " ")
+	if utf8.RuneCountInString(text) > feedTitleLen {
+		text = string([]rune(text)[:feedTitleLen-1]) + "…"
+	}
+	return q.Author + ": " + text
+}
+
+func feedTitle(title string, f *models.QuoteFilter) string {
+	if f.Author != "" {
+		title += " — " + f.Author
+	}
+	if f.Tag != "" {
+		title += " #" + f.Tag
+	}
+	return title
+}
+
+func rssFeedOf(title, base, self string, updated time.Time, quotes []models.Quote) *rssFeed {
+	feed := &rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         title,
+			Link:          base + "/quotes",
+			Description:   "Recently added quotes",
+			Self:          atomLink{Href: self, Rel: "self", Type: "application/rss+xml"},
+			LastBuildDate: updated.UTC().Format(time.RFC1123Z),
+			Items:         make([]rssItem, 0, len(quotes)),
+		},
+	}
+	for i := range quotes {
+		q := &quotes[i]
+		link := base + "/quotes/" + strconv.Itoa(q.ID)
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       feedEntryTitle(q),
+			Link:        link,
+			Description: q.Quote,
+			Creator:     q.Author,
+			Categories:  q.Tags,
+			// ссылка строится из id и не меняется при правке цитаты
+			GUID:    rssGUID{IsPermaLink: true, Value: link},
+			PubDate: q.CreatedAt.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return feed
+}
+
+func atomFeedOf(title, base, self string, updated time.Time, quotes []models.Quote) *atomFeed {
+	feed := &atomFeed{
+		ID:      self,
+		Title:   title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: self, Rel: "self", Type: "application/atom+xml"},
+			{Href: base + "/quotes", Rel: "alternate"},
+		},
+		Entries: make([]atomEntry, 0, len(quotes)),
+	}
+	for i := range quotes {
+		q := &quotes[i]
+		link := base + "/quotes/" + strconv.Itoa(q.ID)
+		entry := atomEntry{
+			ID:        link,
+			Title:     feedEntryTitle(q),
+			Updated:   q.UpdatedAt.UTC().Format(time.RFC3339),
+			Published: q.CreatedAt.UTC().Format(time.RFC3339),
+			Link:      atomLink{Href: link, Rel: "alternate"},
+			Author:    atomPerson{Name: q.Author},
+			Content:   atomContent{Type: "text", Body: q.Quote},
+		}
+		for _, tag := range q.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// HandleGetFeed обрабатывает GET /feeds/quotes.rss и /feeds/quotes.atom,
+// фильтры ?author= и ?tag=
+func (h *Handler) HandleGetFeed() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		// любое изменение таблицы меняет и ленту, поэтому хватает общего отпечатка
+		stamp, err := h.qbs.QuotesStamp(ctx)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		if notModified(w, r, listETag(stamp), stamp.LastModified) {
+			return
+		}
+
+		filter, err := quoteFilter(r)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		quotes, err := h.qbs.RecentQuotes(ctx, filter)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		base := h.baseURL(r)
+		self := base + r.URL.RequestURI()
+		title := feedTitle(h.cfg.Feed.Title, filter)
+
+		format := mux.Vars(r)["format"]
+		var feed any
+		switch format {
+		case "atom":
+			w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+			feed = atomFeedOf(title, base, self, stamp.LastModified, *quotes)
+		default:
+			w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+			feed = rssFeedOf(title, base, self, stamp.LastModified, *quotes)
+		}
+		w.WriteHeader(http.StatusOK)
+		if err := writeXML(w, feed); err != nil {
+			h.logger.Error(ctx, "failed to write feed", zap.Error(err))
+			return
+		}
+
+		h.logger.Info(ctx, "feed served",
+			zap.String("format", format),
+			zap.Int("entries", len(*quotes)),
+		)
+	})
+}
@@ -5,11 +5,13 @@ import (
 	"quotebook/internal/models"
 	"quotebook/internal/errdefs"
 	"quotebook/internal/logger"
+    "quotebook/internal/identity"
     "quotebook/internal/interfaces"
 
     "fmt"
 	"context"
     "strconv"
+    "strings"
 	"encoding/json"
 	"net/http"
 
@@ -22,22 +24,62 @@ type Handler struct {
 	logger *logger.Logger
     cfg *config.Config
 	qbs interfaces.IQuoteService
+    audit interfaces.IAuditService
+    idem interfaces.IIdempotencyService
+    imports interfaces.IImportService
+    cards interfaces.ICardService
+    daily interfaces.IDailyService
+    shuffles interfaces.IShuffleService
+    engagement interfaces.IEngagementService
+    collections interfaces.ICollectionService
+    disputes interfaces.IDisputeService
+    translations interfaces.ITranslationService
+    stats interfaces.IStatsService
+    views interfaces.IViewService
+    similar interfaces.ISimilarService
 }
 
-func NewHandler(lg *logger.Logger, cfg *config.Config, qbs interfaces.IQuoteService) *Handler {
+func NewHandler(lg *logger.Logger, cfg *config.Config, qbs interfaces.IQuoteService,
+    audit interfaces.IAuditService, idem interfaces.IIdempotencyService,
+    imports interfaces.IImportService, cards interfaces.ICardService,
+    daily interfaces.IDailyService, shuffles interfaces.IShuffleService,
+    engagement interfaces.IEngagementService, collections interfaces.ICollectionService,
+    disputes interfaces.IDisputeService, translations interfaces.ITranslationService,
+    stats interfaces.IStatsService, views interfaces.IViewService,
+    similar interfaces.ISimilarService) *Handler {
 	return &Handler{
 		qbs: qbs,
 		logger: lg,
         cfg: cfg,
+        audit: audit,
+        idem: idem,
+        imports: imports,
+        cards: cards,
+        daily: daily,
+        shuffles: shuffles,
+        engagement: engagement,
+        collections: collections,
+        disputes: disputes,
+        translations: translations,
+        stats: stats,
+        views: views,
+        similar: similar,
 	}
 }
 
 // удобно
+// формат ответа выбирается по Accept, см. render.go
 func encode[T any](w http.ResponseWriter, r *http.Request, status int, v T) error {
-	w.Header().Set("Content-Type", "application/json")
+    w.Header().Add("Vary", "Accept")
+    rnd := negotiate(r, v)
+    if rnd == nil {
+        notAcceptable(w, v)
+        return fmt.Errorf("no acceptable representation for %q", r.Header.Get("Accept"))
+    }
+	w.Header().Set("Content-Type", rnd.ContentType())
     w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(v); err != nil {
-		return fmt.Errorf("encode json: %w", err)
+	if err := rnd.Render(w, v); err != nil {
+		return fmt.Errorf("encode %s: %w", rnd.ContentType(), err)
 	}
 	return nil
 }
@@ -51,12 +93,56 @@ func decode[T any](r *http.Request) (T, error) {
 }
 
 // так как frontend фактически нет, то я тут генерирую RequstID
+// если middleware уже выдал RequestID, используется он
 func (h *Handler) GenerateRequestID(r *http.Request) context.Context {
     ctx := r.Context()
     ctx = logger.CtxWWithLogger(ctx, h.logger)
+    ctx = identity.CtxWithActor(ctx, identity.ActorFromRequest(r))
+    if logger.RequestIDFromCtx(ctx) != "" {
+        return ctx
+    }
     return context.WithValue(ctx, logger.RequestID, uuid.New().String())
 }
 
+// pathInt достаёт числовой параметр пути, например {id}
+func pathInt(r *http.Request, name string) (int, error) {
+    n, err := strconv.Atoi(mux.Vars(r)[name])
+    if err != nil {
+        return 0, errdefs.Wrapf(errdefs.ErrInvalidInput, "%s must be an integer", name)
+    }
+    return n, nil
+}
+
+// quoteFilter фильтры списка из ?author=, ?tag=, ?lang= и ?hide_misattributed=
+func quoteFilter(r *http.Request) (*models.QuoteFilter, error) {
+    hide, err := queryBool(r.URL.Query(), "hide_misattributed")
+    if err != nil {
+        return nil, err
+    }
+    return &models.QuoteFilter{
+        Author: r.URL.Query().Get("author"),
+        Tag:    r.URL.Query().Get("tag"),
+        HideMisattributed: hide,
+        Lang:   r.URL.Query().Get("lang"),
+    }, nil
+}
+
+// withoutMisattributed убирает из списка цитаты с чужим авторством, если клиент
+// попросил ?hide_misattributed=true
+func withoutMisattributed(r *http.Request, quotes *[]models.Quote) (*[]models.Quote, error) {
+    hide, err := queryBool(r.URL.Query(), "hide_misattributed")
+    if err != nil || !hide {
+        return quotes, err
+    }
+    kept := make([]models.Quote, 0, len(*quotes))
+    for _, q := range *quotes {
+        if q.Attribution != models.AttributionMisattributed {
+            kept = append(kept, q)
+        }
+    }
+    return &kept, nil
+}
+
 // HandlePostQuote обрабатывает POST /quotes
 func (h *Handler) HandlePostQuote() http.Handler {
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -87,7 +173,7 @@ func (h *Handler) HandlePostQuote() http.Handler {
     })
 }
 
-// HandleGetQuotes обрабатывает GET /quotes
+// HandleGetQuotes обрабатывает GET /quotes?tag=&lang=&hide_misattributed=
 func (h *Handler) HandleGetQuotes() http.Handler {
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
         ctx := h.GenerateRequestID(r)
@@ -97,7 +183,25 @@ func (h *Handler) HandleGetQuotes() http.Handler {
             zap.String("path", r.URL.Path),
         )
 
-        quotes, err := h.qbs.QuotesAll(ctx)
+        prefs := langPrefs(w, r)
+        stamp, err := h.qbs.QuotesStamp(ctx)
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+        if notModified(w, r, localizedListETag(listETag(stamp), prefs), stamp.LastModified) {
+            return
+        }
+
+        // ?tag=, ?lang= и hide_misattributed фильтруются в базе
+        var quotes *[]models.Quote
+        f, err := quoteFilter(r)
+        if err == nil {
+            quotes, err = h.qbs.FilterQuotes(ctx, f)
+        }
+        if err == nil {
+            err = h.translations.Localize(ctx, *quotes, prefs)
+        }
         if err != nil {
             handleServiceError(ctx, w, err)
             return
@@ -110,7 +214,8 @@ func (h *Handler) HandleGetQuotes() http.Handler {
     })
 }
 
-// HandleGetQuoteByAuthor обрабатывает GET /quotes/{author}
+// HandleGetQuoteByAuthor обрабатывает GET /quotes?author=; регистр не важен,
+// опечатки прощаются, к пустому ответу добавляется X-Did-You-Mean
 func (h *Handler) HandleGetQuoteByAuthor() http.Handler {
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
         ctx := h.GenerateRequestID(r)
@@ -120,9 +225,32 @@ func (h *Handler) HandleGetQuoteByAuthor() http.Handler {
             zap.String("path", r.URL.Path),
         )
 
+        prefs := langPrefs(w, r)
+        stamp, err := h.qbs.QuotesStamp(ctx)
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+        if notModified(w, r, localizedListETag(listETag(stamp), prefs), stamp.LastModified) {
+            return
+        }
+
         vars := mux.Vars(r)
         author := vars["author"]
-        quotes, err := h.qbs.QuoteByAuthor(ctx, author)
+        // автор ищется с опечатками, ?tag=, ?lang= и hide_misattributed
+        // применяются к найденным именам
+        var quotes *[]models.Quote
+        f, err := quoteFilter(r)
+        if err == nil {
+            f.Author = author
+            quotes, err = h.qbs.FilterQuotesByAuthor(ctx, f)
+        }
+        if err == nil {
+            err = h.translations.Localize(ctx, *quotes, prefs)
+        }
+        if err == nil && len(*quotes) == 0 {
+            err = h.setDidYouMean(ctx, w, author)
+        }
         if err != nil {
             handleServiceError(ctx, w, err)
             return
@@ -133,6 +261,92 @@ func (h *Handler) HandleGetQuoteByAuthor() http.Handler {
     })
 }
 
+// HandleGetQuote обрабатывает GET /quotes/{id}
+func (h *Handler) HandleGetQuote() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        ctx := h.GenerateRequestID(r)
+
+        h.logger.Info(ctx, "incoming request",
+            zap.String("method", r.Method),
+            zap.String("path", r.URL.Path),
+        )
+
+        id, err := pathInt(r, "id")
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+
+        prefs := langPrefs(w, r)
+        quote, err := h.qbs.GetQuote(ctx, id)
+        if err == nil {
+            err = h.localizeQuote(ctx, quote, prefs)
+        }
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+
+        h.logger.Info(ctx, "return quote",
+            zap.Int("id", id),
+        )
+        if notModified(w, r, quoteETag(quote), quote.UpdatedAt) {
+            return
+        }
+        // просмотр засчитывается, только если цитата действительно отдана
+        h.views.View(id)
+        encode(w, r, http.StatusOK, quote)
+    })
+}
+
+// HandleGetQuotesByIDs обрабатывает GET /quotes?ids=1,2,3
+func (h *Handler) HandleGetQuotesByIDs() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        ctx := h.GenerateRequestID(r)
+
+        h.logger.Info(ctx, "incoming request",
+            zap.String("method", r.Method),
+            zap.String("path", r.URL.Path),
+        )
+
+        var ids []int
+        for _, part := range strings.Split(r.URL.Query().Get("ids"), ",") {
+            id, err := strconv.Atoi(strings.TrimSpace(part))
+            if err != nil {
+                handleServiceError(ctx, w, errdefs.Wrapf(errdefs.ErrInvalidInput, "bad id %q", part))
+                return
+            }
+            ids = append(ids, id)
+        }
+
+        stamp, err := h.qbs.QuotesStamp(ctx)
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+        prefs := langPrefs(w, r)
+        if notModified(w, r, localizedListETag(batchETag(stamp, ids), prefs), stamp.LastModified) {
+            return
+        }
+
+        batch, err := h.qbs.QuotesByIDs(ctx, ids)
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+        if err := h.translations.Localize(ctx, batch.Quotes, prefs); err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+
+        h.logger.Info(ctx, "return quotes by ids",
+            zap.Int("returned", len(batch.Quotes)),
+            zap.Int("missing", len(batch.Missing)),
+        )
+        encode(w, r, http.StatusOK, batch)
+    })
+}
+
 // HandleGetRrote обрабатывает GET /quotes/random
 func (h *Handler) HandleGetRandQuote() http.Handler {
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -143,15 +357,142 @@ func (h *Handler) HandleGetRandQuote() http.Handler {
             zap.String("path", r.URL.Path),
         )
 
-        quote, err := h.qbs.RandQuote(ctx)
+        // с X-Shuffle-Token или ?shuffle=true клиент обходит цитаты без повторов
+        token := r.Header.Get("X-Shuffle-Token")
+        shuffled, err := queryBool(r.URL.Query(), "shuffle")
         if err != nil {
             handleServiceError(ctx, w, err)
             return
         }
 
+        strategy := r.URL.Query().Get("strategy")
+        lang := r.URL.Query().Get("lang")
+        prefs := langPrefs(w, r)
+
+        var quote *models.Quote
+        if (token != "" || shuffled) && strategy != "" {
+            handleServiceError(ctx, w, errdefs.Wrap(errdefs.ErrInvalidInput, "shuffle and strategy cannot be combined"))
+            return
+        }
+        if lang != "" && (token != "" || shuffled || strategy != "") {
+            handleServiceError(ctx, w, errdefs.Wrap(errdefs.ErrInvalidInput, "lang cannot be combined with shuffle or strategy"))
+            return
+        }
+        if lang != "" {
+            quote, err = h.qbs.RandQuoteByLang(ctx, lang)
+        } else if token != "" || shuffled {
+            quote, token, err = h.shuffles.NextQuote(ctx, token)
+            if err == nil {
+                w.Header().Set("X-Shuffle-Token", token)
+            }
+        } else {
+            quote, err = h.qbs.WeightedQuote(ctx, strategy)
+        }
+        if err == nil {
+            err = h.localizeQuote(ctx, quote, prefs)
+        }
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+        h.views.Serve(quote.ID)
+
         h.logger.Info(ctx, "return random quote",
             zap.Int("id", quote.ID),
         )
+        // каждый ответ случаен: 304 по ETag прошлой цитаты вернул бы клиенту
+        // её же вместо новой, поэтому без If-None-Match и кеширования
+        w.Header().Set("Cache-Control", "no-store")
+        w.Header().Set("ETag", quoteETag(quote))
+        encode(w, r, http.StatusOK, quote)
+    })
+}
+
+// HandlePutQuote обрабатывает PUT /quotes/{id}
+func (h *Handler) HandlePutQuote() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        ctx := h.GenerateRequestID(r)
+
+        h.logger.Info(ctx, "incoming request",
+            zap.String("method", r.Method),
+            zap.String("path", r.URL.Path),
+        )
+
+        id, err := pathInt(r, "id")
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+
+        version, err := h.ifMatchVersion(r, id)
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+
+        payload, err := decode[models.Quote](r)
+        if err != nil {
+            h.logger.Info(ctx, "invalid JSON payload", zap.Error(err))
+            http.Error(w, "Bad Request", http.StatusBadRequest)
+            return
+        }
+        payload.ID = id
+        payload.Version = version
+
+        if err := h.qbs.UpdateQuote(ctx, &payload); err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+
+        h.logger.Info(ctx, "quote updated",
+            zap.Int("id", id),
+            zap.Int("version", payload.Version),
+        )
+        w.Header().Set("ETag", quoteETag(&payload))
+        w.WriteHeader(http.StatusNoContent)
+    })
+}
+
+// HandlePatchQuote обрабатывает PATCH /quotes/{id}
+func (h *Handler) HandlePatchQuote() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        ctx := h.GenerateRequestID(r)
+
+        h.logger.Info(ctx, "incoming request",
+            zap.String("method", r.Method),
+            zap.String("path", r.URL.Path),
+        )
+
+        id, err := pathInt(r, "id")
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+
+        version, err := h.ifMatchVersion(r, id)
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+
+        payload, err := decode[models.QuotePatch](r)
+        if err != nil {
+            h.logger.Info(ctx, "invalid JSON payload", zap.Error(err))
+            http.Error(w, "Bad Request", http.StatusBadRequest)
+            return
+        }
+
+        quote, err := h.qbs.PatchQuote(ctx, id, version, &payload)
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+
+        h.logger.Info(ctx, "quote patched",
+            zap.Int("id", id),
+            zap.Int("version", quote.Version),
+        )
+        w.Header().Set("ETag", quoteETag(quote))
         encode(w, r, http.StatusOK, quote)
     })
 }
@@ -174,7 +515,13 @@ func (h *Handler) HandleDeleteQuote() http.Handler {
             return
         }
 
-        if err := h.qbs.DeleteQuote(ctx, id); err != nil {
+        version, err := h.ifMatchVersion(r, id)
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+
+        if err := h.qbs.DeleteQuote(ctx, id, version); err != nil {
             handleServiceError(ctx, w, err)
             return
         }
@@ -187,6 +534,58 @@ func (h *Handler) HandleDeleteQuote() http.Handler {
     })
 }
 
+// HandleGetTrash обрабатывает GET /trash
+func (h *Handler) HandleGetTrash() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        ctx := h.GenerateRequestID(r)
+
+        h.logger.Info(ctx, "incoming request",
+            zap.String("method", r.Method),
+            zap.String("path", r.URL.Path),
+        )
+
+        quotes, err := h.qbs.TrashQuotes(ctx)
+        if err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+
+        h.logger.Info(ctx, "listed trash",
+            zap.Int("returned", len(*quotes)),
+        )
+        encode(w, r, http.StatusOK, quotes)
+    })
+}
+
+// HandleRestoreQuote обрабатывает POST /quotes/{id}/restore
+func (h *Handler) HandleRestoreQuote() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        ctx := h.GenerateRequestID(r)
+
+        h.logger.Info(ctx, "incoming request",
+            zap.String("method", r.Method),
+            zap.String("path", r.URL.Path),
+        )
+
+        vars := mux.Vars(r)
+        id, err := strconv.Atoi(vars["id"])
+        if err != nil {
+            handleServiceError(ctx, w, errdefs.ErrInvalidInput)
+            return
+        }
+
+        if err := h.qbs.RestoreQuote(ctx, id); err != nil {
+            handleServiceError(ctx, w, err)
+            return
+        }
+
+        h.logger.Info(ctx, "quote restored",
+            zap.Int("id", id),
+        )
+        w.WriteHeader(http.StatusNoContent)
+    })
+}
+
 // возвращает нужную ошибку
 // чуть медленее чем на месте (много лишних проверок)
 // зато код более компактный и читаемый
@@ -194,10 +593,20 @@ func handleServiceError(ctx context.Context, w http.ResponseWriter, err error) {
     switch {
     case errdefs.Is(err, errdefs.ErrNotFound):
         http.Error(w, "Not Found", http.StatusNotFound)
+    case errdefs.Is(err, errdefs.ErrUnauthorized):
+        http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+    case errdefs.Is(err, errdefs.ErrForbidden):
+        http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
     case errdefs.Is(err, errdefs.ErrInvalidInput):
         http.Error(w, "Bad Request: "+err.Error(), http.StatusBadRequest)
     case errdefs.Is(err, errdefs.ErrConflict):
         http.Error(w, "Conflict: "+err.Error(), http.StatusConflict)
+    case errdefs.Is(err, errdefs.ErrPreconditionFailed):
+        http.Error(w, "Precondition Failed: "+err.Error(), http.StatusPreconditionFailed)
+    case errdefs.Is(err, errdefs.ErrIdempotencyMismatch):
+        http.Error(w, "Unprocessable Entity: "+err.Error(), http.StatusUnprocessableEntity)
+    case errdefs.Is(err, errdefs.ErrPreconditionRequired):
+        http.Error(w, "Precondition Required: "+err.Error(), http.StatusPreconditionRequired)
     default:
         logger.GetLoggerFromCtx(ctx).Error(ctx, "internal error", zap.Error(err))
         http.Error(w, "Internal Server Error", http.StatusInternalServerError)
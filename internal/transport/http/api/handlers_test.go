@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"quotebook/config"
+	"quotebook/internal/interfaces"
+	"quotebook/internal/logger"
+	"quotebook/internal/models"
+	"quotebook/internal/service"
+)
+
+// mockQuoteService методы, которые нужны тестам; вызов остальных
+// паникует на nil-интерфейсе
+type mockQuoteService struct {
+	mock.Mock
+	interfaces.IQuoteService
+}
+
+func (m *mockQuoteService) CreateQuote(ctx context.Context, q *models.Quote) (int, error) {
+	args := m.Called(ctx, q)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockQuoteService) GetQuote(ctx context.Context, id int) (*models.Quote, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Quote), args.Error(1)
+}
+
+func (m *mockQuoteService) UpdateQuote(ctx context.Context, q *models.Quote) error {
+	args := m.Called(ctx, q)
+	return args.Error(0)
+}
+
+func (m *mockQuoteService) QuotesStamp(ctx context.Context) (*models.QuotesStamp, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*models.QuotesStamp), args.Error(1)
+}
+
+func (m *mockQuoteService) QuotesByIDs(ctx context.Context, ids []int) (*models.QuoteBatch, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).(*models.QuoteBatch), args.Error(1)
+}
+
+func (m *mockQuoteService) WeightedQuote(ctx context.Context, strategy string) (*models.Quote, error) {
+	args := m.Called(ctx, strategy)
+	return args.Get(0).(*models.Quote), args.Error(1)
+}
+
+func (m *mockQuoteService) FilterQuotes(ctx context.Context, f *models.QuoteFilter) (*[]models.Quote, error) {
+	args := m.Called(ctx, f)
+	return args.Get(0).(*[]models.Quote), args.Error(1)
+}
+
+// noTranslations оставляет цитаты на языке оригинала
+type noTranslations struct {
+	interfaces.ITranslationService
+}
+
+func (noTranslations) Localize(ctx context.Context, quotes []models.Quote, prefs []string) error {
+	return nil
+}
+
+// recordingViews запоминает засчитанные просмотры и показы
+type recordingViews struct {
+	interfaces.IViewService
+	mu     sync.Mutex
+	viewed []int
+	served []int
+}
+
+func (v *recordingViews) View(id int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.viewed = append(v.viewed, id)
+}
+
+func (v *recordingViews) Serve(id int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.served = append(v.served, id)
+}
+
+// recordingAudit запоминает записи журнала аудита
+type recordingAudit struct {
+	interfaces.IAuditService
+	mu      sync.Mutex
+	entries []models.AuditEntry
+}
+
+func (a *recordingAudit) Record(ctx context.Context, e *models.AuditEntry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, *e)
+	return nil
+}
+
+// memIdempotencyRepository ключи идемпотентности в памяти
+type memIdempotencyRepository struct {
+	mu   sync.Mutex
+	keys map[string]*models.IdempotencyRecord
+}
+
+func (m *memIdempotencyRepository) ReserveKey(ctx context.Context, principal, key string, expiresAt time.Time) (*models.IdempotencyRecord, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rec, ok := m.keys[principal+"\n"+key]; ok {
+		return rec, false, nil
+	}
+	m.keys[principal+"\n"+key] = &models.IdempotencyRecord{Principal: principal, Key: key, ExpiresAt: expiresAt}
+	return nil, true, nil
+}
+
+func (m *memIdempotencyRepository) CompleteKey(ctx context.Context, rec *models.IdempotencyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	done := *rec
+	done.Completed = true
+	m.keys[rec.Principal+"\n"+rec.Key] = &done
+	return nil
+}
+
+func (m *memIdempotencyRepository) ReleaseKey(ctx context.Context, principal, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, principal+"\n"+key)
+	return nil
+}
+
+func (m *memIdempotencyRepository) PurgeExpiredKeys(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+// testServer роутер со всеми middleware и подставными сервисами
+type testServer struct {
+	router http.Handler
+	cfg    *config.Config
+	quotes *mockQuoteService
+	audit  *recordingAudit
+	views  *recordingViews
+}
+
+func newTestServer(t *testing.T) *testServer {
+	cfg, err := config.LoadConfig("../../../../config/config.yml")
+	require.NoError(t, err)
+	// логи тестам не нужны
+	cfg.Logger.OutputPaths = nil
+	lg, err := logger.New(cfg)
+	require.NoError(t, err)
+
+	ts := &testServer{cfg: cfg, quotes: new(mockQuoteService), audit: new(recordingAudit),
+		views: new(recordingViews)}
+	idem := service.NewIdempotencyService(cfg,
+		&memIdempotencyRepository{keys: make(map[string]*models.IdempotencyRecord)})
+	h := NewHandler(lg, cfg, ts.quotes, ts.audit, idem, nil, nil, nil, nil, nil, nil, nil,
+		noTranslations{}, nil, ts.views, nil)
+	ts.router = NewRouter(h)
+	return ts
+}
+
+// do выполняет запрос; headers — пары имя, значение
+func (ts *testServer) do(method, target, body string, headers ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, r)
+	return w
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// форматы импорта по Content-Type, если ?format= не задан
+var importContentTypes = map[string]string{
+	"text/csv":             models.FormatCSV,
+	"application/json":     models.FormatJSON,
+	"application/x-ndjson": models.FormatNDJSON,
+	"application/ndjson":   models.FormatNDJSON,
+}
+
+// parseImportOptions читает format, on_duplicate, dry_run и async
+func parseImportOptions(r *http.Request) (*models.ImportOptions, bool, error) {
+	q := r.URL.Query()
+	opts := &models.ImportOptions{
+		Format:      q.Get("format"),
+		OnDuplicate: q.Get("on_duplicate"),
+	}
+	if opts.Format == "" {
+		mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		opts.Format = importContentTypes[mt]
+	}
+	if opts.Format == "" {
+		return nil, false, errdefs.Wrap(errdefs.ErrInvalidInput, "format is required: csv, json or ndjson")
+	}
+
+	var err error
+	if opts.DryRun, err = queryBool(q, "dry_run"); err != nil {
+		return nil, false, err
+	}
+	async, err := queryBool(q, "async")
+	if err != nil {
+		return nil, false, err
+	}
+	return opts, async, nil
+}
+
+func queryBool(q url.Values, name string) (bool, error) {
+	v := q.Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errdefs.Wrapf(errdefs.ErrInvalidInput, "%s must be a boolean", name)
+	}
+	return b, nil
+}
+
+// spoolBody сохраняет тело запроса во временный файл для фонового импорта
+func (h *Handler) spoolBody(r *http.Request) (string, error) {
+	f, err := os.CreateTemp(h.cfg.Import.SpoolDir, "quotes-import-*")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r.Body); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// HandleImportQuotes обрабатывает POST /quotes/import.
+// Большие файлы (и ?async=true) импортируются в фоне: 202 и ссылка на задачу.
+func (h *Handler) HandleImportQuotes() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		opts, async, err := parseImportOptions(r)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		threshold := h.cfg.Import.AsyncThreshold
+		if async || (threshold > 0 && r.ContentLength > threshold) {
+			path, err := h.spoolBody(r)
+			if err != nil {
+				handleServiceError(ctx, w, err)
+				return
+			}
+			job, err := h.imports.StartImport(ctx, opts, path)
+			if err != nil {
+				handleServiceError(ctx, w, err)
+				return
+			}
+
+			h.logger.Info(ctx, "import job started",
+				zap.String("job", job.ID),
+			)
+			w.Header().Set("Location", "/quotes/import/"+job.ID)
+			encode(w, r, http.StatusAccepted, job)
+			return
+		}
+
+		res, err := h.imports.Import(ctx, opts, r.Body)
+		if err != nil {
+			// при on_duplicate=fail отчёт нужен клиенту, чтобы найти дубли
+			if res != nil && errdefs.Is(err, errdefs.ErrConflict) {
+				h.logger.Info(ctx, "import rejected", zap.Int("duplicates", res.Duplicates))
+				encode(w, r, http.StatusConflict, res)
+				return
+			}
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "quotes imported",
+			zap.Int("total", res.Total),
+			zap.Int("inserted", res.Inserted),
+			zap.Int("updated", res.Updated),
+			zap.Int("invalid", res.Invalid),
+			zap.Bool("dry_run", res.DryRun),
+		)
+		encode(w, r, http.StatusOK, res)
+	})
+}
+
+// HandleGetImportJob обрабатывает GET /quotes/import/{job}
+func (h *Handler) HandleGetImportJob() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		job, err := h.imports.ImportJob(ctx, mux.Vars(r)["job"])
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "import job fetched",
+			zap.String("job", job.ID),
+			zap.String("status", job.Status),
+		)
+		encode(w, r, http.StatusOK, job)
+	})
+}
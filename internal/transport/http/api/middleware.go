@@ -0,0 +1,322 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"quotebook/internal/audit"
+	"quotebook/internal/identity"
+	"quotebook/internal/logger"
+	"quotebook/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// statusRecorder запоминает код ответа для аудита
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	if sr.status == 0 {
+		sr.status = status
+	}
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(b)
+}
+
+// Flush нужен потоковым ответам (экспорт)
+func (sr *statusRecorder) Flush() {
+	if f, ok := sr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// hashingBody считает SHA-256 тела по мере чтения, не буферизуя его
+type hashingBody struct {
+	io.ReadCloser
+	h    hash.Hash
+	size int64
+}
+
+// newHashingBody prefix подмешивается в хеш перед телом
+func newHashingBody(body io.ReadCloser, prefix string) *hashingBody {
+	hb := &hashingBody{ReadCloser: body, h: sha256.New()}
+	hb.h.Write([]byte(prefix))
+	return hb
+}
+
+func (hb *hashingBody) Read(p []byte) (int, error) {
+	n, err := hb.ReadCloser.Read(p)
+	hb.size += int64(n)
+	hb.h.Write(p[:n])
+	return n, err
+}
+
+// sum дочитывает то, что не прочитал хэндлер, и возвращает хеш
+func (hb *hashingBody) sum() string {
+	rest, _ := io.Copy(hb.h, hb.ReadCloser)
+	hb.size += rest
+	return hex.EncodeToString(hb.h.Sum(nil))
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (h *Handler) trustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	for _, p := range h.cfg.Server.TrustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP адрес клиента. X-Forwarded-For учитывается, только если запрос
+// пришёл от прокси из server.trustedProxies: заголовок читается справа
+// налево до первого адреса, который не принадлежит доверенному прокси, —
+// всё левее него мог подставить сам клиент.
+func (h *Handler) clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !h.trustedProxy(ip) {
+		return ip
+	}
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			break
+		}
+		ip = hop
+		if !h.trustedProxy(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+// isAdmin запрос несёт токен admin.token; пустой токен не подходит никому
+func (h *Handler) isAdmin(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	expected := h.cfg.Admin.Token
+	return expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+	return r.URL.Path
+}
+
+// AuditMiddleware пишет в журнал аудита каждый изменяющий запрос. Запись
+// делается после ответа и best-effort: если база журнала недоступна, изменение
+// уже выполнено, а ошибка только логируется.
+func (h *Handler) AuditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isMutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), logger.RequestID, uuid.New().String())
+		ctx, trail := audit.CtxWithTrail(ctx)
+		body := newHashingBody(r.Body, "")
+		r = r.WithContext(ctx)
+		r.Body = body
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		outcome := models.OutcomeSuccess
+		if status >= http.StatusBadRequest {
+			outcome = models.OutcomeFailure
+		}
+		payloadHash := body.sum()
+		if body.size == 0 {
+			payloadHash = ""
+		}
+		target := trail.TargetID()
+		if target == "" {
+			target = mux.Vars(r)["id"]
+		}
+
+		entry := &models.AuditEntry{
+			Principal:     identity.ActorFromRequest(r),
+			Authenticated: h.isAdmin(r),
+			IP:            h.clientIP(r),
+			Method:        r.Method,
+			Route:         routeTemplate(r),
+			TargetID:      target,
+			Status:        status,
+			Outcome:       outcome,
+			RequestID:     logger.RequestIDFromCtx(ctx),
+			PayloadHash:   payloadHash,
+		}
+		// ответ уже отправлен, поэтому ошибка только логируется
+		if err := h.audit.Record(context.WithoutCancel(ctx), entry); err != nil {
+			h.logger.Error(ctx, "failed to record audit entry", zap.Error(err))
+		}
+	})
+}
+
+// responseCapture копирует ответ для повтора по Idempotency-Key,
+// буферизует не больше limit+1 байт
+type responseCapture struct {
+	http.ResponseWriter
+	status int
+	limit  int
+	buf    bytes.Buffer
+}
+
+func (rc *responseCapture) WriteHeader(status int) {
+	if rc.status == 0 {
+		rc.status = status
+	}
+	rc.ResponseWriter.WriteHeader(status)
+}
+
+func (rc *responseCapture) Write(b []byte) (int, error) {
+	if rc.status == 0 {
+		rc.status = http.StatusOK
+	}
+	if room := rc.limit + 1 - rc.buf.Len(); room > 0 {
+		rc.buf.Write(b[:min(len(b), room)])
+	}
+	return rc.ResponseWriter.Write(b)
+}
+
+func (rc *responseCapture) Flush() {
+	if f, ok := rc.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// IdempotencyMiddleware выполняет изменяющий запрос с Idempotency-Key один раз,
+// повтор получает сохранённый ответ. X-User клиент указывает сам, поэтому
+// ключ привязан ещё и к отпечатку запроса с адресом клиента: чужой ключ с
+// другого адреса отклоняется как несовпадение, а не отдаёт чужой ответ.
+func (h *Handler) IdempotencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+		if key == "" || !isMutating(r.Method) || !h.idem.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := logger.CtxWWithLogger(r.Context(), h.logger)
+		principal := identity.ActorFromRequest(r)
+		existing, err := h.idem.Begin(ctx, principal, key)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		// отпечаток: метод, адрес, клиент и тело запроса
+		body := newHashingBody(r.Body, r.Method+"\n"+r.URL.RequestURI()+"\n"+h.clientIP(r)+"\n")
+		if existing != nil {
+			if err := h.idem.CheckReplay(existing, body.sum()); err != nil {
+				handleServiceError(ctx, w, err)
+				return
+			}
+			if existing.ContentType != "" {
+				w.Header().Set("Content-Type", existing.ContentType)
+			}
+			if existing.ETag != "" {
+				w.Header().Set("ETag", existing.ETag)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(existing.Status)
+			w.Write(existing.Body)
+			return
+		}
+
+		// упавший хэндлер не должен держать ключ до истечения TTL
+		defer func() {
+			if p := recover(); p != nil {
+				h.idem.Release(context.WithoutCancel(ctx), principal, key)
+				panic(p)
+			}
+		}()
+
+		capture := &responseCapture{ResponseWriter: w, limit: h.cfg.Idempotency.MaxResponseSize}
+		r.Body = body
+		next.ServeHTTP(capture, r)
+
+		status := capture.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		rec := &models.IdempotencyRecord{
+			Principal:   principal,
+			Key:         key,
+			Fingerprint: body.sum(),
+			Status:      status,
+			ContentType: capture.Header().Get("Content-Type"),
+			ETag:        capture.Header().Get("ETag"),
+			Body:        capture.buf.Bytes(),
+		}
+		if err := h.idem.Complete(context.WithoutCancel(ctx), rec); err != nil {
+			h.logger.Error(ctx, "failed to store idempotent response", zap.Error(err))
+		}
+	})
+}
+
+// AdminOnly пускает только запросы с Authorization: Bearer <admin.token>
+func (h *Handler) AdminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !h.isAdmin(r) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CacheControlMiddleware выставляет Cache-Control из cache.routes для GET
+func (h *Handler) CacheControlMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			if cc, ok := h.cfg.Cache.Routes[routeTemplate(r)]; ok {
+				w.Header().Set("Cache-Control", cc)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"quotebook/config"
+	"quotebook/internal/audit"
+	"quotebook/internal/identity"
+	"quotebook/internal/models"
+)
+
+func TestIdempotencyMiddleware_Replay(t *testing.T) {
+	ts := newTestServer(t)
+	ts.quotes.On("CreateQuote", mock.Anything, mock.Anything).Return(7, nil).Once()
+	body := `{"author":"A","quote":"Q"}`
+
+	first := ts.do(http.MethodPost, "/quotes", body, "Idempotency-Key", "k1")
+	require.Equal(t, http.StatusCreated, first.Code)
+	require.Empty(t, first.Header().Get("Idempotent-Replayed"))
+
+	again := ts.do(http.MethodPost, "/quotes", body, "Idempotency-Key", "k1")
+	require.Equal(t, first.Code, again.Code)
+	require.Equal(t, first.Body.String(), again.Body.String())
+	require.Equal(t, first.Header().Get("Content-Type"), again.Header().Get("Content-Type"))
+	require.Equal(t, "true", again.Header().Get("Idempotent-Replayed"))
+
+	// тот же ключ с другим телом — ошибка клиента, а не повтор
+	w := ts.do(http.MethodPost, "/quotes", `{"author":"B","quote":"Q"}`, "Idempotency-Key", "k1")
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	ts.quotes.AssertNumberOfCalls(t, "CreateQuote", 1)
+}
+
+func TestIdempotencyMiddleware_ServerErrorNotStored(t *testing.T) {
+	ts := newTestServer(t)
+	ts.quotes.On("CreateQuote", mock.Anything, mock.Anything).Return(0, errors.New("db is down")).Once()
+	ts.quotes.On("CreateQuote", mock.Anything, mock.Anything).Return(7, nil).Once()
+	body := `{"author":"A","quote":"Q"}`
+
+	w := ts.do(http.MethodPost, "/quotes", body, "Idempotency-Key", "k1")
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	// ключ освобождён, повтор выполняется заново
+	w = ts.do(http.MethodPost, "/quotes", body, "Idempotency-Key", "k1")
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	ts.quotes.AssertExpectations(t)
+}
+
+func TestIdempotencyMiddleware_WithoutKey(t *testing.T) {
+	ts := newTestServer(t)
+	ts.quotes.On("CreateQuote", mock.Anything, mock.Anything).Return(7, nil).Twice()
+	body := `{"author":"A","quote":"Q"}`
+
+	for range 2 {
+		w := ts.do(http.MethodPost, "/quotes", body)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+	ts.quotes.AssertExpectations(t)
+}
+
+func TestAuditMiddleware_RecordsMutations(t *testing.T) {
+	ts := newTestServer(t)
+	ts.quotes.On("CreateQuote", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { audit.SetTarget(args.Get(0).(context.Context), "7") }).
+		Return(7, nil).Once()
+	body := `{"author":"A","quote":"Q"}`
+
+	w := ts.do(http.MethodPost, "/quotes", body,
+		identity.ActorHeader, "alice", "X-Forwarded-For", "203.0.113.5, 10.0.0.1")
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// чтение в журнал не попадает
+	ts.quotes.On("GetQuote", mock.Anything, 7).Return(&models.Quote{ID: 7, Version: 1}, nil)
+	w = ts.do(http.MethodGet, "/quotes/7", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Len(t, ts.audit.entries, 1)
+	e := ts.audit.entries[0]
+	sum := sha256.Sum256([]byte(body))
+	require.Equal(t, "alice", e.Principal)
+	require.False(t, e.Authenticated)
+	// прокси не настроены, X-Forwarded-For не учитывается
+	require.Equal(t, "192.0.2.1", e.IP)
+	require.Equal(t, http.MethodPost, e.Method)
+	require.Equal(t, "/quotes", e.Route)
+	require.Equal(t, "7", e.TargetID)
+	require.Equal(t, http.StatusCreated, e.Status)
+	require.Equal(t, models.OutcomeSuccess, e.Outcome)
+	require.NotEmpty(t, e.RequestID)
+	require.Equal(t, hex.EncodeToString(sum[:]), e.PayloadHash)
+}
+
+func TestAuditMiddleware_RecordsFailures(t *testing.T) {
+	ts := newTestServer(t)
+
+	// 412 до обращения к сервису: цель берётся из адреса
+	w := ts.do(http.MethodPut, "/quotes/1", `{"author":"A","quote":"Q"}`, "If-Match", `"2-1"`)
+	require.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	// 406 отклоняется до хэндлера, но тело всё равно попадает в хеш
+	w = ts.do(http.MethodPost, "/quotes", `{"author":"A","quote":"Q"}`, "Accept", "image/png")
+	require.Equal(t, http.StatusNotAcceptable, w.Code)
+
+	w = ts.do(http.MethodDelete, "/quotes/1", "", "Accept", "image/png")
+	require.Equal(t, http.StatusNotAcceptable, w.Code)
+
+	require.Len(t, ts.audit.entries, 3)
+	put, post, del := ts.audit.entries[0], ts.audit.entries[1], ts.audit.entries[2]
+
+	require.Equal(t, identity.Anonymous, put.Principal)
+	require.Equal(t, "/quotes/{id}", put.Route)
+	require.Equal(t, "1", put.TargetID)
+	require.Equal(t, http.StatusPreconditionFailed, put.Status)
+	require.Equal(t, models.OutcomeFailure, put.Outcome)
+
+	require.Equal(t, http.StatusNotAcceptable, post.Status)
+	require.Equal(t, models.OutcomeFailure, post.Outcome)
+	require.NotEmpty(t, post.PayloadHash)
+
+	// без тела хеша нет
+	require.Equal(t, models.OutcomeFailure, del.Outcome)
+	require.Empty(t, del.PayloadHash)
+	require.NotEqual(t, put.RequestID, post.RequestID)
+	ts.quotes.AssertNotCalled(t, "UpdateQuote", mock.Anything, mock.Anything)
+	ts.quotes.AssertNotCalled(t, "CreateQuote", mock.Anything, mock.Anything)
+}
+
+func TestAdminOnly_ClosedWithoutToken(t *testing.T) {
+	ts := newTestServer(t)
+	require.Empty(t, ts.cfg.Admin.Token, "config.yml must not ship an admin token")
+
+	// пустой Bearer не совпадает с пустым токеном
+	for _, auth := range []string{"", "Bearer ", "Bearer changeme"} {
+		w := ts.do(http.MethodGet, "/admin/audit", "", "Authorization", auth)
+		require.Equal(t, http.StatusForbidden, w.Code, auth)
+	}
+
+	ts.cfg.Admin.Token = "s3cret"
+	w := ts.do(http.MethodGet, "/admin/audit", "", "Authorization", "Bearer wrong")
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestClientIP_TrustedProxies(t *testing.T) {
+	var trusted []config.IPPrefix
+	require.NoError(t, yaml.Unmarshal([]byte(`[192.0.2.1, 10.0.0.0/8]`), &trusted))
+	var invalid []config.IPPrefix
+	require.Error(t, yaml.Unmarshal([]byte(`[10.0.0.0/33]`), &invalid))
+	h := &Handler{cfg: &config.Config{Server: config.ServerConfig{TrustedProxies: trusted}}}
+
+	for _, tc := range []struct {
+		remote, xff, want string
+	}{
+		// клиент напрямую: заголовок мог подставить он сам
+		{"198.51.100.9:1234", "203.0.113.5", "198.51.100.9"},
+		{"192.0.2.1:1234", "", "192.0.2.1"},
+		// левее первого недоверенного адреса может быть что угодно
+		{"192.0.2.1:1234", "6.6.6.6, 203.0.113.5, 10.0.0.1", "203.0.113.5"},
+		{"192.0.2.1:1234", "10.1.1.1, 10.0.0.1", "10.1.1.1"},
+		{"192.0.2.1:1234", "garbage, 10.0.0.1", "10.0.0.1"},
+		{"[::ffff:10.0.0.2]:1234", "203.0.113.5", "203.0.113.5"},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/quotes", nil)
+		r.RemoteAddr = tc.remote
+		if tc.xff != "" {
+			r.Header.Set("X-Forwarded-For", tc.xff)
+		}
+		require.Equal(t, tc.want, h.clientIP(r), tc)
+	}
+}
+
+func TestAuditMiddleware_AuthenticatedOnlyWithAdminToken(t *testing.T) {
+	ts := newTestServer(t)
+	ts.cfg.Admin.Token = "s3cret"
+	ts.quotes.On("CreateQuote", mock.Anything, mock.Anything).Return(7, nil).Twice()
+	body := `{"author":"A","quote":"Q"}`
+
+	ts.do(http.MethodPost, "/quotes", body, identity.ActorHeader, "root")
+	ts.do(http.MethodPost, "/quotes", body, identity.ActorHeader, "root", "Authorization", "Bearer s3cret")
+
+	require.Len(t, ts.audit.entries, 2)
+	require.False(t, ts.audit.entries[0].Authenticated)
+	require.True(t, ts.audit.entries[1].Authenticated)
+}
+
+func TestIdempotencyMiddleware_KeyBoundToClient(t *testing.T) {
+	ts := newTestServer(t)
+	ts.quotes.On("CreateQuote", mock.Anything, mock.Anything).Return(7, nil).Once()
+	body := `{"author":"A","quote":"Q"}`
+
+	post := func(remote string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(body))
+		r.RemoteAddr = remote
+		r.Header.Set("Idempotency-Key", "k1")
+		w := httptest.NewRecorder()
+		ts.router.ServeHTTP(w, r)
+		return w
+	}
+
+	require.Equal(t, http.StatusCreated, post("198.51.100.1:1000").Code)
+	// другой анонимный клиент с тем же ключом и телом чужой ответ не получает
+	w := post("198.51.100.2:1000")
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	require.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	// а сам клиент получает повтор и с другого порта
+	w = post("198.51.100.1:2000")
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+
+	ts.quotes.AssertExpectations(t)
+}
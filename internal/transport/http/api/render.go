@@ -0,0 +1,445 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"quotebook/internal/models"
+)
+
+// Renderer пишет ответ в одном представлении. Рендерер может уметь не всё:
+// CanRender говорит, представимо ли значение в этом формате.
+type Renderer interface {
+	ContentType() string
+	CanRender(v any) bool
+	Render(w io.Writer, v any) error
+}
+
+// renderers в порядке предпочтения: первый подходящий выбирается для */*
+// и при отсутствии Accept
+var renderers []Renderer
+
+// RegisterRenderer добавляет формат ответа для всех ручек, отдающих данные через encode
+func RegisterRenderer(r Renderer) {
+	renderers = append(renderers, r)
+}
+
+func init() {
+	RegisterRenderer(jsonRenderer{})
+	RegisterRenderer(textRenderer{})
+	RegisterRenderer(htmlRenderer{})
+	RegisterRenderer(csvRenderer{})
+	RegisterRenderer(xmlRenderer{})
+	RegisterRenderer(markdownRenderer{})
+}
+
+// mediaRange элемент заголовка Accept
+type mediaRange struct {
+	typ, sub string
+	q        float64
+}
+
+func (m mediaRange) specificity() int {
+	switch {
+	case m.typ == "*":
+		return 0
+	case m.sub == "*":
+		return 1
+	}
+	return 2
+}
+
+func (m mediaRange) matches(contentType string) bool {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	typ, sub, _ := strings.Cut(mt, "/")
+	return (m.typ == "*" || m.typ == typ) && (m.sub == "*" || m.sub == sub)
+}
+
+// parseAccept разбирает Accept и сортирует по q, затем по точности диапазона
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, sub, ok := strings.Cut(mt, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{typ: typ, sub: sub, q: q})
+	}
+
+	// при равенстве сохраняется порядок из заголовка
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+	return ranges
+}
+
+// negotiate выбирает рендерер для v по Accept; nil — подходящего нет (406).
+// Передайте v == nil, чтобы проверить только сам заголовок.
+//
+// Не цитаты (id, коллекции, оценки) умеет только JSON. Если клиент принимает
+// какой-то из известных форматов, но не JSON, такой ответ всё равно отдаётся
+// в JSON, а не 406: RFC 9110 это разрешает, а изменяющему запросу иначе
+// пришлось бы отказывать уже после изменения. 406 остаётся, если JSON
+// запрещён явно (application/json;q=0).
+func negotiate(r *http.Request, v any) Renderer {
+	can := func(rnd Renderer) bool { return v == nil || rnd.CanRender(v) }
+
+	header := r.Header.Get("Accept")
+	if strings.TrimSpace(header) == "" {
+		for _, rnd := range renderers {
+			if can(rnd) {
+				return rnd
+			}
+		}
+		return nil
+	}
+
+	ranges := parseAccept(header)
+	// q=0 запрещает тип, даже если он подходит под */*
+	refused := func(rnd Renderer) bool {
+		for _, m := range ranges {
+			if m.q == 0 && m.specificity() == 2 && m.matches(rnd.ContentType()) {
+				return true
+			}
+		}
+		return false
+	}
+	accepted := false
+	for _, m := range ranges {
+		if m.q == 0 {
+			break
+		}
+		for _, rnd := range renderers {
+			if !m.matches(rnd.ContentType()) || refused(rnd) {
+				continue
+			}
+			if can(rnd) {
+				return rnd
+			}
+			accepted = true
+		}
+	}
+	if accepted && !isQuotes(v) && !refused(jsonRenderer{}) {
+		return jsonRenderer{}
+	}
+	return nil
+}
+
+// notAcceptable отвечает 406 со списком доступных для v типов
+func notAcceptable(w http.ResponseWriter, v any) {
+	var types []string
+	for _, rnd := range renderers {
+		if v == nil || rnd.CanRender(v) {
+			mt, _, _ := mime.ParseMediaType(rnd.ContentType())
+			types = append(types, mt)
+		}
+	}
+	http.Error(w, "Not Acceptable: supported types: "+strings.Join(types, ", "), http.StatusNotAcceptable)
+}
+
+// quoteList ответ как цитаты: для форматов кроме JSON
+type quoteList struct {
+	entries []models.QuoteEntry
+	// подписи ко всему ответу, например ненайденные id
+	notes []models.Note
+	// одна цитата, а не список
+	single bool
+}
+
+var entrierType = reflect.TypeFor[models.QuoteEntrier]()
+
+// quotesOf достаёт цитаты из ответа: цитату или обёртку над ней
+// (models.QuoteEntrier), срез таких или указатель на срез, список с подписями
+// (models.QuoteLister). Новой обёртке достаточно реализовать один из этих
+// интерфейсов. ok=false — в ответе не цитаты.
+func quotesOf(v any) (list quoteList, ok bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			// nil-срез — пустой список, nil-цитата — не цитата
+			elem := rv.Type().Elem()
+			return quoteList{}, elem.Kind() == reflect.Slice && elem.Elem().Implements(entrierType)
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return quoteList{}, false
+	}
+
+	switch t := rv.Interface().(type) {
+	case models.QuoteLister:
+		list.entries, list.notes = t.Entries()
+		return list, true
+	case models.QuoteEntrier:
+		return quoteList{entries: []models.QuoteEntry{t.Entry()}, single: true}, true
+	}
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Implements(entrierType) {
+		list.entries = make([]models.QuoteEntry, rv.Len())
+		for i := range list.entries {
+			list.entries[i] = rv.Index(i).Interface().(models.QuoteEntrier).Entry()
+		}
+		return list, true
+	}
+	return quoteList{}, false
+}
+
+func isQuotes(v any) bool {
+	_, ok := quotesOf(v)
+	return ok
+}
+
+// noteNames имена подписей цитат в порядке первого появления: колонки CSV и Markdown
+func (l quoteList) noteNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, e := range l.entries {
+		for _, n := range e.Notes {
+			if !seen[n.Name] {
+				seen[n.Name] = true
+				names = append(names, n.Name)
+			}
+		}
+	}
+	return names
+}
+
+// noteValues значения подписей цитаты по именам, отсутствующие — пустые
+func noteValues(notes []models.Note, names []string) []string {
+	values := make([]string, len(names))
+	for i, name := range names {
+		for _, n := range notes {
+			if n.Name == name {
+				values[i] = n.Value
+				break
+			}
+		}
+	}
+	return values
+}
+
+type jsonRenderer struct{}
+
+func (jsonRenderer) ContentType() string  { return "application/json" }
+func (jsonRenderer) CanRender(v any) bool { return true }
+func (jsonRenderer) Render(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// textRenderer “цитата” — автор и подписи строками "name: value", цитаты
+// через пустую строку; подписи ко всему ответу — в начале
+type textRenderer struct{}
+
+func (textRenderer) ContentType() string  { return "text/plain; charset=utf-8" }
+func (textRenderer) CanRender(v any) bool { return isQuotes(v) }
+func (textRenderer) Render(w io.Writer, v any) error {
+	list, _ := quotesOf(v)
+	var b strings.Builder
+	writeNotes := func(notes []models.Note) {
+		for _, n := range notes {
+			b.WriteString(n.Name + ": " + n.Value + "\n")
+		}
+	}
+	writeNotes(list.notes)
+	for i, e := range list.entries {
+		if i > 0 || len(list.notes) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("“" + e.Quote.Quote + "” — " + e.Quote.Author + "\n")
+		writeNotes(e.Notes)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var quotesHTML = template.Must(template.New("quotes").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Quotes</title></head>
+<body>
+{{- define "notes"}}{{if .}}
+<dl>
+{{- range .}}
+  <dt>{{.Name}}</dt><dd>{{.Value}}</dd>
+{{- end}}
+</dl>
+{{- end}}{{end}}
+{{- template "notes" .Notes}}
+{{- range .Entries}}
+<blockquote id="quote-{{.Quote.ID}}">
+  <p style="white-space: pre-line">{{.Quote.Quote}}</p>
+  <footer>— <cite>{{.Quote.Author}}</cite></footer>
+  {{- template "notes" .Notes}}
+</blockquote>
+{{- end}}
+</body>
+</html>
+`))
+
+type htmlRenderer struct{}
+
+func (htmlRenderer) ContentType() string  { return "text/html; charset=utf-8" }
+func (htmlRenderer) CanRender(v any) bool { return isQuotes(v) }
+func (htmlRenderer) Render(w io.Writer, v any) error {
+	list, _ := quotesOf(v)
+	return quotesHTML.Execute(w, struct {
+		Notes   []models.Note
+		Entries []models.QuoteEntry
+	}{list.notes, list.entries})
+}
+
+// csvRenderer пишет так же, как GET /quotes/export, подписи цитат — лишними
+// колонками. Подписям ко всему ответу в CSV места нет, такие ответы он не
+// отдаёт.
+type csvRenderer struct{}
+
+func (csvRenderer) ContentType() string { return exportFormats["csv"].contentType }
+func (csvRenderer) CanRender(v any) bool {
+	list, ok := quotesOf(v)
+	return ok && len(list.notes) == 0
+}
+func (csvRenderer) Render(w io.Writer, v any) error {
+	list, _ := quotesOf(v)
+	names := list.noteNames()
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append(csvHeader(), names...)); err != nil {
+		return err
+	}
+	for _, e := range list.entries {
+		if err := cw.Write(append(csvRow(&e.Quote), noteValues(e.Notes, names)...)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// markdownRenderer таблица как в GET /quotes/export, подписи цитат — лишними
+// колонками, подписи ко всему ответу — списком перед таблицей
+type markdownRenderer struct{}
+
+func (markdownRenderer) ContentType() string  { return exportFormats["markdown"].contentType }
+func (markdownRenderer) CanRender(v any) bool { return isQuotes(v) }
+func (markdownRenderer) Render(w io.Writer, v any) error {
+	list, _ := quotesOf(v)
+	var b strings.Builder
+	for _, n := range list.notes {
+		b.WriteString("- " + markdownCell.Replace(n.Name) + ": " + markdownCell.Replace(n.Value) + "\n")
+	}
+	if len(list.notes) > 0 {
+		b.WriteString("\n")
+	}
+	names := list.noteNames()
+	b.WriteString(markdownHeader(names))
+	for _, e := range list.entries {
+		b.WriteString(markdownRow(&e.Quote, noteValues(e.Notes, names)))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type xmlNote struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlQuote struct {
+	XMLName   xml.Name  `xml:"quote"`
+	ID        int       `xml:"id,attr"`
+	Version   int       `xml:"version,attr,omitempty"`
+	Author    string    `xml:"author"`
+	Text      string    `xml:"text"`
+	CreatedAt time.Time `xml:"created_at"`
+	UpdatedAt time.Time `xml:"updated_at"`
+	Notes     []xmlNote `xml:"note"`
+}
+
+type xmlQuotes struct {
+	XMLName xml.Name   `xml:"quotes"`
+	Notes   []xmlNote  `xml:"note"`
+	Quotes  []xmlQuote `xml:"quote"`
+}
+
+func xmlNotes(notes []models.Note) []xmlNote {
+	if len(notes) == 0 {
+		return nil
+	}
+	out := make([]xmlNote, len(notes))
+	for i, n := range notes {
+		out[i] = xmlNote{Name: n.Name, Value: n.Value}
+	}
+	return out
+}
+
+// xmlRenderer одна цитата — <quote>, список — <quotes>; подписи — элементы
+// <note name="...">
+type xmlRenderer struct{}
+
+func (xmlRenderer) ContentType() string  { return "application/xml; charset=utf-8" }
+func (xmlRenderer) CanRender(v any) bool { return isQuotes(v) }
+func (xmlRenderer) Render(w io.Writer, v any) error {
+	list, _ := quotesOf(v)
+	doc := xmlQuotes{Notes: xmlNotes(list.notes), Quotes: make([]xmlQuote, 0, len(list.entries))}
+	for _, e := range list.entries {
+		q := e.Quote
+		doc.Quotes = append(doc.Quotes, xmlQuote{
+			ID: q.ID, Version: q.Version, Author: q.Author, Text: q.Quote,
+			CreatedAt: q.CreatedAt.UTC(), UpdatedAt: q.UpdatedAt.UTC(),
+			Notes: xmlNotes(e.Notes),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	var err error
+	if list.single {
+		err = enc.Encode(doc.Quotes[0])
+	} else {
+		err = enc.Encode(doc)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// AcceptMiddleware отклоняет изменяющий запрос с 406 до выполнения, если
+// его ответ нечем будет отдать. Иначе изменение прошло бы, а ответ о нём
+// клиент так и не получил бы. Изменяющие ручки отвечают и не цитатами, а
+// такой ответ есть только в JSON, поэтому проверяется, что отдать можно
+// значение, которое не цитата: клиент принимает известный формат и не
+// запрещает JSON.
+func (h *Handler) AcceptMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isMutating(r.Method) && negotiate(r, struct{}{}) == nil {
+			notAcceptable(w, nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"quotebook/internal/models"
+)
+
+func TestNegotiate_QuoteFormats(t *testing.T) {
+	ts := newTestServer(t)
+	ts.quotes.On("GetQuote", mock.Anything, 1).
+		Return(&models.Quote{ID: 1, Author: "Confucius", Quote: "Everything has beauty", Version: 1}, nil)
+
+	for accept, contentType := range map[string]string{
+		"":                                 "application/json",
+		"*/*":                              "application/json",
+		"text/plain":                       "text/plain; charset=utf-8",
+		"text/html;q=0.5, application/xml": "application/xml; charset=utf-8",
+		"text/*":                           "text/plain; charset=utf-8",
+		"application/json;q=0, text/*":     "text/plain; charset=utf-8",
+	} {
+		w := ts.do(http.MethodGet, "/quotes/1", "", "Accept", accept)
+		require.Equal(t, http.StatusOK, w.Code, accept)
+		require.Equal(t, contentType, w.Header().Get("Content-Type"), accept)
+		require.Contains(t, w.Header().Values("Vary"), "Accept")
+	}
+
+	w := ts.do(http.MethodGet, "/quotes/1", "", "Accept", "image/png")
+	require.Equal(t, http.StatusNotAcceptable, w.Code)
+	require.Contains(t, w.Body.String(), "application/json")
+}
+
+func TestEncode_WrapperNotes(t *testing.T) {
+	dq := &models.DailyQuote{Date: "2024-05-01", Mode: models.DailyRotation,
+		Quote: models.Quote{ID: 3, Author: "A", Quote: "Q"}}
+
+	r := httptest.NewRequest(http.MethodGet, "/quotes/daily", nil)
+	r.Header.Set("Accept", "text/plain")
+	w := httptest.NewRecorder()
+	require.NoError(t, encode(w, r, http.StatusOK, dq))
+	require.Contains(t, w.Body.String(), "“Q” — A\n")
+	require.Contains(t, w.Body.String(), "date: 2024-05-01\n")
+	require.Contains(t, w.Body.String(), "mode: rotation\n")
+
+	// не цитаты в форматах кроме JSON отдаются в JSON, а не 406
+	w = httptest.NewRecorder()
+	require.NoError(t, encode(w, r, http.StatusOK, map[string]int{"id": 1}))
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+}
+
+func TestAcceptMiddleware_RejectsBeforeMutation(t *testing.T) {
+	ts := newTestServer(t)
+
+	for _, accept := range []string{"image/png", "application/json;q=0, text/plain"} {
+		w := ts.do(http.MethodPost, "/quotes", `{"author":"A","quote":"Q"}`, "Accept", accept)
+		require.Equal(t, http.StatusNotAcceptable, w.Code, accept)
+	}
+	ts.quotes.AssertNotCalled(t, "CreateQuote", mock.Anything, mock.Anything)
+}
+
+func TestAcceptMiddleware_NonQuoteFallsBackToJSON(t *testing.T) {
+	ts := newTestServer(t)
+	ts.quotes.On("CreateQuote", mock.Anything, mock.Anything).Return(7, nil).Once()
+
+	// ответ POST — id, в text/plain его нет, поэтому он отдаётся в JSON
+	w := ts.do(http.MethodPost, "/quotes", `{"author":"A","quote":"Q"}`, "Accept", "text/plain")
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	require.JSONEq(t, "7", w.Body.String())
+
+	ts.quotes.AssertExpectations(t)
+}
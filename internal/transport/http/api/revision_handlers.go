@@ -0,0 +1,148 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"quotebook/internal/errdefs"
+
+	"go.uber.org/zap"
+)
+
+// HandleGetRevisions обрабатывает GET /quotes/{id}/revisions
+func (h *Handler) HandleGetRevisions() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		revisions, err := h.qbs.QuoteRevisions(ctx, id)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "listed revisions",
+			zap.Int("id", id),
+			zap.Int("returned", len(*revisions)),
+		)
+		encode(w, r, http.StatusOK, revisions)
+	})
+}
+
+// HandleGetRevision обрабатывает GET /quotes/{id}/revisions/{rev}
+func (h *Handler) HandleGetRevision() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		rev, err := pathInt(r, "rev")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		revision, err := h.qbs.QuoteRevision(ctx, id, rev)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "return revision",
+			zap.Int("id", id),
+			zap.Int("rev", rev),
+		)
+		encode(w, r, http.StatusOK, revision)
+	})
+}
+
+// HandleGetRevisionDiff обрабатывает GET /quotes/{id}/diff?from=&to=
+func (h *Handler) HandleGetRevisionDiff() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		from, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+		to, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+		if errFrom != nil || errTo != nil {
+			handleServiceError(ctx, w, errdefs.Wrap(errdefs.ErrInvalidInput, "from and to revisions required"))
+			return
+		}
+
+		diff, err := h.qbs.DiffQuoteRevisions(ctx, id, from, to)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "return revision diff",
+			zap.Int("id", id),
+			zap.Int("from", from),
+			zap.Int("to", to),
+		)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(diff))
+	})
+}
+
+// HandleRevertQuote обрабатывает POST /quotes/{id}/revert/{rev}
+func (h *Handler) HandleRevertQuote() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		rev, err := pathInt(r, "rev")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		if err := h.qbs.RevertQuote(ctx, id, rev); err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "quote reverted",
+			zap.Int("id", id),
+			zap.Int("rev", rev),
+		)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
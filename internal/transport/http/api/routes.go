@@ -6,12 +6,73 @@ import (
 
 func NewRouter(handler *Handler) *mux.Router {
     router := mux.NewRouter()
+    router.Use(handler.AuditMiddleware)
+    router.Use(handler.CacheControlMiddleware)
+    router.Use(handler.AcceptMiddleware)
+    router.Use(handler.IdempotencyMiddleware)
 
     router.Handle("/quotes", handler.HandleGetQuoteByAuthor()).Methods("GET").Queries("author", "{author}")
+    router.Handle("/quotes", handler.HandleGetQuotesByIDs()).Methods("GET").Queries("ids", "{ids}")
+    router.Handle("/quotes", handler.HandleGetSortedQuotes()).Methods("GET").Queries("sort", "{sort}")
     router.Handle("/quotes", handler.HandleGetQuotes()).Methods("GET")
     router.Handle("/quotes", handler.HandlePostQuote()).Methods("POST")
+    router.Handle("/quotes/export", handler.HandleExportQuotes()).Methods("GET")
+    router.Handle("/quotes/import", handler.HandleImportQuotes()).Methods("POST")
+    router.Handle("/quotes/import/{job}", handler.HandleGetImportJob()).Methods("GET")
+    router.Handle("/quotes/daily", handler.HandleGetDailyQuote()).Methods("GET")
+    router.Handle("/quotes/daily/history", handler.HandleGetDailyHistory()).Methods("GET")
+    router.Handle("/quotes/on-this-day", handler.HandleGetOnThisDay()).Methods("GET")
     router.Handle("/quotes/random", handler.HandleGetRandQuote()).Methods("GET")
+    router.Handle("/quotes/trending", handler.HandleGetTrending()).Methods("GET")
+    router.Handle("/quotes/{id:[0-9]+}", handler.HandleGetQuote()).Methods("GET")
+    router.Handle("/quotes/{id:[0-9]+}/card.{format:png|svg}", handler.HandleGetQuoteCard()).Methods("GET")
+    router.Handle("/quotes/{id:[0-9]+}/citation", handler.HandleGetCitation()).Methods("GET")
+    router.Handle("/quotes/{id:[0-9]+}/similar", handler.HandleGetSimilar()).Methods("GET")
+    router.Handle("/quotes/{id}", handler.HandlePutQuote()).Methods("PUT")
+    router.Handle("/quotes/{id}", handler.HandlePatchQuote()).Methods("PATCH")
     router.Handle("/quotes/{id}", handler.HandleDeleteQuote()).Methods("DELETE")
+    router.Handle("/quotes/{id}/restore", handler.HandleRestoreQuote()).Methods("POST")
+    router.Handle("/quotes/{id}/revisions", handler.HandleGetRevisions()).Methods("GET")
+    router.Handle("/quotes/{id}/revisions/{rev}", handler.HandleGetRevision()).Methods("GET")
+    router.Handle("/quotes/{id}/diff", handler.HandleGetRevisionDiff()).Methods("GET")
+    router.Handle("/quotes/{id}/revert/{rev}", handler.HandleRevertQuote()).Methods("POST")
+    router.Handle("/quotes/{id}/disputes", handler.HandlePostDispute()).Methods("POST")
+    router.Handle("/quotes/{id:[0-9]+}/disputes", handler.HandleGetQuoteDisputes()).Methods("GET")
+    router.Handle("/quotes/{id:[0-9]+}/translations", handler.HandleGetTranslations()).Methods("GET")
+    router.Handle("/quotes/{id:[0-9]+}/translations/{lang}", handler.HandleGetTranslation()).Methods("GET")
+    router.Handle("/quotes/{id}/translations/{lang}", handler.HandlePutTranslation()).Methods("PUT")
+    router.Handle("/quotes/{id}/translations/{lang}", handler.HandleDeleteTranslation()).Methods("DELETE")
+    router.Handle("/quotes/{id}/like", handler.HandleLikeQuote()).Methods("POST")
+    router.Handle("/quotes/{id}/like", handler.HandleUnlikeQuote()).Methods("DELETE")
+    router.Handle("/quotes/{id}/rating", handler.HandleRateQuote()).Methods("PUT")
+    router.Handle("/quotes/{id}/rating", handler.HandleUnrateQuote()).Methods("DELETE")
+    router.Handle("/stats", handler.HandleGetStats()).Methods("GET")
+    router.Handle("/authors/suggest", handler.HandleSuggestAuthors()).Methods("GET")
+    router.Handle("/authors/{name}", handler.HandleGetAuthor()).Methods("GET")
+    router.Handle("/me/favorites", handler.HandleGetFavorites()).Methods("GET")
+    router.Handle("/collections", handler.HandleGetCollections()).Methods("GET")
+    router.Handle("/collections", handler.HandlePostCollection()).Methods("POST")
+    router.Handle("/collections/{id}", handler.HandleGetCollection()).Methods("GET")
+    router.Handle("/collections/{id}", handler.HandlePatchCollection()).Methods("PATCH")
+    router.Handle("/collections/{id}", handler.HandleDeleteCollection()).Methods("DELETE")
+    router.Handle("/collections/{id}/quotes", handler.HandleAddToCollection()).Methods("POST")
+    router.Handle("/collections/{id}/quotes/{quote_id}", handler.HandleRemoveFromCollection()).Methods("DELETE")
+    router.Handle("/collections/{id}/order", handler.HandleReorderCollection()).Methods("PUT")
+    router.Handle("/collections/{id}/random", handler.HandleGetCollectionRandom()).Methods("GET")
+    router.Handle("/collections/{id}/export", handler.HandleExportCollection()).Methods("GET")
+    router.Handle("/feeds/quotes.{format:rss|atom}", handler.HandleGetFeed()).Methods("GET")
+    router.Handle("/trash", handler.HandleGetTrash()).Methods("GET")
+
+    admin := router.PathPrefix("/admin").Subrouter()
+    admin.Use(handler.AdminOnly)
+    admin.Handle("/audit", handler.HandleGetAudit()).Methods("GET")
+    admin.Handle("/audit/export", handler.HandleExportAudit()).Methods("GET")
+    admin.Handle("/audit/verify", handler.HandleVerifyAudit()).Methods("GET")
+    admin.Handle("/daily/{date}", handler.HandlePinDailyQuote()).Methods("PUT")
+    admin.Handle("/authors/{name}", handler.HandlePutAuthor()).Methods("PUT")
+    admin.Handle("/disputes", handler.HandleGetDisputes()).Methods("GET")
+    admin.Handle("/disputes/{id}/resolve", handler.HandleResolveDispute()).Methods("POST")
+    admin.Handle("/quotes/{id}/attribution", handler.HandleSetAttribution()).Methods("PUT")
 
     return router
 }
\ No newline at end of file
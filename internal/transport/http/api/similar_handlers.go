@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// HandleGetSimilar обрабатывает GET /quotes/{id}/similar?limit=10
+func (h *Handler) HandleGetSimilar() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		limit := 0
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				handleServiceError(ctx, w, errdefs.Wrap(errdefs.ErrInvalidInput, "limit must be a positive integer"))
+				return
+			}
+			limit = n
+		}
+
+		prefs := langPrefs(w, r)
+		similar, err := h.similar.Similar(ctx, id, limit)
+		if err == nil {
+			quotes := make([]*models.Quote, len(*similar))
+			for i := range *similar {
+				quotes[i] = &(*similar)[i].Quote
+			}
+			err = h.localizeQuotes(ctx, quotes, prefs)
+		}
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "return similar quotes",
+			zap.Int("id", id),
+			zap.Int("returned", len(*similar)),
+		)
+		encode(w, r, http.StatusOK, similar)
+	})
+}
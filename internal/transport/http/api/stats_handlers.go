@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"quotebook/internal/errdefs"
+
+	"go.uber.org/zap"
+)
+
+// HandleGetStats обрабатывает GET /stats?top=10&interval=day|week|month
+func (h *Handler) HandleGetStats() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		top := 0
+		if v := r.URL.Query().Get("top"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				handleServiceError(ctx, w, errdefs.Wrap(errdefs.ErrInvalidInput, "top must be a positive integer"))
+				return
+			}
+			top = n
+		}
+
+		stats, err := h.stats.Stats(ctx, top, r.URL.Query().Get("interval"))
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "return stats",
+			zap.Int("quotes", stats.Quotes),
+			zap.Time("generated_at", stats.GeneratedAt),
+		)
+		encode(w, r, http.StatusOK, stats)
+	})
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// parseAcceptLanguage "ru-RU,ru;q=0.9,en;q=0.8" -> [ru en]: основные подтеги
+// по убыванию q без повторов; *, q=0 и неразборчивые теги пропускаются
+func parseAcceptLanguage(header string) []string {
+	type langRange struct {
+		lang string
+		q    float64
+	}
+	var ranges []langRange
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if len(lang) < 2 || len(lang) > 3 || strings.Trim(lang, "abcdefghijklmnopqrstuvwxyz") != "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, langRange{lang: lang, q: q})
+		}
+	}
+
+	// при равенстве сохраняется порядок из заголовка
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	langs := make([]string, 0, len(ranges))
+	seen := make(map[string]bool, len(ranges))
+	for _, rg := range ranges {
+		if !seen[rg.lang] {
+			seen[rg.lang] = true
+			langs = append(langs, rg.lang)
+		}
+	}
+	return langs
+}
+
+// langPrefs языки ответа: ?lang= или, если его нет, Accept-Language.
+// Ответ зависит от заголовка, поэтому выставляется Vary.
+func langPrefs(w http.ResponseWriter, r *http.Request) []string {
+	w.Header().Add("Vary", "Accept-Language")
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		return []string{lang}
+	}
+	return parseAcceptLanguage(r.Header.Get("Accept-Language"))
+}
+
+// localizedListETag ETag списка для набора языков: переводы одной выборки
+// на разные языки не должны совпадать по ETag
+func localizedListETag(etag string, prefs []string) string {
+	if len(prefs) == 0 {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + "." + strings.Join(prefs, ",") + `"`
+}
+
+// localizeQuote Localize для одной цитаты
+func (h *Handler) localizeQuote(ctx context.Context, quote *models.Quote, prefs []string) error {
+	one := []models.Quote{*quote}
+	if err := h.translations.Localize(ctx, one, prefs); err != nil {
+		return err
+	}
+	*quote = one[0]
+	return nil
+}
+
+// localizeQuotes как localizeQuote для цитат внутри обёрток вроде TrendingQuote
+func (h *Handler) localizeQuotes(ctx context.Context, quotes []*models.Quote, prefs []string) error {
+	if len(prefs) == 0 || len(quotes) == 0 {
+		return nil
+	}
+	values := make([]models.Quote, len(quotes))
+	for i, q := range quotes {
+		values[i] = *q
+	}
+	if err := h.translations.Localize(ctx, values, prefs); err != nil {
+		return err
+	}
+	for i, q := range quotes {
+		*q = values[i]
+	}
+	return nil
+}
+
+// HandleGetTranslations обрабатывает GET /quotes/{id}/translations
+func (h *Handler) HandleGetTranslations() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		translations, err := h.translations.Translations(ctx, id)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "listed translations",
+			zap.Int("quote", id),
+			zap.Int("returned", len(*translations)),
+		)
+		encode(w, r, http.StatusOK, translations)
+	})
+}
+
+// HandleGetTranslation обрабатывает GET /quotes/{id}/translations/{lang}
+func (h *Handler) HandleGetTranslation() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		t, err := h.translations.Translation(ctx, id, mux.Vars(r)["lang"])
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "return translation",
+			zap.Int("quote", id),
+			zap.String("lang", t.Lang),
+		)
+		encode(w, r, http.StatusOK, t)
+	})
+}
+
+// HandlePutTranslation обрабатывает PUT /quotes/{id}/translations/{lang},
+// тело {"quote": "...", "translator": "..."}
+func (h *Handler) HandlePutTranslation() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		payload, err := decode[models.Translation](r)
+		if err != nil {
+			handleServiceError(ctx, w, errdefs.Wrapf(errdefs.ErrInvalidInput, "invalid JSON payload: %v", err))
+			return
+		}
+		t, created, err := h.translations.PutTranslation(ctx, id, mux.Vars(r)["lang"], &payload)
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "translation saved",
+			zap.Int("quote", id),
+			zap.String("lang", t.Lang),
+			zap.Bool("created", created),
+		)
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		encode(w, r, status, t)
+	})
+}
+
+// HandleDeleteTranslation обрабатывает DELETE /quotes/{id}/translations/{lang}
+func (h *Handler) HandleDeleteTranslation() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		id, err := pathInt(r, "id")
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+		lang := mux.Vars(r)["lang"]
+		if err := h.translations.DeleteTranslation(ctx, id, lang); err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "translation deleted",
+			zap.Int("quote", id),
+			zap.String("lang", lang),
+		)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
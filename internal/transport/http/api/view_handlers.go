@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"quotebook/internal/errdefs"
+	"quotebook/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// HandleGetTrending обрабатывает GET /quotes/trending?window=24h|7d&limit=20
+func (h *Handler) HandleGetTrending() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := h.GenerateRequestID(r)
+
+		h.logger.Info(ctx, "incoming request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		limit := 0
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				handleServiceError(ctx, w, errdefs.Wrap(errdefs.ErrInvalidInput, "limit must be a positive integer"))
+				return
+			}
+			limit = n
+		}
+
+		prefs := langPrefs(w, r)
+		trending, err := h.views.Trending(ctx, r.URL.Query().Get("window"), limit)
+		if err == nil {
+			quotes := make([]*models.Quote, len(*trending))
+			for i := range *trending {
+				quotes[i] = &(*trending)[i].Quote
+			}
+			err = h.localizeQuotes(ctx, quotes, prefs)
+		}
+		if err != nil {
+			handleServiceError(ctx, w, err)
+			return
+		}
+
+		h.logger.Info(ctx, "return trending quotes",
+			zap.Int("returned", len(*trending)),
+		)
+		encode(w, r, http.StatusOK, trending)
+	})
+}